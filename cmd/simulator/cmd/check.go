@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"os"
 	"time"
 
 	"github.com/pterm/pterm"
@@ -10,6 +9,7 @@ import (
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
 	"github.com/dejanzele/batch-simulator/internal/k8s"
 	"github.com/dejanzele/batch-simulator/internal/simulator"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 )
 
 var checkCmd = &cobra.Command{
@@ -17,11 +17,15 @@ var checkCmd = &cobra.Command{
 	Short: "Check are required components installed & configured",
 	Long: `This command conducts comprehensive checks for essential components necessary for the system's operation,
 including the presence of 'kubectl', 'kwok', and various stages.
+It also reviews whether the current identity has the permissions needed to run and clean up a simulation,
+and inspects quotas, limit ranges, admission webhooks and priority & fairness settings which could reject fake resources.
 It ensures that all required tools and configurations are in place and functioning correctly,
 offering a quick and efficient way to validate the setup.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fatal := false
 		warning := false
+		// hints tell the user how to fix the checks which did not pass
+		var hints []string
 
 		pterm.DefaultHeader.Println("starting checks...")
 
@@ -33,93 +37,182 @@ offering a quick and efficient way to validate the setup.`,
 		blip()
 		pterm.DefaultSection.Println("init")
 
-		pterm.Info.Println("initializing kubernetes clients...")
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
-		}
-		dynamicClient, err := k8s.NewDynamicClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize dynamic k8s client: %v", err)
-			os.Exit(1)
-		}
-		pterm.Success.Println("kubernetes client initialized successfully!")
+		client := newKubernetesClient()
+		dynamicClient := newDynamicClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
 
 		// checks section
 		blip()
 		pterm.DefaultSection.Println("checks")
-		spinner, _ := pterm.DefaultSpinner.Start("is kubectl installed?")
+		s := startStep("kubectl", "is kubectl installed?")
 		time.Sleep(500 * time.Millisecond)
 		_, ok := simulator.CheckIsKubectlInstalled(cmd.Context())
 		if !ok {
 			warning = true
-			spinner.Warning("kubectl is not installed")
+			s.Warning("kubectl is not installed")
+			hints = append(hints, "install kubectl and add it to the PATH")
 		} else {
-			spinner.Success("kubectl is installed")
+			s.Success("kubectl is installed")
 		}
 
 		time.Sleep(500 * time.Millisecond)
 
-		spinner, _ = pterm.DefaultSpinner.Start("is kwok cli installed?")
+		s = startStep("kwok", "is kwok cli installed?")
 		time.Sleep(500 * time.Millisecond)
 		_, ok = simulator.CheckIsKWOKInstalled(cmd.Context())
 		if !ok {
 			warning = true
-			spinner.Warning("kwok cli is not installed")
+			s.Warning("kwok cli is not installed")
+			hints = append(hints, "install the kwok cli and add it to the PATH")
 		} else {
-			spinner.Success("kwok is installed")
+			s.Success("kwok is installed")
 		}
 
 		time.Sleep(500 * time.Millisecond)
 
-		spinner, _ = pterm.DefaultSpinner.Start("are stages created?")
+		s = startStep("stages", "are stages created?")
 		time.Sleep(500 * time.Millisecond)
 		created, missing, err := simulator.CheckAreStagesCreated(cmd.Context(), dynamicClient)
-		if err != nil {
+		switch {
+		case err != nil:
 			fatal = true
-			spinner.Fail("failed to check if stages are created")
+			s.Fail("failed to check if stages are created", err)
 			pterm.Error.Printf("%v\n", err)
-		}
-		if !created {
+		case !created:
 			warning = true
-			spinner.Warning("required kwok stages are not installed! run 'simulator init' to install required components.")
+			s.WithData(map[string]any{"missing": missing}).
+				Warning("required kwok stages are not installed! run 'batchsim install' to install required components.")
 			pterm.Warning.Printf("following stages are missing: %v\n", missing)
-		} else {
-			spinner.Success("all stages are installed")
+			hints = append(hints, "run 'batchsim install' to install the missing kwok stages")
+		default:
+			s.Success("all stages are installed")
 		}
 
 		time.Sleep(500 * time.Millisecond)
 
-		spinner, _ = pterm.DefaultSpinner.Start("is kwok-operator running?")
+		s = startStep("operator", "is kwok-operator running?")
 		time.Sleep(500 * time.Millisecond)
 		_, running, err := simulator.CheckIsOperatorRunning(cmd.Context(), client, config.KWOKNamespace)
-		if err != nil {
+		switch {
+		case err != nil:
 			fatal = true
-			spinner.Fail("failed to check is kwok-operator running")
+			s.Fail("failed to check is kwok-operator running", err)
 			pterm.Error.Printf("%v\n", err)
+		case !running:
+			warning = true
+			s.Warning("kwok-operator is not running")
+			hints = append(hints, "run 'batchsim install' to install the kwok-operator")
+		default:
+			s.Success("kwok-operator is running")
 		}
-		if !running {
+
+		time.Sleep(500 * time.Millisecond)
+
+		s = startStep("permissions", "does current identity have required permissions?")
+		permissions := simulator.RequiredPermissions(permissionOptions(), config.Namespace, config.SimulatorNamespace)
+		results, err := k8s.CheckPermissions(cmd.Context(), client, permissions)
+		s.WithData(results)
+		switch severity := simulator.PermissionSeverity(results); {
+		case err != nil:
+			fatal = true
+			s.Fail("failed to check permissions", err)
+			pterm.Error.Printf("%v\n", err)
+		case severity == simulator.SeverityFatal:
+			fatal = true
+			s.Fail("current identity is missing permissions required to run a simulation", nil)
+			hints = append(hints, "grant the current identity the missing permissions listed above")
+		case severity == simulator.SeverityWarning:
 			warning = true
-			spinner.Warning("kwok-operator is not running")
-		} else {
-			spinner.Success("kwok-operator is running")
+			s.Warning("current identity is missing one or more permissions")
+			hints = append(hints, "grant the current identity the missing permissions listed above")
+		default:
+			s.Success("current identity has all required permissions")
+		}
+		if err == nil {
+			printPermissionMatrix(results)
 		}
 
+		time.Sleep(500 * time.Millisecond)
+
+		s = startStep("preflight", "will simulation resources be admitted?")
+		findings := simulator.RunPreflight(cmd.Context(), client, config.Namespace)
+		s.WithData(findings)
+		switch simulator.MaxSeverity(findings) {
+		case simulator.SeverityFatal:
+			fatal = true
+			s.Fail("one or more simulation resources will be rejected", nil)
+			hints = append(hints, "fix the fatal preflight findings listed above")
+		case simulator.SeverityWarning:
+			warning = true
+			s.Warning("cluster settings could reject or throttle simulation resources")
+			hints = append(hints, "review the preflight findings listed above")
+		default:
+			s.Success("simulation resources will be admitted")
+		}
+		printPreflightFindings(findings)
+
 		// status section
 		blip()
 		pterm.DefaultSection.Println("status")
-		if warning {
-			pterm.Warning.Println("run 'simulator install' to install required components")
+		for _, hint := range hints {
+			pterm.Info.Println(hint)
 		}
 		exitBasedOnStatus(fatal, warning)
 	},
 }
 
+// permissionOptions returns the optional simulation features whose permissions are checked, exits if they are invalid.
+func permissionOptions() simulator.PermissionOptions {
+	options := simulator.PermissionOptions{Kueue: config.Kueue, ObjectsClusterScoped: config.ObjectClusterScoped}
+	if config.Gangs {
+		options.GangStyle = resources.GangStyle(config.GangStyle)
+		if err := options.GangStyle.Validate(); err != nil {
+			stepFailed("gangs", "invalid configuration", err)
+			exit(exitCodeFatal)
+		}
+	}
+	if config.ObjectResource != "" {
+		resource, err := objectResource(nil)
+		if err != nil {
+			stepFailed("objects", "invalid configuration", err)
+			exit(exitCodeFatal)
+		}
+		options.Objects = &resource
+	}
+	return options
+}
+
+// printPermissionMatrix prints the reviewed permissions in a table.
+func printPermissionMatrix(results []k8s.PermissionResult) {
+	data := pterm.TableData{{"Purpose", "Verb", "Resource", "Namespace", "Allowed"}}
+	for _, result := range results {
+		resource := result.Resource
+		if result.Group != "" {
+			resource += "." + result.Group
+		}
+		namespace := result.Namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+		allowed := pterm.Green("yes")
+		if !result.Allowed {
+			allowed = pterm.Red("no")
+		}
+		data = append(data, []string{result.Purpose, result.Verb, resource, namespace, allowed})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
 func NewCheckCmd() *cobra.Command {
 	addKubeconfigFlag(checkCmd)
 	addKubernetesConfigFlags(checkCmd)
+	checkCmd.Flags().StringVarP(&config.Namespace, "namespace", "n", config.Namespace, "namespace in which simulation resources are created")
+	checkCmd.Flags().StringVar(&config.SimulatorNamespace, "simulator-namespace", config.SimulatorNamespace, "namespace in which simulator resources are created")
+	checkCmd.Flags().StringVar(&config.ObjectResource, "object-resource", config.ObjectResource, "resource of the objects created from --object-template as <resource>.<version>.<group>, their permissions are checked if it is set")
+	checkCmd.Flags().BoolVar(&config.ObjectClusterScoped, "object-cluster-scoped", config.ObjectClusterScoped, "whether the objects created from --object-template are cluster-scoped")
+	checkCmd.Flags().BoolVar(&config.Kueue, "kueue", config.Kueue, "check the permissions to set up and delete the kueue queues of run --kueue")
+	checkCmd.Flags().BoolVar(&config.Gangs, "gangs", config.Gangs, "check the permissions to create and delete the PodGroups of run --gangs")
+	checkCmd.Flags().StringVar(&config.GangStyle, "gang-style", config.GangStyle, "gang scheduler whose PodGroups are created (coscheduling or volcano)")
 	checkCmd.PersistentFlags().BoolVarP(&config.Verbose, "verbose", "v", false, "verbose output")
 	return checkCmd
 }
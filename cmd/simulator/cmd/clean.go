@@ -3,10 +3,14 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/utils/strings/slices"
 
 	"github.com/pterm/pterm"
@@ -15,6 +19,7 @@ import (
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
 	"github.com/dejanzele/batch-simulator/internal/k8s"
 	"github.com/dejanzele/batch-simulator/internal/simulator"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 )
 
 var cleanCmd = &cobra.Command{
@@ -38,27 +43,102 @@ It's a comprehensive approach to maintaining a clean and efficient simulation en
 		blip()
 		pterm.DefaultSection.Println("init")
 
-		pterm.Info.Println("initializing kubernetes clients...")
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
+		client := newKubernetesClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
+
+		resourceCount := len(config.Resources)
+		if resourceCount == 0 {
+			config.Resources = []string{"nodes", "pods", "jobs", "namespaces"}
+			if config.ObjectResource != "" {
+				config.Resources = append(config.Resources, "objects")
+			}
+			if config.Kueue {
+				config.Resources = append(config.Resources, "queues")
+			}
+			if config.Gangs {
+				config.Resources = append(config.Resources, "gangs")
+			}
+			resourceCount = len(config.Resources)
+		}
+		cleanObjects := slices.Contains(config.Resources, "objects") || slices.Contains(config.Resources, "object")
+		var objectsResource schema.GroupVersionResource
+		if cleanObjects {
+			var err error
+			objectsResource, err = objectResource(nil)
+			if err != nil {
+				stepFailed("objects", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
 		}
-		pterm.Success.Println("kubernetes client initialized successfully!")
 
 		pterm.Info.Println("initializing kubernetes resource manager...")
-		manager := k8s.NewManager(client, &k8s.ManagerConfig{Namespace: config.Namespace})
+		cleanQueues := slices.Contains(config.Resources, "queues") || slices.Contains(config.Resources, "queue")
+		cleanGangs := slices.Contains(config.Resources, "gangs") || slices.Contains(config.Resources, "gang")
+		gangStyle := resources.GangStyle(config.GangStyle)
+		if cleanGangs {
+			if err := gangStyle.Validate(); err != nil {
+				stepFailed("gangs", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
+		}
+		var dynamicClient dynamic.Interface
+		if cleanObjects || cleanQueues || cleanGangs {
+			dynamicClient = newDynamicClient()
+		}
+		manager := k8s.NewManager(client, &k8s.ManagerConfig{
+			Namespace:     config.Namespace,
+			DynamicClient: dynamicClient,
+			DeleteRateLimiterConfig: k8s.RateLimiterConfig{
+				Frequency: config.DeleteFrequency,
+				Requests:  config.DeleteRequests,
+			},
+			ForceDelete: config.Force,
+		})
 		pterm.Success.Println("kubernetes resource manager initialized successfully!")
 
 		// clean section
 		blip()
 		pterm.DefaultSection.Println("clean")
 
-		resourceCount := len(config.Resources)
-		if resourceCount == 0 {
-			config.Resources = []string{"nodes", "pods", "jobs"}
-			resourceCount = 3
+		labelSelector := resources.RunSelector(simulator.LabelSelector, config.RunID)
+		if config.RunID != "" {
+			pterm.Info.Printf("cleaning up resources of run %s\n", config.RunID)
+			if slices.Contains(config.Resources, "events") || slices.Contains(config.Resources, "event") {
+				pterm.Warning.Println("events are not labeled with the run ID and are not cleaned up when --run-id is set")
+				config.Resources = slices.Filter(nil, config.Resources, func(r string) bool { return r != "events" && r != "event" })
+				resourceCount = len(config.Resources)
+			}
+			if slices.Contains(config.Resources, "namespaces") || slices.Contains(config.Resources, "namespace") {
+				pterm.Info.Println("generated namespaces are shared by runs and are not deleted when --run-id is set, the objects of the run are deleted in every generated namespace")
+				config.Resources = slices.Filter(nil, config.Resources, func(r string) bool { return r != "namespaces" && r != "namespace" })
+				resourceCount = len(config.Resources)
+			}
+		}
+
+		var errorList []error
+		var forceReport *k8s.ForceCleanReport
+		if config.Force {
+			// stuck and orphaned resources are removed first, otherwise waiting for them to terminate times out
+			s := startStep("force", "removing stuck and orphaned resources...")
+			var err error
+			forceReport, err = manager.ForceClean(cmd.Context(), k8s.ForceCleanOptions{
+				RunID:                  config.RunID,
+				SimulatorNamespace:     config.SimulatorNamespace,
+				SimulatorLabelSelector: simulator.LabelSelectorSimulator,
+			})
+			if forceReport != nil {
+				s.WithData(forceReport)
+			}
+			if err != nil {
+				errorList = append(errorList, err)
+				fatal = true
+				s.Fail("failed to remove stuck and orphaned resources", err)
+			} else {
+				s.Success(fmt.Sprintf("removed %d stuck and orphaned resources", forceReport.Total()))
+			}
+			if forceReport != nil && forceReport.Total() > 0 {
+				printForceCleanReport(forceReport)
+			}
 		}
 
 		pterm.Printf("cleaning up following resources: %v\n", config.Resources)
@@ -70,86 +150,194 @@ It's a comprehensive approach to maintaining a clean and efficient simulation en
 		multi := pterm.DefaultMultiPrinter
 		_, _ = multi.Start()
 
+		if emitter.Enabled() {
+			emitCtx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			go emitDeleteProgressEvery(emitCtx, 1*time.Second, manager)
+		}
+
 		// wait for nodes & pods to fully terminate
-		var errorList []error
 		async := false
 
 		if slices.Contains(config.Resources, "nodes") || slices.Contains(config.Resources, "node") {
 			go func() {
 				defer wg.Done()
-				spinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("cleaning up nodes...")
-				if err := manager.DeleteNodes(cmd.Context(), simulator.LabelSelector, async); err != nil {
+				s := startStepWithWriter("nodes", "cleaning up nodes...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, "nodes")
+				err := manager.DeleteNodes(cmd.Context(), labelSelector, async)
+				stop()
+				if err != nil {
 					errorList = append(errorList, err)
 					if errors.Is(err, context.DeadlineExceeded) {
 						warning = true
-						spinner.Warning("timed out waiting for all nodes to terminate")
+						s.Warning("timed out waiting for all nodes to terminate")
 					} else {
 						fatal = true
-						spinner.Fail("failed to cleanup nodes")
+						s.Fail("failed to cleanup nodes", err)
 						pterm.Error.Printf("%v", err)
 					}
 					return
 				}
-				spinner.Success("all nodes fully terminated!")
+				s.Success("all nodes fully terminated!")
 			}()
 		}
 
 		if slices.Contains(config.Resources, "pods") || slices.Contains(config.Resources, "pod") {
 			go func() {
 				defer wg.Done()
-				spinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("cleaning up pods...")
-				if err := manager.DeletePods(cmd.Context(), simulator.LabelSelector, async); err != nil {
+				s := startStepWithWriter("pods", "cleaning up pods...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, "pods")
+				err := manager.DeletePods(cmd.Context(), labelSelector, async)
+				stop()
+				if err != nil {
 					errorList = append(errorList, err)
 					if errors.Is(err, context.DeadlineExceeded) {
 						warning = true
-						spinner.Warning("timed out waiting for all pods to terminate")
+						s.Warning("timed out waiting for all pods to terminate")
 					} else {
 						fatal = true
-						spinner.Fail("failed to cleanup pods")
+						s.Fail("failed to cleanup pods", err)
 					}
 					return
 				}
-				spinner.Success("all pods fully terminated!")
+				s.Success("all pods fully terminated!")
 			}()
 		}
 
 		if slices.Contains(config.Resources, "jobs") || slices.Contains(config.Resources, "job") {
 			go func() {
 				defer wg.Done()
-				spinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("cleaning up jobs...")
-				if err := manager.DeleteJobs(cmd.Context(), simulator.LabelSelector, async); err != nil {
+				s := startStepWithWriter("jobs", "cleaning up jobs...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, "jobs")
+				err := manager.DeleteJobs(cmd.Context(), labelSelector, async)
+				stop()
+				if err != nil {
+					errorList = append(errorList, err)
+					if errors.Is(err, context.DeadlineExceeded) {
+						warning = true
+						s.Warning("timed out waiting for all jobs to terminate")
+					} else {
+						fatal = true
+						s.Fail("failed to cleanup jobs", err)
+						pterm.Error.Printf("%v", err)
+					}
+					return
+				}
+				s.Success("all jobs fully terminated!")
+			}()
+		}
+
+		if slices.Contains(config.Resources, "namespaces") || slices.Contains(config.Resources, "namespace") {
+			go func() {
+				defer wg.Done()
+				s := startStepWithWriter("namespaces", "cleaning up namespaces...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, "namespaces")
+				// deleting the generated namespaces of all runs deletes the pods and jobs which were spread across them
+				err := manager.DeleteNamespaces(cmd.Context(), resources.LabelSelectorFakeNamespace, async)
+				stop()
+				if err != nil {
+					errorList = append(errorList, err)
+					if errors.Is(err, context.DeadlineExceeded) {
+						warning = true
+						s.Warning("timed out waiting for all namespaces to terminate")
+					} else {
+						fatal = true
+						s.Fail("failed to cleanup namespaces", err)
+						pterm.Error.Printf("%v", err)
+					}
+					return
+				}
+				s.Success("all namespaces fully terminated!")
+			}()
+		}
+
+		if cleanObjects {
+			go func() {
+				defer wg.Done()
+				s := startStepWithWriter("objects", fmt.Sprintf("cleaning up %s...", objectsResource.Resource), multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, objectsResource.Resource)
+				err := manager.DeleteObjects(cmd.Context(), objectsResource, config.ObjectClusterScoped, resources.RunSelector(resources.LabelSelectorFakeObject, config.RunID), async)
+				stop()
+				if err != nil {
+					errorList = append(errorList, err)
+					if errors.Is(err, context.DeadlineExceeded) {
+						warning = true
+						s.Warning(fmt.Sprintf("timed out waiting for all %s to terminate", objectsResource.Resource))
+					} else {
+						fatal = true
+						s.Fail(fmt.Sprintf("failed to cleanup %s", objectsResource.Resource), err)
+						pterm.Error.Printf("%v", err)
+					}
+					return
+				}
+				s.Success(fmt.Sprintf("all %s fully terminated!", objectsResource.Resource))
+			}()
+		}
+
+		if cleanQueues {
+			go func() {
+				defer wg.Done()
+				s := startStepWithWriter("queues", "cleaning up kueue queues...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, resources.LocalQueues.Resource)
+				err := manager.DeleteQueues(cmd.Context(), resources.RunSelector(resources.LabelSelectorFakeQueue, config.RunID), async)
+				stop()
+				if err != nil {
+					errorList = append(errorList, err)
+					if errors.Is(err, context.DeadlineExceeded) {
+						warning = true
+						s.Warning("timed out waiting for all kueue queues to terminate")
+					} else {
+						fatal = true
+						s.Fail("failed to cleanup kueue queues", err)
+						pterm.Error.Printf("%v", err)
+					}
+					return
+				}
+				s.Success("all kueue queues fully terminated!")
+			}()
+		}
+
+		if cleanGangs {
+			go func() {
+				defer wg.Done()
+				s := startStepWithWriter("gangs", "cleaning up gangs...", multi.NewWriter())
+				stop := trackDeleteProgress(s, manager, gangStyle.PodGroupResource().Resource)
+				err := manager.DeleteGangs(cmd.Context(), gangStyle, resources.RunSelector(resources.LabelSelectorFakePodGroup, config.RunID), async)
+				stop()
+				if err != nil {
 					errorList = append(errorList, err)
 					if errors.Is(err, context.DeadlineExceeded) {
 						warning = true
-						spinner.Warning("timed out waiting for all jobs to terminate")
+						s.Warning("timed out waiting for all gangs to terminate")
 					} else {
 						fatal = true
-						spinner.Fail("failed to cleanup jobs")
+						s.Fail("failed to cleanup gangs", err)
 						pterm.Error.Printf("%v", err)
 					}
 					return
 				}
-				spinner.Success("all jobs fully terminated!")
+				s.Success("all gangs fully terminated!")
 			}()
 		}
 
 		if slices.Contains(config.Resources, "events") || slices.Contains(config.Resources, "event") {
 			go func() {
 				defer wg.Done()
-				spinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("cleaning up events...")
-				if err := manager.DeleteEvents(cmd.Context(), async); err != nil {
+				s := startStepWithWriter("events", "cleaning up events...", multi.NewWriter())
+				err := manager.DeleteEvents(cmd.Context(), async)
+				if err != nil {
 					errorList = append(errorList, err)
 					if errors.Is(err, context.DeadlineExceeded) {
 						warning = true
-						spinner.Warning("timed out waiting for all events to terminate")
+						s.Warning("timed out waiting for all events to terminate")
 					} else {
 						fatal = true
-						spinner.Fail("failed to cleanup events")
+						s.Fail("failed to cleanup events", err)
 						pterm.Error.Printf("%v", err)
 					}
 					return
 				}
-				spinner.Success("all events fully terminated!")
+				s.Success("all events fully terminated!")
 			}()
 		}
 
@@ -162,9 +350,14 @@ It's a comprehensive approach to maintaining a clean and efficient simulation en
 		blip()
 		pterm.DefaultSection.Println("status")
 		if len(errorList) > 0 {
+			errorMessages := make([]string, 0, len(errorList))
 			for _, err := range errorList {
 				pterm.Error.Printf("%v\n", err)
+				errorMessages = append(errorMessages, err.Error())
 			}
+			emitter.SetSummary(map[string]any{"runId": config.RunID, "resources": config.Resources, "deleted": manager.DeleteProgress(), "forced": forceReport, "errors": errorMessages})
+		} else {
+			emitter.SetSummary(map[string]any{"runId": config.RunID, "resources": config.Resources, "deleted": manager.DeleteProgress(), "forced": forceReport})
 		}
 		exitBasedOnStatus(fatal, warning)
 	},
@@ -172,16 +365,26 @@ It's a comprehensive approach to maintaining a clean and efficient simulation en
 
 func NewCleanCmd() *cobra.Command {
 	cleanCmd.Flags().StringVarP(&config.Namespace, "namespace", "n", config.Namespace, "namespace in which to create simulation resources")
-	cleanCmd.Flags().StringSliceVarP(&config.Resources, "resources", "r", config.Resources, "resources to delete (nodes, pods, jobs)")
+	cleanCmd.Flags().StringSliceVarP(&config.Resources, "resources", "r", config.Resources, "resources to delete (nodes, pods, jobs, namespaces, objects, queues, gangs, events)")
+	cleanCmd.Flags().StringVar(&config.ObjectResource, "object-resource", config.ObjectResource, "resource of the objects created from --object-template as <resource>.<version>.<group>, objects are deleted by default if it is set")
+	cleanCmd.Flags().BoolVar(&config.ObjectClusterScoped, "object-cluster-scoped", config.ObjectClusterScoped, "whether the objects created from --object-template are cluster-scoped")
+	cleanCmd.Flags().BoolVar(&config.Kueue, "kueue", config.Kueue, "whether the run submitted jobs to kueue, kueue queues are deleted by default if it is set")
+	cleanCmd.Flags().BoolVar(&config.Gangs, "gangs", config.Gangs, "whether the run created gangs, the PodGroups of gangs are deleted by default if it is set")
+	cleanCmd.Flags().StringVar(&config.GangStyle, "gang-style", config.GangStyle, "gang scheduler whose PodGroups were created (coscheduling or volcano)")
+	cleanCmd.Flags().BoolVar(&config.Force, "force", config.Force, "remove pods stuck terminating, orphaned pods & jobs and leftover simulator jobs, and delete pods with a grace period of 0")
+	cleanCmd.Flags().StringVar(&config.SimulatorNamespace, "simulator-namespace", config.SimulatorNamespace, "namespace of the simulator jobs removed by --force")
+	cleanCmd.Flags().DurationVar(&config.DeleteFrequency, "delete-frequency", config.DeleteFrequency, "frequency at which to delete resources")
+	cleanCmd.Flags().IntVar(&config.DeleteRequests, "delete-requests", config.DeleteRequests, "number of resources of each kind to delete per --delete-frequency")
+	cleanCmd.Flags().StringVar(&config.RunID, "run-id", config.RunID, "only delete the nodes, pods and jobs of the run, resources of all runs are deleted if empty")
 
 	validate := func() {
 		for _, r := range config.Resources {
 			switch r {
-			case "nodes", "node", "pods", "pod", "jobs", "job", "event", "events":
+			case "nodes", "node", "pods", "pod", "jobs", "job", "namespaces", "namespace", "objects", "object", "queues", "queue", "gangs", "gang", "event", "events":
 				continue
 			default:
-				slog.Error("unsupported resource type:" + r + ", --resources|-r supports only node(s),job(s),pod(s)")
-				os.Exit(1)
+				slog.Error("unsupported resource type:" + r + ", --resources|-r supports only node(s),job(s),pod(s),namespace(s),object(s),queue(s),gang(s),event(s)")
+				os.Exit(exitCodeFatal)
 			}
 		}
 	}
@@ -1,16 +1,24 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 	"path/filepath"
 
 	"github.com/pterm/pterm"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/homedir"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
 	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 )
 
 // Help is a helper function to print command help if 0 arguments passed and command requires an argument.
@@ -40,8 +48,72 @@ func addKubernetesConfigFlags(cmd *cobra.Command) {
 // getKubernetesConfig returns a k8s.Config based on the current configuration.
 func getKubernetesConfig() k8s.Config {
 	return k8s.Config{
-		QPS:   config.QPS,
-		Burst: config.Burst,
+		QPS:    config.QPS,
+		Burst:  config.Burst,
+		Tracer: requestTracer,
+	}
+}
+
+// requestTracer traces the API requests of all clients created after it is set.
+var requestTracer *k8s.RequestTracer
+
+// newKubernetesClient initializes the Kubernetes client and exits with a fatal exit code if it fails.
+// In offline mode, it returns an in-memory fake clientset which injects the configured latency and errors.
+func newKubernetesClient() kubernetes.Interface {
+	if config.Offline {
+		stepStarted("init", "initializing in-memory fake kubernetes client...")
+		return k8s.NewFakeClient(k8s.FaultConfig{Latency: config.OfflineLatency, ErrorRate: config.OfflineErrorRate})
+	}
+	stepStarted("init", "initializing kubernetes clients...")
+	client, err := k8s.NewClient(&config.Kubeconfig, getKubernetesConfig())
+	if err != nil {
+		stepFailed("init", "failed to initialize k8s client", err)
+		exit(exitCodeFatal)
+	}
+	return client
+}
+
+// newDynamicClient initializes the dynamic Kubernetes client and exits with a fatal exit code if it fails.
+func newDynamicClient() dynamic.Interface {
+	dynamicClient, err := k8s.NewDynamicClient(&config.Kubeconfig, getKubernetesConfig())
+	if err != nil {
+		stepFailed("init", "failed to initialize dynamic k8s client", err)
+		exit(exitCodeFatal)
+	}
+	return dynamicClient
+}
+
+// newObjectsDynamicClient initializes the dynamic Kubernetes client which creates the objects of the resources,
+// in offline mode an in-memory fake dynamic client which serves the resources, whose list kinds are keyed by resource.
+func newObjectsDynamicClient(listKinds map[schema.GroupVersionResource]string) dynamic.Interface {
+	if config.Offline {
+		return k8s.NewFakeDynamicClient(listKinds)
+	}
+	return newDynamicClient()
+}
+
+// gangSizes returns the gang size distribution of the configured sizes and weights.
+func gangSizes() ([]resources.GangSize, error) {
+	if len(config.GangSizeWeights) > 0 && len(config.GangSizeWeights) != len(config.GangSizes) {
+		return nil, fmt.Errorf("--gang-size-weights must have one weight per gang size, got %d weights for %d sizes", len(config.GangSizeWeights), len(config.GangSizes))
+	}
+	sizes := make([]resources.GangSize, 0, len(config.GangSizes))
+	for i, size := range config.GangSizes {
+		gangSize := resources.GangSize{Size: size}
+		if len(config.GangSizeWeights) > 0 {
+			gangSize.Weight = config.GangSizeWeights[i]
+		}
+		sizes = append(sizes, gangSize)
+	}
+	return sizes, resources.ValidateGangSizes(sizes)
+}
+
+// kueueListKinds returns the list kinds of the Kueue resources created by the simulator.
+func kueueListKinds() map[schema.GroupVersionResource]string {
+	return map[schema.GroupVersionResource]string{
+		resources.ResourceFlavors: "ResourceFlavorList",
+		resources.ClusterQueues:   "ClusterQueueList",
+		resources.LocalQueues:     "LocalQueueList",
 	}
 }
 
@@ -50,12 +122,67 @@ func exitBasedOnStatus(fatal, warning bool) {
 	switch {
 	case fatal:
 		pterm.Error.Println("one or more checks encountered fatal errors")
-		os.Exit(2)
+		exit(exitCodeFatal)
 	case warning:
 		pterm.Warning.Println("one or more checks failed")
-		os.Exit(1)
+		exit(exitCodeFailure)
 	default:
 		pterm.Success.Println("all checks passed")
-		os.Exit(0)
+		exit(exitCodeSuccess)
+	}
+}
+
+// newJobTracker creates a tracker for job metrics of fake jobs in the tracked namespace.
+func newJobTracker(client kubernetes.Interface) *measurement.JobTracker {
+	return measurement.NewJobTracker(
+		client,
+		trackedNamespace(),
+		resources.RunSelector(resources.LabelSelectorFakeJob, config.RunID),
+		resources.RunSelector(resources.LabelSelectorFakePod, config.RunID),
+		resources.LabelKeyJobClass,
+		measurement.WithQueueLabel(resources.LabelKeyQueueName),
+	)
+}
+
+// namespaceSpread returns the spread of pods and jobs across the configured namespaces,
+// it has no namespaces if pods and jobs are created in the configured namespace.
+func namespaceSpread() executor.Spread {
+	if config.NamespaceCount <= 1 {
+		return executor.Spread{}
+	}
+	namespaces := make([]string, config.NamespaceCount)
+	for i := range namespaces {
+		namespaces[i] = fmt.Sprintf("%s-%d", config.Namespace, i)
+	}
+	return executor.Spread{
+		Namespaces:   namespaces,
+		Distribution: executor.Distribution(config.NamespaceDistribution),
+		Weights:      config.NamespaceWeights,
+		ZipfExponent: config.ZipfExponent,
+	}
+}
+
+// objectResource returns the resource of the objects created from the template, the --object-resource if it is set
+// or the resource guessed from the kind of the template.
+func objectResource(template *unstructured.Unstructured) (schema.GroupVersionResource, error) {
+	if config.ObjectResource == "" {
+		if template == nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("--object-resource is required")
+		}
+		return resources.GuessResource(template), nil
+	}
+	resource, _ := schema.ParseResourceArg(config.ObjectResource)
+	if resource == nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("--object-resource must be <resource>.<version>.<group>, got %q", config.ObjectResource)
+	}
+	return *resource, nil
+}
+
+// trackedNamespace returns the namespace in which pods and jobs are tracked,
+// all namespaces if they are spread or tenants create them in their own namespaces.
+func trackedNamespace() string {
+	if config.NamespaceCount > 1 || config.Workloads != "" {
+		return metav1.NamespaceAll
 	}
+	return config.Namespace
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/compare"
+	"github.com/dejanzele/batch-simulator/internal/results"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <base-results-dir> <current-results-dir>",
+	Short: "Compare two simulation runs and detect regressions",
+	Long: `This command compares the summary metrics of two results directories written by run --results-dir,
+e.g. the same scenario against different Kubernetes versions or scheduler configurations.
+It prints the base and current value and the percentage change of every metric (throughput, latency percentiles,
+error rates, makespan...) and exits with a non-zero exit code if any metric got worse by more than its threshold,
+so it can be used to gate CI.
+
+Thresholds are percentages and can be overridden per metric with glob patterns, e.g.
+  batchsim compare run-a/ run-b/ --threshold 10 --metric-threshold '*.p99=25' --metric-threshold '*.error_rate=0'
+A metric which was zero in the base run is a regression as soon as it gets worse.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		pterm.DefaultHeader.Println("comparing runs...")
+
+		opts := compare.Options{Threshold: config.CompareThreshold, Ignore: config.CompareIgnore}
+		for _, s := range config.CompareMetricThresholds {
+			threshold, err := compare.ParseMetricThreshold(s)
+			if err != nil {
+				stepFailed("thresholds", "invalid metric threshold", err)
+				exit(exitCodeFatal)
+			}
+			opts.MetricThresholds = append(opts.MetricThresholds, threshold)
+		}
+
+		stepStarted("load", fmt.Sprintf("loading results from %s and %s...", args[0], args[1]))
+		base, err := results.Load(args[0])
+		if err != nil {
+			stepFailed("load", "failed to load base results", err)
+			exit(exitCodeFatal)
+		}
+		current, err := results.Load(args[1])
+		if err != nil {
+			stepFailed("load", "failed to load current results", err)
+			exit(exitCodeFatal)
+		}
+		stepSucceeded("load", "results loaded successfully!")
+
+		result := compare.Runs(&base.Summary, &current.Summary, opts)
+		printComparison(result)
+		emitter.SetSummary(map[string]any{"base": args[0], "current": args[1], "comparison": result})
+
+		s := startStep("compare", "checking regression thresholds...")
+		if result.Regressions > 0 {
+			s.Fail(fmt.Sprintf("%d metrics regressed beyond their threshold", result.Regressions), nil)
+			exit(exitCodeFailure)
+		}
+		s.Success(fmt.Sprintf("no regressions across %d metrics", len(result.Changes)))
+	},
+}
+
+func NewCompareCmd() *cobra.Command {
+	compareCmd.Flags().Float64Var(&config.CompareThreshold, "threshold", config.CompareThreshold, "maximum allowed worsening of a metric in percent")
+	compareCmd.Flags().StringArrayVar(&config.CompareMetricThresholds, "metric-threshold", config.CompareMetricThresholds, "per-metric threshold in <pattern>=<percent> format, the first matching pattern is used (e.g. '*.p99=25')")
+	compareCmd.Flags().StringArrayVar(&config.CompareIgnore, "ignore", config.CompareIgnore, "pattern of metrics which are never reported as regressions (e.g. 'scheduler.*')")
+
+	return compareCmd
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
-	"github.com/dejanzele/batch-simulator/internal/k8s"
 	"github.com/dejanzele/batch-simulator/internal/simulator"
 )
 
@@ -33,81 +32,85 @@ ensuring all necessary functionalities are in place and operational.`,
 		// init section
 		blip()
 		pterm.DefaultSection.Println("init")
-		pterm.Info.Println("initializing kubernetes clients...")
-
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
-		}
-		dynamicClient, err := k8s.NewDynamicClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize dynamic k8s client: %v", err)
-			os.Exit(1)
-		}
-		pterm.Success.Println("kubernetes client initialized successfully!")
+		client := newKubernetesClient()
+		dynamicClient := newDynamicClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
 
 		// install section
 		blip()
 		pterm.DefaultSection.Println("install")
 
-		spinner, _ := pterm.DefaultSpinner.Start("installing kwok operator...")
+		s := startStep("operator", "installing kwok operator...")
 		output, err := simulator.InstallOperator(cmd.Context(), config.KWOKNamespace)
 		if err != nil {
-			spinner.Fail("failed to install kwok operator")
+			s.Fail("failed to install kwok operator", err)
 			pterm.Error.Printf("%v\n", err)
-			os.Exit(2)
+			exit(exitCodeFatal)
 		}
-		spinner.Success("kwok operator installed successfully!")
+		s.Success("kwok operator installed successfully!")
 		pterm.Println(string(output))
 
-		spinner, _ = pterm.DefaultSpinner.Start("waiting for operator to become available...")
+		s = startStep("operator-available", "waiting for operator to become available...")
 		output, running, err := simulator.CheckIsOperatorRunning(cmd.Context(), client, config.KWOKNamespace)
 		if err != nil {
-			spinner.Fail("failed to check if kwok operator is running")
+			s.Fail("failed to check if kwok operator is running", err)
 			pterm.Error.Printf("%v\n", err)
-			os.Exit(2)
+			exit(exitCodeFatal)
 		}
 		if !running {
 			failed = true
-			spinner.Warning(string(output))
+			s.Warning(string(output))
 		} else {
-			spinner.Success(string(output))
+			s.Success(string(output))
 		}
 
-		spinner, _ = pterm.DefaultSpinner.Start("installing kwok stages...")
+		s = startStep("stages", "installing kwok stages...")
 		output, err = simulator.CreateStages(cmd.Context())
 		if err != nil {
-			failed = true
-			spinner.Fail("failed to install kwok stages")
+			s.Fail("failed to install kwok stages", err)
 			pterm.Error.Printf("%v\n", err)
-			os.Exit(2)
+			exit(exitCodeFatal)
 		}
-		spinner.Success("kwok stages installed successfully!")
+		s.Success("kwok stages installed successfully!")
 		pterm.Println(string(output))
 
-		spinner, _ = pterm.DefaultSpinner.Start("checking are kwok stages created...")
+		s = startStep("stages-created", "checking are kwok stages created...")
 		installed, missing, err := simulator.CheckAreStagesCreated(cmd.Context(), dynamicClient)
-		if err != nil {
+		switch {
+		case err != nil:
 			failed = true
-			spinner.Fail("failed to check if kwok stages are created")
+			s.Fail("failed to check if kwok stages are created", err)
 			pterm.Error.Printf("%v\n", err)
-		}
-		if !installed {
+		case !installed:
 			failed = true
-			spinner.Warning("stages not created: %v\n", missing)
-		} else {
-			spinner.Success("kwok stages created successfully!")
+			s.WithData(map[string]any{"missing": missing}).Warning(fmt.Sprintf("stages not created: %v", missing))
+		default:
+			s.Success("kwok stages created successfully!")
 		}
 
-		spinner, _ = pterm.DefaultSpinner.Start("installing necessary RBAC resources...")
+		s = startStep("rbac", "installing necessary RBAC resources...")
 		if err := simulator.CreateRBAC(cmd.Context(), client, config.Namespace); err != nil {
 			failed = true
-			spinner.Fail("failed to install RBAC resources")
+			s.Fail("failed to install RBAC resources", err)
 			pterm.Error.Printf("%v\n", err)
 		} else {
-			spinner.Success("RBAC resources installed successfully!")
+			s.Success("RBAC resources installed successfully!")
+		}
+
+		if config.Kueue {
+			s = startStep("kueue", "installing kueue CRDs...")
+			crdsInstalled, crdsSkipped, err := simulator.InstallKueueCRDs(cmd.Context(), dynamicClient)
+			s.WithData(map[string]any{"installed": crdsInstalled, "skipped": crdsSkipped})
+			switch {
+			case err != nil:
+				failed = true
+				s.Fail("failed to install kueue CRDs", err)
+				pterm.Error.Printf("%v\n", err)
+			case len(crdsSkipped) > 0:
+				s.Success(fmt.Sprintf("kueue CRDs installed, existing CRDs were kept: %v", crdsSkipped))
+			default:
+				s.Success("kueue CRDs installed successfully, admitting jobs requires the kueue controller")
+			}
 		}
 
 		// status section
@@ -115,14 +118,15 @@ ensuring all necessary functionalities are in place and operational.`,
 		pterm.DefaultSection.Println("status")
 		if failed {
 			pterm.Warning.Println("one or more components failed to install")
-		} else {
-			pterm.Success.Println("all components installed successfully")
+			exit(exitCodeFailure)
 		}
+		pterm.Success.Println("all components installed successfully")
 	},
 }
 
 func NewInitCmd() *cobra.Command {
 	addKubeconfigFlag(installCmd)
 	addKubernetesConfigFlags(installCmd)
+	installCmd.Flags().BoolVar(&config.Kueue, "kueue", config.Kueue, "install the kueue CRDs required by run --kueue, existing CRDs are kept")
 	return installCmd
 }
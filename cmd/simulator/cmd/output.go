@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pterm/pterm"
+
+	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/output"
+)
+
+// Exit codes returned by every command.
+const (
+	// exitCodeSuccess is returned when the command finished successfully.
+	exitCodeSuccess = 0
+	// exitCodeFailure is returned when the command finished but one or more steps failed.
+	exitCodeFailure = 1
+	// exitCodeFatal is returned when the command could not be completed.
+	exitCodeFatal = 2
+)
+
+// emitter emits structured events when a machine-readable output format is selected.
+var emitter = output.New(os.Stdout, output.FormatHuman, "")
+
+// initOutput configures the emitter for the given command.
+// In machine-readable formats, all pterm rendering is disabled so stdout contains only structured events.
+func initOutput(command string) {
+	format, err := output.ParseFormat(config.Output)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(exitCodeFatal)
+	}
+	emitter = output.New(os.Stdout, format, command)
+	if emitter.Enabled() {
+		config.NoGUI = true
+		pterm.DisableOutput()
+	}
+}
+
+// exit emits the summary event and exits with the provided code.
+func exit(code int) {
+	_ = emitter.Close(code)
+	os.Exit(code)
+}
+
+// step reports a single unit of work through a spinner in human mode and as structured events otherwise.
+type step struct {
+	name    string
+	spinner *pterm.SpinnerPrinter
+	data    any
+}
+
+// startStep starts a new step with the given name and spinner text.
+func startStep(name, text string) *step {
+	return startStepWithWriter(name, text, nil)
+}
+
+// startStepWithWriter starts a new step which renders its spinner to the given writer.
+func startStepWithWriter(name, text string, writer io.Writer) *step {
+	printer := pterm.DefaultSpinner
+	if writer != nil {
+		printer = *printer.WithWriter(writer)
+	}
+	spinner, _ := printer.Start(text)
+	emitter.Step(output.EventStepStarted, name, text, nil, nil)
+	return &step{name: name, spinner: spinner}
+}
+
+// WithData attaches a payload which is emitted with the step result.
+func (s *step) WithData(data any) *step {
+	s.data = data
+	return s
+}
+
+// UpdateText updates the spinner text, it is not emitted as an event.
+func (s *step) UpdateText(text string) {
+	s.spinner.UpdateText(text)
+}
+
+// Success marks the step as succeeded.
+func (s *step) Success(message string) {
+	s.spinner.Success(message)
+	emitter.Step(output.EventStepSucceeded, s.name, message, nil, s.data)
+}
+
+// Warning marks the step as finished with a non-fatal problem.
+func (s *step) Warning(message string) {
+	s.spinner.Warning(message)
+	emitter.Step(output.EventStepWarning, s.name, message, nil, s.data)
+}
+
+// Fail marks the step as failed with the given error.
+func (s *step) Fail(message string, err error) {
+	s.spinner.Fail(message)
+	emitter.Step(output.EventStepFailed, s.name, message, err, s.data)
+}
+
+// stepStarted prints an informational line and emits a step started event.
+func stepStarted(name, message string) {
+	pterm.Info.Println(message)
+	emitter.Step(output.EventStepStarted, name, message, nil, nil)
+}
+
+// stepSucceeded prints a success line and emits a step succeeded event.
+func stepSucceeded(name, message string) {
+	pterm.Success.Println(message)
+	emitter.Step(output.EventStepSucceeded, name, message, nil, nil)
+}
+
+// stepFailed prints an error line and emits a step failed event.
+func stepFailed(name, message string, err error) {
+	pterm.Error.Printf("%s: %v\n", message, err)
+	emitter.Step(output.EventStepFailed, name, message, err, nil)
+}
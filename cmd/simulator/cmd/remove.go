@@ -1,13 +1,11 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
-	"github.com/dejanzele/batch-simulator/internal/k8s"
-
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
 	"github.com/dejanzele/batch-simulator/internal/simulator"
 )
@@ -29,41 +27,52 @@ These steps are crucial for reverting the simulation environment to its original
 		// init section
 		blip()
 		pterm.DefaultSection.Println("init")
-		pterm.Info.Println("initializing kubernetes clients...")
-
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
-		}
+		client := newKubernetesClient()
 
 		// uninstall section
 		blip()
 
-		pterm.Info.Println("uninstalling kwok stages...")
+		stepStarted("stages", "uninstalling kwok stages...")
 		output, err := simulator.DeleteStages(cmd.Context())
 		if err != nil {
 			failed = true
-			pterm.Error.Printf("failed to uninstall kwok stages: %v\n", err)
+			stepFailed("stages", "failed to uninstall kwok stages", err)
+		} else {
+			stepSucceeded("stages", "kwok stages uninstalled successfully!")
 		}
 		pterm.Println(string(output))
 
 		pterm.DefaultSection.Println("uninstall")
-		pterm.Info.Println("uninstalling kwok operator...")
+		stepStarted("operator", "uninstalling kwok operator...")
 		output, err = simulator.UninstallOperator(cmd.Context(), config.KWOKNamespace)
 		if err != nil {
 			failed = true
-			pterm.Error.Printf("failed to uninstall kwok operator: %v\n", err)
+			stepFailed("operator", "failed to uninstall kwok operator", err)
+		} else {
+			stepSucceeded("operator", "kwok operator uninstalled successfully!")
 		}
 		pterm.Println(string(output))
 
-		pterm.Info.Println("uninstalling RBAC resources...")
+		stepStarted("rbac", "uninstalling RBAC resources...")
 		if err := simulator.DeleteRBAC(cmd.Context(), client, config.Namespace); err != nil {
 			failed = true
-			pterm.Error.Printf("failed to uninstall RBAC resources: %v\n", err)
+			stepFailed("rbac", "failed to uninstall RBAC resources", err)
 		} else {
-			pterm.Success.Println("RBAC resources uninstalled successfully!")
+			stepSucceeded("rbac", "RBAC resources uninstalled successfully!")
+		}
+
+		if config.Kueue {
+			stepStarted("kueue", "uninstalling kueue CRDs...")
+			crdsDeleted, crdsSkipped, err := simulator.DeleteKueueCRDs(cmd.Context(), newDynamicClient())
+			switch {
+			case err != nil:
+				failed = true
+				stepFailed("kueue", "failed to uninstall kueue CRDs", err)
+			case len(crdsSkipped) > 0:
+				stepSucceeded("kueue", fmt.Sprintf("kueue CRDs %v uninstalled, CRDs not installed by the simulator were kept: %v", crdsDeleted, crdsSkipped))
+			default:
+				stepSucceeded("kueue", "kueue CRDs uninstalled successfully!")
+			}
 		}
 
 		// status section
@@ -71,12 +80,13 @@ These steps are crucial for reverting the simulation environment to its original
 		pterm.DefaultSection.Println("status")
 		if failed {
 			pterm.Error.Println("one or more components failed to uninstall")
-		} else {
-			pterm.Success.Println("all components uninstalled successfully")
+			exit(exitCodeFailure)
 		}
+		pterm.Success.Println("all components uninstalled successfully")
 	},
 }
 
 func NewRemoveCmd() *cobra.Command {
+	removeCmd.Flags().BoolVar(&config.Kueue, "kueue", config.Kueue, "uninstall the kueue CRDs installed by install --kueue, which deletes their kueue objects")
 	return removeCmd
 }
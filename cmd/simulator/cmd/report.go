@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/report"
+	"github.com/dejanzele/batch-simulator/internal/results"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <results-dir>",
+	Short: "Generate an HTML report of a simulation run",
+	Long: `This command generates a self-contained HTML report from the results directory written by run --results-dir.
+The report contains the configuration, creation throughput, latency percentiles, error breakdown,
+pod phase counts over time and scheduling latency histograms, and embeds all styles and charts,
+so it can be shared as a single file and opened without network access.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pterm.DefaultHeader.Println("generating report...")
+
+		dir := args[0]
+		stepStarted("load", fmt.Sprintf("loading results from %s...", dir))
+		run, err := results.Load(dir)
+		if err != nil {
+			stepFailed("load", "failed to load results", err)
+			exit(exitCodeFatal)
+		}
+		stepSucceeded("load", "results loaded successfully!")
+
+		file := config.ReportFile
+		if file == "" {
+			file = filepath.Join(dir, "report.html")
+		}
+		stepStarted("report", fmt.Sprintf("writing report to %s...", file))
+		var buf bytes.Buffer
+		if err := report.Generate(&buf, run); err != nil {
+			stepFailed("report", "failed to generate report", err)
+			exit(exitCodeFatal)
+		}
+		if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+			stepFailed("report", "failed to write report", err)
+			exit(exitCodeFatal)
+		}
+		stepSucceeded("report", fmt.Sprintf("report written to %s", file))
+		emitter.SetSummary(map[string]any{"results": dir, "report": file})
+	},
+}
+
+func NewReportCmd() *cobra.Command {
+	reportCmd.Flags().StringVarP(&config.ReportFile, "file", "f", config.ReportFile, "path of the generated HTML report, defaults to report.html in the results directory")
+
+	return reportCmd
+}
@@ -15,9 +15,16 @@ var rootCmd = &cobra.Command{
 	Short: "kwok-based batch simulation tool",
 	Long: `This command-line interface (CLI) tool facilitates the simulation of batch scheduling scenarios,
 leveraging Kubernetes (k8s) and Kwok technologies.
-It's designed for users who need to model and understand various batch processing workflows within a k8s environment.`,
+It's designed for users who need to model and understand various batch processing workflows within a k8s environment.
+
+Every command supports machine-readable output using --output json|ndjson and exits with
+0 on success, 1 if one or more steps failed and 2 if the command could not be completed.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		logger.Init(getLogLevel())
+		initOutput(cmd.Name())
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		exit(exitCodeSuccess)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if Help(cmd, args) {
@@ -31,12 +38,16 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().BoolVarP(&config.Debug, "debug", "d", false, "enable debug output")
 	rootCmd.PersistentFlags().BoolVarP(&config.Silent, "silent", "s", false, "disable internal logging")
 	rootCmd.PersistentFlags().BoolVar(&config.NoGUI, "no-gui", false, "disable printing graphical elements")
+	rootCmd.PersistentFlags().StringVarP(&config.Output, "output", "o", config.Output, "output format (human, json, ndjson)")
 	rootCmd.AddCommand(NewCheckCmd())
 	rootCmd.AddCommand(NewInitCmd())
 	rootCmd.AddCommand(NewRemoveCmd())
 	rootCmd.AddCommand(NewRunCmd())
 	rootCmd.AddCommand(NewCleanCmd())
 	rootCmd.AddCommand(NewWatchCmd())
+	rootCmd.AddCommand(NewRunsCmd())
+	rootCmd.AddCommand(NewReportCmd())
+	rootCmd.AddCommand(NewCompareCmd())
 	rootCmd.MarkFlagsMutuallyExclusive("verbose", "debug", "silent")
 	return rootCmd
 }
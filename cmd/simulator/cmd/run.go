@@ -11,6 +11,8 @@ import (
 	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/dejanzele/batch-simulator/internal/simulator"
@@ -19,7 +21,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/assertion"
 	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/metrics"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/dejanzele/batch-simulator/internal/workload"
 )
 
 var runCmd = &cobra.Command{
@@ -32,6 +42,119 @@ The process is designed to mimic real-world Kubernetes environments for testing
 	Run: func(cmd *cobra.Command, args []string) {
 		pterm.DefaultHeader.Println("running simulation...")
 
+		assertions, err := assertion.ParseAll(config.Assertions)
+		if err != nil {
+			stepFailed("assertions", "invalid assertion", err)
+			exit(exitCodeFatal)
+		}
+		if len(assertions) > 0 {
+			// remote runs execute the rate limiters in the simulator job, the local metrics would always pass
+			if config.Remote {
+				stepFailed("assertions", "invalid configuration", fmt.Errorf("--assert cannot be combined with --remote"))
+				exit(exitCodeFatal)
+			}
+			if !config.Measure {
+				pterm.Info.Println("assertions are evaluated against measured pod, scheduler and job metrics, enabling --measure")
+				config.Measure = true
+			}
+		}
+
+		if config.Offline {
+			validateOfflineConfig()
+		}
+
+		// seed before anything random is generated, so the whole run is reproducible from the recorded seed
+		seeded := config.Seed != 0
+		if !seeded {
+			config.Seed = time.Now().UnixNano()
+		}
+		util.Seed(config.Seed)
+
+		switch executor.Naming(config.Naming) {
+		case executor.NamingRandom, executor.NamingSequential:
+		default:
+			stepFailed("naming", "invalid configuration", fmt.Errorf("--naming must be %s or %s, got %q", executor.NamingRandom, executor.NamingSequential, config.Naming))
+			exit(exitCodeFatal)
+		}
+		spread := namespaceSpread()
+		if config.NamespaceCount < 1 {
+			stepFailed("namespaces", "invalid configuration", fmt.Errorf("--namespaces must be at least 1, got %d", config.NamespaceCount))
+			exit(exitCodeFatal)
+		}
+		if len(spread.Namespaces) > 0 {
+			if err := spread.Validate(); err != nil {
+				stepFailed("namespaces", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
+		}
+		var tenants *workload.Config
+		if config.Workloads != "" {
+			if config.Remote || config.Resume {
+				stepFailed("workloads", "invalid configuration", fmt.Errorf("--workloads cannot be combined with --remote or --resume"))
+				exit(exitCodeFatal)
+			}
+			tenants, err = workload.Load(config.Workloads)
+			if err != nil {
+				stepFailed("workloads", "invalid workloads", err)
+				exit(exitCodeFatal)
+			}
+		}
+		var objectTemplate *unstructured.Unstructured
+		var objectsResource schema.GroupVersionResource
+		if config.ObjectTemplate != "" {
+			if config.Remote {
+				stepFailed("objects", "invalid configuration", fmt.Errorf("--object-template cannot be combined with --remote"))
+				exit(exitCodeFatal)
+			}
+			objectTemplate, err = resources.LoadObjectTemplate(config.ObjectTemplate)
+			if err != nil {
+				stepFailed("objects", "invalid object template", err)
+				exit(exitCodeFatal)
+			}
+			objectsResource, err = objectResource(objectTemplate)
+			if err != nil {
+				stepFailed("objects", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
+		}
+		var gangSizeDistribution []resources.GangSize
+		if config.Gangs {
+			if config.Remote {
+				stepFailed("gangs", "invalid configuration", fmt.Errorf("--gangs cannot be combined with --remote"))
+				exit(exitCodeFatal)
+			}
+			if err := resources.GangStyle(config.GangStyle).Validate(); err != nil {
+				stepFailed("gangs", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
+			gangSizeDistribution, err = gangSizes()
+			if err != nil {
+				stepFailed("gangs", "invalid configuration", err)
+				exit(exitCodeFatal)
+			}
+		}
+		if config.Kueue && config.Remote {
+			stepFailed("kueue", "invalid configuration", fmt.Errorf("--kueue cannot be combined with --remote"))
+			exit(exitCodeFatal)
+		}
+		if config.Resume && config.RunID == "" {
+			stepFailed("resume", "invalid configuration", fmt.Errorf("--resume requires the --run-id of the interrupted run"))
+			exit(exitCodeFatal)
+		}
+		switch {
+		case config.RunID == "" && seeded:
+			config.RunID = resources.NewSeededRunID(util.Default())
+		case config.RunID == "":
+			config.RunID = resources.NewRunID()
+		default:
+			if err := resources.ValidateRunID(config.RunID); err != nil {
+				stepFailed("run-id", "invalid run ID", err)
+				exit(exitCodeFatal)
+			}
+		}
+		resources.RunID = config.RunID
+		pterm.Info.Printf("run ID: %s\n", config.RunID)
+
 		// Print config section
 		blip()
 		printSimulationConfig()
@@ -40,19 +163,19 @@ The process is designed to mimic real-world Kubernetes environments for testing
 		blip()
 		pterm.DefaultSection.Println("init")
 
-		pterm.Info.Println("initializing kubernetes clients...")
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
+		if config.TraceRequests {
+			requestTracer = k8s.NewRequestTracer()
 		}
-		pterm.Success.Println("kubernetes client initialized successfully!")
+		client := newKubernetesClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
 
-		pterm.Info.Println("initializing kubernetes resource manager...")
+		stepStarted("manager", "initializing kubernetes resource manager...")
 		managerConfig := k8s.ManagerConfig{
 			Namespace:     config.Namespace,
 			RandomEnvVars: config.RandomEnvVars,
+			RunID:         config.RunID,
+			Naming:        executor.Naming(config.Naming),
+			Spread:        spread,
 			PodRateLimiterConfig: k8s.RateLimiterConfig{
 				Frequency: config.PodCreatorFrequency,
 				Requests:  config.PodCreatorRequests,
@@ -69,20 +192,124 @@ The process is designed to mimic real-world Kubernetes environments for testing
 				Limit:     config.JobCreatorLimit,
 			},
 		}
+		failures := metrics.NewFailureRecorder()
+		observers := ratelimiter.Observers{failures}
+		var exporter *metrics.Exporter
+		if config.MetricsAddr != "" {
+			exporter = metrics.NewExporter()
+			observers = append(observers, exporter)
+		}
+		managerConfig.Observer = observers
+		if tenants != nil {
+			managerConfig.Workloads = tenants.Workloads(client, workload.Options{
+				RandomEnvVars: config.RandomEnvVars,
+				Naming:        executor.Naming(config.Naming),
+				RunID:         config.RunID,
+				Observer:      observers,
+			})
+		}
+		listKinds := make(map[schema.GroupVersionResource]string)
+		if objectTemplate != nil {
+			listKinds[objectsResource] = objectTemplate.GetKind() + "List"
+			managerConfig.Objects = &k8s.ObjectsConfig{
+				Resource:      objectsResource,
+				ClusterScoped: config.ObjectClusterScoped,
+				Template:      objectTemplate,
+				RateLimiterConfig: k8s.RateLimiterConfig{
+					Frequency: config.ObjectCreatorFrequency,
+					Requests:  config.ObjectCreatorRequests,
+					Limit:     config.ObjectCreatorLimit,
+				},
+			}
+		}
+		if config.Kueue {
+			for resource, listKind := range kueueListKinds() {
+				listKinds[resource] = listKind
+			}
+			managerConfig.Kueue = &k8s.KueueConfig{
+				ResourceFlavor: config.KueueResourceFlavor,
+				ClusterQueue:   config.KueueClusterQueue,
+				LocalQueue:     config.KueueLocalQueue,
+				Quota:          resources.NodePoolQuota(config.NodeCreatorLimit),
+			}
+		}
+		if config.Gangs {
+			gangStyle := resources.GangStyle(config.GangStyle)
+			listKinds[gangStyle.PodGroupResource()] = "PodGroupList"
+			managerConfig.Gangs = &k8s.GangsConfig{
+				Style:         gangStyle,
+				SchedulerName: config.GangSchedulerName,
+				Sizes:         gangSizeDistribution,
+				RateLimiterConfig: k8s.RateLimiterConfig{
+					Frequency: config.GangCreatorFrequency,
+					Requests:  config.GangCreatorRequests,
+					Limit:     config.GangCreatorLimit,
+				},
+			}
+		}
+		if len(listKinds) > 0 {
+			managerConfig.DynamicClient = newObjectsDynamicClient(listKinds)
+		}
 		manager := k8s.NewManager(client, &managerConfig)
-		pterm.Success.Println("kubernetes resource manager initialized successfully!")
+		stepSucceeded("manager", "kubernetes resource manager initialized successfully!")
 
-		pterm.Info.Println("initializing namespaces")
-		if err = k8s.CreateNamespaceIfNeed(cmd.Context(), client, config.Namespace, slog.Default()); err != nil {
-			pterm.Error.Printf("error checking should namespace %s be created: %v\n", config.Namespace, err)
-			os.Exit(1)
+		if exporter != nil {
+			exporter.RegisterRates(manager.Rates)
+			go func() {
+				if err := exporter.Serve(cmd.Context(), config.MetricsAddr); err != nil {
+					stepFailed("metrics", "failed to serve prometheus metrics", err)
+				}
+			}()
+			pterm.Info.Printf("serving prometheus metrics on %s/metrics\n", config.MetricsAddr)
 		}
 
-		if err = k8s.CreateNamespaceIfNeed(cmd.Context(), client, config.SimulatorNamespace, slog.Default()); err != nil {
-			pterm.Error.Printf("error checking should namespace %s be created: %v\n", config.Namespace, err)
-			os.Exit(1)
+		stepStarted("namespaces", "initializing namespaces")
+		if err := k8s.CreateNamespaceIfNeed(cmd.Context(), client, config.Namespace, slog.Default()); err != nil {
+			stepFailed("namespaces", fmt.Sprintf("error checking should namespace %s be created", config.Namespace), err)
+			exit(exitCodeFatal)
+		}
+
+		if err := k8s.CreateNamespaceIfNeed(cmd.Context(), client, config.SimulatorNamespace, slog.Default()); err != nil {
+			stepFailed("namespaces", fmt.Sprintf("error checking should namespace %s be created", config.SimulatorNamespace), err)
+			exit(exitCodeFatal)
+		}
+		stepSucceeded("namespaces", "namespaces initialized")
+
+		if config.Preflight {
+			s := startStep("preflight", "running preflight checks...")
+			findings := simulator.RunPreflight(cmd.Context(), client, config.Namespace)
+			s.WithData(findings)
+			switch simulator.MaxSeverity(findings) {
+			case simulator.SeverityFatal:
+				s.Fail("preflight checks found settings which will reject simulation resources", nil)
+				printPreflightFindings(findings)
+				exit(exitCodeFatal)
+			case simulator.SeverityWarning:
+				s.Warning("preflight checks found settings which could reject or throttle simulation resources")
+			default:
+				s.Success("preflight checks passed")
+			}
+			printPreflightFindings(findings)
+		}
+
+		if config.Kueue {
+			s := startStep("kueue", fmt.Sprintf("setting up kueue cluster queue %s...", config.KueueClusterQueue))
+			if err := manager.SetupKueue(cmd.Context()); err != nil {
+				s.Fail("failed to set up kueue", err)
+				exit(exitCodeFatal)
+			}
+			s.Success(fmt.Sprintf("jobs are submitted to local queue %s of cluster queue %s", config.KueueLocalQueue, config.KueueClusterQueue))
+		}
+
+		if config.Resume {
+			s := startStep("resume", fmt.Sprintf("counting resources of run %s...", config.RunID))
+			nodes, pods, jobs, err := manager.Resume(cmd.Context())
+			if err != nil {
+				s.Fail("failed to resume run", err)
+				exit(exitCodeFatal)
+			}
+			s.Success(fmt.Sprintf("resuming run %s with %d nodes, %d pods and %d jobs already created", config.RunID, nodes, pods, jobs))
 		}
-		pterm.Info.Println("namespaces initialized")
 
 		pterm.Info.Printf("setting the default env vars type to %s type\n", config.DefaultEnvVarsType)
 		resources.SetDefaultEnvVarsType(config.DefaultEnvVarsType)
@@ -90,7 +317,64 @@ The process is designed to mimic real-world Kubernetes environments for testing
 		resources.EnvVarCount = config.EnvVarCount
 		pterm.Success.Printf("setting max env var size to %d bytes\n", config.MaxEnvVarSize)
 		resources.MaxEnvVarSize = config.MaxEnvVarSize
+		pterm.Success.Printf("setting job class to %s\n", config.JobClass)
+		resources.JobClass = config.JobClass
+
+		var scheduler *measurement.SchedulerTracker
+		var pods *measurement.PodTracker
+		var jobs *measurement.JobTracker
+		if config.Measure {
+			s := startStep("scheduler", "starting scheduler throughput measurement...")
+			podSelector := resources.RunSelector(resources.LabelSelectorFakePod, config.RunID)
+			scheduler = measurement.NewSchedulerTracker(client, trackedNamespace(), podSelector)
+			if err := scheduler.Start(cmd.Context(), 1*time.Second); err != nil {
+				s.Fail("failed to start scheduler throughput measurement", err)
+				exit(exitCodeFatal)
+			}
+			s.Success("scheduler throughput measurement started")
+
+			s = startStep("pods", "starting pod lifecycle measurement...")
+			pods = measurement.NewPodTracker(client, trackedNamespace(), podSelector)
+			if err := pods.Start(cmd.Context()); err != nil {
+				s.Fail("failed to start pod lifecycle measurement", err)
+				exit(exitCodeFatal)
+			}
+			go pods.Record(cmd.Context(), 1*time.Second)
+			s.Success("pod lifecycle measurement started")
+
+			s = startStep("jobs", "starting job metrics measurement...")
+			jobs = newJobTracker(client)
+			if err := jobs.Start(cmd.Context()); err != nil {
+				s.Fail("failed to start job metrics measurement", err)
+				exit(exitCodeFatal)
+			}
+			s.Success("job metrics measurement started")
+		}
 
+		var gangs *measurement.GangTracker
+		if config.Gangs {
+			s := startStep("gangs", "starting gang placement measurement...")
+			gangs = measurement.NewGangTracker(
+				client,
+				trackedNamespace(),
+				resources.RunSelector(resources.LabelSelectorFakeGangPod, config.RunID),
+				resources.LabelKeyGang,
+				resources.LabelKeyGangSize,
+			)
+			if err := gangs.Start(cmd.Context()); err != nil {
+				s.Fail("failed to start gang placement measurement", err)
+				exit(exitCodeFatal)
+			}
+			s.Success("gang placement measurement started")
+		}
+
+		var scraper *metrics.APIServerScraper
+		if config.ScrapeAPIServerMetrics {
+			scraper = startAPIServerScraper(cmd.Context(), client)
+		}
+
+		stepStarted("simulation", "starting simulation")
+		startedAt := time.Now()
 		if config.Remote {
 			pterm.Success.Println("running simulation in remote Kubernetes cluster")
 			err = runRemote(cmd.Context(), client)
@@ -98,17 +382,150 @@ The process is designed to mimic real-world Kubernetes environments for testing
 			pterm.Success.Println("running simulation from local machine")
 			err = runLocal(cmd.Context(), manager)
 		}
+		finishedAt := time.Now()
+		if err == nil && (pods != nil || gangs != nil) && !k8s.SchedulesPods(client) {
+			pterm.Info.Println("pods are never scheduled in offline mode, reporting them without waiting for them to settle")
+		} else if err == nil {
+			if pods != nil {
+				waitForPodsToSettle(cmd.Context(), pods)
+			}
+			if gangs != nil {
+				waitForGangsToSettle(cmd.Context(), gangs)
+			}
+		}
+		summary := map[string]any{
+			"runId":      config.RunID,
+			"config":     simulationConfig(),
+			"metrics":    metricsSnapshot(manager),
+			"namespaces": manager.NamespaceMetrics(),
+			"failures":   failures.Failures(),
+		}
+		var schedulerReport *measurement.SchedulerReport
+		var podsReport *measurement.PodReport
+		var jobsReport map[string]measurement.JobClassReport
+		var queuesReport map[string]measurement.QueueReport
+		if config.Measure {
+			schedulerSummary, podsSummary := scheduler.Report(), pods.Report()
+			schedulerReport, podsReport = &schedulerSummary, &podsSummary
+			jobsReport, queuesReport = jobs.Report(), jobs.QueueReport()
+			summary["scheduler"] = schedulerReport
+			summary["pods"] = podsReport
+			summary["jobs"] = jobsReport
+			summary["queues"] = queuesReport
+		}
+		var requestsReport *k8s.RequestReport
+		if requestTracer != nil {
+			report := requestTracer.Report()
+			requestsReport = &report
+			summary["requests"] = requestsReport
+		}
+		var gangsReport *measurement.GangReport
+		if gangs != nil {
+			report := gangs.Report()
+			gangsReport = &report
+			summary["gangs"] = gangsReport
+		}
+		var apiServerReport *metrics.APIServerReport
+		if scraper != nil {
+			apiServerReport = finishAPIServerScraper(cmd.Context(), scraper)
+			summary["apiServer"] = apiServerReport
+		}
+		runSummary := &results.Summary{
+			RunID:      config.RunID,
+			Seed:       config.Seed,
+			StartedAt:  startedAt,
+			FinishedAt: finishedAt,
+			Executors:  manager.Summaries(),
+			Namespaces: manager.NamespaceMetrics(),
+			Failures:   failures.Failures(),
+			Pods:       podsReport,
+			Scheduler:  schedulerReport,
+			Jobs:       jobsReport,
+			Queues:     queuesReport,
+			Gangs:      gangsReport,
+			Requests:   requestsReport,
+			APIServer:  apiServerReport,
+		}
+		var evaluated []assertion.Result
+		if len(assertions) > 0 {
+			evaluated = assertion.Evaluate(assertions, results.Metrics(runSummary))
+			summary["assertions"] = evaluated
+		}
+		emitter.SetSummary(summary)
+		if config.ResultsDir != "" {
+			writeResults(manager, scheduler, pods, runSummary)
+		}
 		if err != nil {
-			pterm.Error.Printf("failed to run simulation: %v", err)
-			os.Exit(1)
+			stepFailed("simulation", "failed to run simulation", err)
+			exit(exitCodeFailure)
 		}
 		// status section
 		blip()
 		pterm.DefaultSection.Println("status")
-		pterm.Success.Println("simulator finished successfully!")
+		if config.Measure {
+			printPodReport(*podsReport)
+			printSchedulerReport(*schedulerReport)
+			printJobsReport(jobsReport)
+			printQueueReport(queuesReport)
+		}
+		if gangsReport != nil {
+			printGangReport(gangsReport)
+		}
+		if len(manager.Workloads()) > 0 {
+			printWorkloads(manager.Workloads())
+		}
+		if len(spread.Namespaces) > 0 || len(manager.Workloads()) > 0 {
+			printNamespaceMetrics(manager.NamespaceMetrics())
+		}
+		if requestsReport != nil {
+			printRequestReport(requestsReport)
+		}
+		if apiServerReport != nil {
+			printAPIServerReport(apiServerReport)
+		}
+		if len(evaluated) > 0 {
+			s := startStep("assertions", fmt.Sprintf("evaluating %d assertions...", len(evaluated)))
+			failed := assertion.Failed(evaluated)
+			if failed > 0 {
+				s.Fail(fmt.Sprintf("%d of %d assertions failed", failed, len(evaluated)), nil)
+				printAssertions(evaluated)
+				exit(exitCodeFailure)
+			}
+			s.Success(fmt.Sprintf("all %d assertions passed", len(evaluated)))
+			printAssertions(evaluated)
+		}
+		pterm.Info.Printf("resources of run %s can be deleted with: batchsim clean --run-id %s\n", config.RunID, config.RunID)
+		stepSucceeded("simulation", "simulator finished successfully!")
 	},
 }
 
+// validateOfflineConfig exits if the configuration cannot be used in offline mode
+// and disables the features which require a real API server.
+func validateOfflineConfig() {
+	if config.Remote {
+		stepFailed("offline", "invalid configuration", fmt.Errorf("--offline cannot be combined with --remote"))
+		exit(exitCodeFatal)
+	}
+	if config.OfflineErrorRate < 0 || config.OfflineErrorRate > 1 {
+		stepFailed("offline", "invalid configuration", fmt.Errorf("--offline-error-rate must be between 0 and 1, got %v", config.OfflineErrorRate))
+		exit(exitCodeFatal)
+	}
+	for _, feature := range []struct {
+		enabled *bool
+		flag    string
+	}{
+		{&config.Preflight, "--preflight"},
+		{&config.TraceRequests, "--trace-requests"},
+		{&config.ScrapeAPIServerMetrics, "--scrape-apiserver-metrics"},
+	} {
+		if *feature.enabled {
+			pterm.Warning.Printf("%s requires a cluster and is disabled in offline mode\n", feature.flag)
+			*feature.enabled = false
+		}
+	}
+	pterm.Warning.Println("running offline against an in-memory fake clientset, pods are never scheduled and stay pending")
+}
+
 func runRemote(ctx context.Context, client kubernetes.Interface) error {
 	args := []string{
 		"--node-creator-frequency", config.NodeCreatorFrequency.String(),
@@ -124,30 +541,162 @@ func runRemote(ctx context.Context, client kubernetes.Interface) error {
 		"--default-env-vars-type", config.DefaultEnvVarsType,
 		"--env-var-count", fmt.Sprintf("%d", config.EnvVarCount),
 		"--max-env-var-size", fmt.Sprintf("%d", config.MaxEnvVarSize),
+		"--job-class", config.JobClass,
+		"--run-id", config.RunID,
+		"--seed", fmt.Sprintf("%d", config.Seed),
+		"--naming", config.Naming,
+		"--namespaces", fmt.Sprintf("%d", config.NamespaceCount),
+		"--namespace-distribution", config.NamespaceDistribution,
+		"--zipf-exponent", fmt.Sprintf("%g", config.ZipfExponent),
+		// the simulator job pod can be rescheduled, it continues the run instead of starting over
+		"--resume",
 		"--namespace", config.Namespace,
+		"--metrics-addr", config.MetricsAddr,
+	}
+	for _, weight := range config.NamespaceWeights {
+		args = append(args, "--namespace-weights", fmt.Sprintf("%g", weight))
+	}
+	args = append(args,
 		"--no-gui",
 		"--verbose",
-	}
-	pterm.Info.Println("creating simulator job...")
+	)
+	stepStarted("remote", "creating simulator job...")
 	job := simulator.NewSimulatorJob(args)
 	_, err := client.BatchV1().Jobs(config.SimulatorNamespace).Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create simulator job: %v", err)
 	}
 
-	pterm.Info.Println("waiting for simulator job pod to become ready...")
+	stepStarted("remote", "waiting for simulator job pod to become ready...")
 	if err := k8s.WaitForJobPodsReady(ctx, client, config.SimulatorNamespace, job.Name, config.DefaultPollTimeout); err != nil {
 		return fmt.Errorf("failed to wait for simulator job pods to become ready: %v", err)
 	}
 
-	pterm.Info.Println("streaming simulator job pod logs...")
-	if err := k8s.WatchJobPodLogs(ctx, client, config.SimulatorNamespace, job.Name, os.Stdout); err != nil {
+	stepStarted("remote", "streaming simulator job pod logs...")
+	// keep stdout reserved for structured events in machine-readable output formats
+	logs := os.Stdout
+	if emitter.Enabled() {
+		logs = os.Stderr
+	}
+	if err := k8s.WatchJobPodLogs(ctx, client, config.SimulatorNamespace, job.Name, logs); err != nil {
 		return fmt.Errorf("failed to watch simulator job pod logs: %v", err)
 	}
 
 	return nil
 }
 
+// writeResults writes the summary into the results directory, together with the run configuration and the time series.
+// Results are only recorded for local runs, as remote runs execute the rate limiters in the simulator job.
+func writeResults(
+	manager *k8s.Manager,
+	scheduler *measurement.SchedulerTracker,
+	pods *measurement.PodTracker,
+	summary *results.Summary,
+) {
+	if config.Remote {
+		pterm.Warning.Println("results are not recorded for remote runs, skipping writing results")
+		return
+	}
+	s := startStep("results", fmt.Sprintf("writing results to %s...", config.ResultsDir))
+	writer, err := results.NewWriter(config.ResultsDir)
+	if err != nil {
+		s.Fail("failed to write results", err)
+		return
+	}
+	writes := []func() error{
+		func() error { return writer.WriteConfig(simulationConfig()) },
+		func() error { return writer.WriteSummary(summary) },
+		func() error { return writer.WriteTimeSeries(manager.TimeSeries()) },
+	}
+	if config.Measure {
+		writes = append(writes,
+			func() error { return writer.WriteSchedulerTimeSeries(scheduler.TimeSeries()) },
+			func() error { return writer.WritePodTimeSeries(pods.TimeSeries()) },
+			func() error { return writer.WriteJobs(summary.Jobs) },
+		)
+	}
+	if len(summary.Queues) > 0 {
+		writes = append(writes, func() error { return writer.WriteQueues(summary.Queues) })
+	}
+	if summary.Gangs != nil {
+		writes = append(writes, func() error { return writer.WriteGangs(summary.Gangs) })
+	}
+	if summary.Requests != nil {
+		writes = append(writes, func() error { return writer.WriteRequests(summary.Requests) })
+	}
+	if summary.APIServer != nil {
+		writes = append(writes, func() error { return writer.WriteJSON(results.APIServerFile, summary.APIServer) })
+	}
+	for _, write := range writes {
+		if err := write(); err != nil {
+			s.Fail("failed to write results", err)
+			return
+		}
+	}
+	s.Success(fmt.Sprintf("results written to %s", config.ResultsDir))
+}
+
+// waitForPodsToSettle keeps measuring after the simulation until no pod is pending and the pod phases stopped changing,
+// so the reports include the pods which are still being scheduled and started. It gives up after the settle timeout.
+func waitForPodsToSettle(ctx context.Context, pods *measurement.PodTracker) {
+	s := startStep("settle", "waiting for pods to settle...")
+	settleCtx, cancel := context.WithTimeout(ctx, config.SettleTimeout)
+	defer cancel()
+	err := pods.WaitForSettled(settleCtx, config.DefaultPollInterval, func(counts measurement.PhaseCounts) {
+		s.UpdateText(fmt.Sprintf("waiting for pods to settle %s...", counts.String()))
+	})
+	if err != nil {
+		s.Warning(fmt.Sprintf("pods did not settle within %s, reporting the pods measured so far", config.SettleTimeout))
+		return
+	}
+	s.Success("pods settled")
+}
+
+// waitForGangsToSettle waits until no gang is partially placed and no member pod is being bound, so the gang report
+// only counts the gangs which stayed partially placed. It gives up after the settle timeout.
+func waitForGangsToSettle(ctx context.Context, gangs *measurement.GangTracker) {
+	s := startStep("settle", "waiting for gang placement to settle...")
+	settleCtx, cancel := context.WithTimeout(ctx, config.SettleTimeout)
+	defer cancel()
+	if err := gangs.WaitForSettled(settleCtx, config.DefaultPollInterval); err != nil {
+		s.Warning(fmt.Sprintf("gang placement did not settle within %s, partially placed gangs stayed partial", config.SettleTimeout))
+		return
+	}
+	s.Success("gang placement settled")
+}
+
+// startAPIServerScraper scrapes the API server metrics once as the baseline and then periodically in the background.
+// It returns nil if the baseline could not be scraped.
+func startAPIServerScraper(ctx context.Context, client kubernetes.Interface) *metrics.APIServerScraper {
+	s := startStep("apiserver-metrics", "scraping api server metrics baseline...")
+	scraper := metrics.NewAPIServerScraper(client.Discovery().RESTClient(), simulatedResources...)
+	if err := scraper.Scrape(ctx); err != nil {
+		s.Warning(fmt.Sprintf("api server metrics will not be reported: %v", err))
+		return nil
+	}
+	go scraper.Run(ctx, config.ScrapeInterval)
+	s.Success("api server metrics baseline scraped")
+	return scraper
+}
+
+// finishAPIServerScraper scrapes the API server metrics a final time and returns the delta report.
+func finishAPIServerScraper(ctx context.Context, scraper *metrics.APIServerScraper) *metrics.APIServerReport {
+	s := startStep("apiserver-metrics", "scraping api server metrics...")
+	if err := scraper.Scrape(ctx); err != nil {
+		s.Warning(fmt.Sprintf("failed to scrape api server metrics: %v", err))
+	}
+	report, err := scraper.Report()
+	if err != nil {
+		s.Warning(fmt.Sprintf("api server metrics will not be reported: %v", err))
+		return nil
+	}
+	s.Success("api server metrics scraped")
+	return report
+}
+
+// simulatedResources are the resources created or deleted by a simulation.
+var simulatedResources = []string{"pods", "nodes", "jobs", "events", "namespaces"}
+
 func runLocal(ctx context.Context, manager *k8s.Manager) error {
 	pterm.Success.Println("kubernetes client initialized successfully!")
 
@@ -161,6 +710,11 @@ func runLocal(ctx context.Context, manager *k8s.Manager) error {
 		callback = func() { wg.Done() }
 		go printMetricsEvery(ctx, 1*time.Second, manager, callback)
 	}
+	if emitter.Enabled() {
+		emitCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go emitMetricsEvery(emitCtx, 1*time.Second, manager)
+	}
 	_ = manager.Start(ctx)
 	wg.Wait()
 
@@ -177,7 +731,47 @@ func NewRunCmd() *cobra.Command {
 	runCmd.Flags().DurationVar(&config.JobCreatorFrequency, "job-creator-frequency", config.JobCreatorFrequency, "frequency at which to create jobs")
 	runCmd.Flags().IntVar(&config.JobCreatorRequests, "job-creator-requests", config.JobCreatorRequests, "number of job creation requests to make in each iteration")
 	runCmd.Flags().IntVar(&config.JobCreatorLimit, "job-creator-limit", config.JobCreatorLimit, "maximum number of jobs to create")
+	runCmd.Flags().StringVar(&config.JobClass, "job-class", config.JobClass, "value of the job-class label used to group jobs in job metrics")
+	runCmd.Flags().StringVar(&config.ObjectTemplate, "object-template", config.ObjectTemplate, "YAML template of objects of any kind (e.g. custom resources) to create through the dynamic client")
+	runCmd.Flags().StringVar(&config.ObjectResource, "object-resource", config.ObjectResource, "resource of the created objects as <resource>.<version>.<group>, guessed from the kind of the template if empty")
+	runCmd.Flags().BoolVar(&config.ObjectClusterScoped, "object-cluster-scoped", config.ObjectClusterScoped, "create cluster-scoped objects instead of namespaced objects")
+	runCmd.Flags().DurationVar(&config.ObjectCreatorFrequency, "object-creator-frequency", config.ObjectCreatorFrequency, "frequency at which to create objects")
+	runCmd.Flags().IntVar(&config.ObjectCreatorRequests, "object-creator-requests", config.ObjectCreatorRequests, "number of object creation requests to make in each iteration")
+	runCmd.Flags().IntVar(&config.ObjectCreatorLimit, "object-creator-limit", config.ObjectCreatorLimit, "maximum number of objects to create")
+	runCmd.Flags().BoolVar(&config.Gangs, "gangs", config.Gangs, "create gangs, a PodGroup and its member pods which a gang scheduler places all-or-nothing")
+	runCmd.Flags().StringVar(&config.GangStyle, "gang-style", config.GangStyle, "gang scheduler whose PodGroups are created (coscheduling or volcano)")
+	runCmd.Flags().StringVar(&config.GangSchedulerName, "gang-scheduler-name", config.GangSchedulerName, "scheduler of the member pods, scheduler-plugins-scheduler for coscheduling and volcano for volcano if empty")
+	runCmd.Flags().IntSliceVar(&config.GangSizes, "gang-sizes", config.GangSizes, "numbers of member pods of gangs, every gang draws one of them")
+	runCmd.Flags().Float64SliceVar(&config.GangSizeWeights, "gang-size-weights", config.GangSizeWeights, "weights of the gang sizes, one per size, sizes are drawn uniformly if empty")
+	runCmd.Flags().DurationVar(&config.GangCreatorFrequency, "gang-creator-frequency", config.GangCreatorFrequency, "frequency at which to create gangs")
+	runCmd.Flags().IntVar(&config.GangCreatorRequests, "gang-creator-requests", config.GangCreatorRequests, "number of gangs to create in each iteration")
+	runCmd.Flags().IntVar(&config.GangCreatorLimit, "gang-creator-limit", config.GangCreatorLimit, "maximum number of gangs to create")
+	runCmd.Flags().BoolVar(&config.Kueue, "kueue", config.Kueue, "create suspended jobs submitted to kueue local queues of a cluster queue whose quota matches the fake node pool")
+	runCmd.Flags().StringVar(&config.KueueResourceFlavor, "kueue-resource-flavor", config.KueueResourceFlavor, "name of the kueue resource flavor which matches the fake nodes")
+	runCmd.Flags().StringVar(&config.KueueClusterQueue, "kueue-cluster-queue", config.KueueClusterQueue, "name of the kueue cluster queue which admits the jobs")
+	runCmd.Flags().StringVar(&config.KueueLocalQueue, "kueue-local-queue", config.KueueLocalQueue, "name of the kueue local queue created in every namespace in which jobs are created")
+	runCmd.Flags().StringVar(&config.Workloads, "workloads", config.Workloads, "YAML file defining tenants which create jobs or pods in their own namespaces with their own template, arrival process and rate")
+	runCmd.Flags().Int64Var(&config.Seed, "seed", config.Seed, "seed of all randomness (names, env var payloads, random sizes) to reproduce a run, random if 0")
+	runCmd.Flags().StringVar(&config.Naming, "naming", config.Naming, "naming scheme of created resources, random (<prefix>-<random>) or sequential (<prefix>-<run-id>-<seq>)")
+	runCmd.Flags().BoolVar(&config.Resume, "resume", config.Resume, "continue an interrupted run with the same --run-id, creating only the resources remaining to the limits")
+	runCmd.Flags().StringVar(&config.RunID, "run-id", config.RunID, "run ID stamped on every created node, pod and job, a new one is generated if empty, derived from --seed if it is set")
 	runCmd.Flags().StringVarP(&config.Namespace, "namespace", "n", config.Namespace, "namespace in which to create simulation resources")
+	runCmd.Flags().IntVar(&config.NamespaceCount, "namespaces", config.NamespaceCount, "number of namespaces, named <namespace>-<i> and created on demand, across which to spread pods and jobs")
+	runCmd.Flags().StringVar(&config.NamespaceDistribution, "namespace-distribution", config.NamespaceDistribution, "distribution of pods and jobs across --namespaces, round-robin, weighted or zipf")
+	runCmd.Flags().Float64SliceVar(&config.NamespaceWeights, "namespace-weights", config.NamespaceWeights, "weights of the namespaces of the weighted distribution, one per namespace")
+	runCmd.Flags().Float64Var(&config.ZipfExponent, "zipf-exponent", config.ZipfExponent, "exponent of the zipf distribution, higher values concentrate more pods and jobs in the first namespaces")
+	runCmd.Flags().BoolVar(&config.Measure, "measure", config.Measure, "measure scheduler throughput, pod lifecycle and job metrics with informers, which requires list & watch permissions on pods, events and jobs")
+	runCmd.Flags().DurationVar(&config.SettleTimeout, "settle-timeout", config.SettleTimeout, "maximum time to keep measuring after the simulation until the pods and the gang placement settle")
+	runCmd.Flags().BoolVar(&config.Preflight, "preflight", config.Preflight, "check quotas, limit ranges & admission before creating resources")
+	runCmd.Flags().StringVar(&config.MetricsAddr, "metrics-addr", config.MetricsAddr, "address on which to serve prometheus metrics (e.g. :9090), disabled if empty")
+	runCmd.Flags().BoolVar(&config.ScrapeAPIServerMetrics, "scrape-apiserver-metrics", config.ScrapeAPIServerMetrics, "scrape api server metrics before, during and after the simulation and report the delta")
+	runCmd.Flags().DurationVar(&config.ScrapeInterval, "scrape-interval", config.ScrapeInterval, "interval at which api server metrics are scraped")
+	runCmd.Flags().BoolVar(&config.TraceRequests, "trace-requests", config.TraceRequests, "record latency, status codes, sizes and client-side rate limiter wait of every API request")
+	runCmd.Flags().StringVar(&config.ResultsDir, "results-dir", config.ResultsDir, "directory in which to write the run configuration, summary and metrics time series, disabled if empty")
+	runCmd.Flags().StringArrayVar(&config.Assertions, "assert", config.Assertions, "assertion evaluated against the final metrics which fails the run if not satisfied (e.g. 'pod.create.p99 < 500ms', 'pod.failed == 0')")
+	runCmd.Flags().BoolVar(&config.Offline, "offline", config.Offline, "run against an in-memory fake clientset instead of a cluster")
+	runCmd.Flags().DurationVar(&config.OfflineLatency, "offline-latency", config.OfflineLatency, "mean latency injected into create, update and delete requests in offline mode")
+	runCmd.Flags().Float64Var(&config.OfflineErrorRate, "offline-error-rate", config.OfflineErrorRate, "fraction of create, update and delete requests which fail in offline mode (0-1)")
 	runCmd.Flags().BoolVarP(&config.Remote, "remote", "r", config.Remote, "run the simulator in a Kubernetes cluster")
 	runCmd.Flags().IntVar(&config.PodSpecSize, "pod-spec-size", config.PodSpecSize, "size of the pod spec in bytes")
 	runCmd.Flags().BoolVar(&config.RandomEnvVars, "random-env-vars", config.RandomEnvVars, "use random env vars")
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspect simulation runs found in the cluster",
+	Long: `This command groups subcommands which inspect simulation runs.
+Every run stamps its run ID as the run-id label on the nodes, pods and jobs it creates,
+which can be passed to clean --run-id and watch --run-id to act only on the objects of that run.`,
+}
+
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List simulation runs found in the cluster with their object counts",
+	Long: `This command lists the runs whose nodes, pods or jobs still exist in the cluster,
+with the number of objects of every run and the creation time of the oldest and newest object.
+Pods and jobs are only counted in the configured namespace.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init section
+		blip()
+		pterm.DefaultSection.Println("init")
+
+		client := newKubernetesClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
+
+		// runs section
+		blip()
+		pterm.DefaultSection.Println("runs")
+
+		s := startStep("runs", "listing runs...")
+		runs, err := k8s.ListRuns(cmd.Context(), client, config.Namespace)
+		if err != nil {
+			s.Fail("failed to list runs", err)
+			exit(exitCodeFatal)
+		}
+		s.Success(fmt.Sprintf("found %d runs", len(runs)))
+		if len(runs) > 0 {
+			printRuns(runs)
+		}
+		emitter.SetSummary(map[string]any{"namespace": config.Namespace, "runs": runs})
+	},
+}
+
+func NewRunsCmd() *cobra.Command {
+	runsListCmd.Flags().StringVarP(&config.Namespace, "namespace", "n", config.Namespace, "namespace in which to look for pods and jobs")
+	runsCmd.AddCommand(runsListCmd)
+
+	return runsCmd
+}
@@ -4,13 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pterm/pterm"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
+	"github.com/dejanzele/batch-simulator/internal/assertion"
+	"github.com/dejanzele/batch-simulator/internal/compare"
 	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/metrics"
 	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/dejanzele/batch-simulator/internal/simulator"
+	"github.com/dejanzele/batch-simulator/internal/stats"
 )
 
 // printKWOKConfig prints the configuration for k8s and kwok
@@ -38,6 +48,12 @@ func printSimulationConfig() {
 		WithBulletStyle(pterm.NewStyle(pterm.FgLightCyan)).
 		WithTextStyle(pterm.NewStyle(pterm.FgLightCyan)).
 		WithItems([]pterm.BulletListItem{
+			{Level: 1, Text: "run id                 = " + config.RunID},
+			{Level: 1, Text: "seed                   = " + fmt.Sprintf("%d", config.Seed)},
+			{Level: 1, Text: "naming                 = " + config.Naming},
+			{Level: 1, Text: "resume                 = " + fmt.Sprintf("%t", config.Resume)},
+			{Level: 1, Text: "namespaces             = " + fmt.Sprintf("%d (%s)", config.NamespaceCount, config.NamespaceDistribution)},
+			{Level: 1, Text: "workloads              = " + config.Workloads},
 			{Level: 1, Text: "node creator frequency = " + config.NodeCreatorFrequency.String()},
 			{Level: 1, Text: "node creator requests  = " + fmt.Sprintf("%d", config.NodeCreatorRequests)},
 			{Level: 1, Text: "node creator limit     = " + fmt.Sprintf("%d", config.NodeCreatorLimit)},
@@ -47,6 +63,15 @@ func printSimulationConfig() {
 			{Level: 1, Text: "job creator frequency  = " + config.JobCreatorFrequency.String()},
 			{Level: 1, Text: "job creator requests   = " + fmt.Sprintf("%d", config.JobCreatorRequests)},
 			{Level: 1, Text: "job creator limit      = " + fmt.Sprintf("%d", config.JobCreatorLimit)},
+			{Level: 1, Text: "job class              = " + config.JobClass},
+			{Level: 1, Text: "object template        = " + config.ObjectTemplate},
+			{Level: 1, Text: "object frequency       = " + config.ObjectCreatorFrequency.String()},
+			{Level: 1, Text: "object requests        = " + fmt.Sprintf("%d", config.ObjectCreatorRequests)},
+			{Level: 1, Text: "object limit           = " + fmt.Sprintf("%d", config.ObjectCreatorLimit)},
+			{Level: 1, Text: "kueue                  = " + fmt.Sprintf("%t", config.Kueue)},
+			{Level: 1, Text: "gangs                  = " + fmt.Sprintf("%t (%s, sizes %v)", config.Gangs, config.GangStyle, config.GangSizes)},
+			{Level: 1, Text: "gang limit             = " + fmt.Sprintf("%d", config.GangCreatorLimit)},
+			{Level: 1, Text: "measure                = " + fmt.Sprintf("%t (settle timeout %s)", config.Measure, config.SettleTimeout)},
 		}).Render()
 }
 
@@ -74,31 +99,45 @@ func printMetricsEvery(ctx context.Context, interval time.Duration, manager *k8s
 		WithCenter(true).Start()
 	defer func() { _ = area.Stop() }()
 	printMetrics(area, oldNodeCreationMetrics, oldPodCreationMetrics, oldJobCreationMetrics)
+	resumedNodes, resumedPods, resumedJobs := manager.Resumed()
 	var nodeBar, podBar, jobBar *pterm.ProgressbarPrinter
-	if config.NodeCreatorLimit > 0 {
+	if config.NodeCreatorLimit > resumedNodes {
 		nodeBar, _ = pterm.
 			DefaultProgressbar.
 			WithWriter(multi.NewWriter()).
-			WithTotal(config.NodeCreatorLimit).
+			WithTotal(config.NodeCreatorLimit - resumedNodes).
 			WithTitle("Node Creation Progress").
 			Start()
 	}
-	if config.PodCreatorLimit > 0 {
+	if config.PodCreatorLimit > resumedPods {
 		podBar, _ = pterm.
 			DefaultProgressbar.
 			WithWriter(multi.NewWriter()).
-			WithTotal(config.PodCreatorLimit).
+			WithTotal(config.PodCreatorLimit - resumedPods).
 			WithTitle("Pod Creation Progress").
 			Start()
 	}
-	if config.JobCreatorLimit > 0 {
+	if config.JobCreatorLimit > resumedJobs {
 		jobBar, _ = pterm.
 			DefaultProgressbar.
 			WithWriter(multi.NewWriter()).
-			WithTotal(config.JobCreatorLimit).
+			WithTotal(config.JobCreatorLimit - resumedJobs).
 			WithTitle("Job Creation Progress").
 			Start()
 	}
+	workloadBars := make(map[string]*pterm.ProgressbarPrinter)
+	oldWorkloadMetrics := make(map[string]ratelimiter.Metrics)
+	for _, workload := range manager.Workloads() {
+		oldWorkloadMetrics[workload.Name] = workload.RateLimiter.Metrics()
+		if workload.RateLimiter.Limit() > 0 {
+			workloadBars[workload.Name], _ = pterm.
+				DefaultProgressbar.
+				WithWriter(multi.NewWriter()).
+				WithTotal(workload.RateLimiter.Limit()).
+				WithTitle(fmt.Sprintf("Workload %s Progress", workload.Name)).
+				Start()
+		}
+	}
 
 	_, _ = multi.Start()
 	defer func() { _, _ = multi.Stop() }()
@@ -120,7 +159,15 @@ func printMetricsEvery(ctx context.Context, interval time.Duration, manager *k8s
 			printMetrics(area, nodeCreationMetrics, podCreationMetrics, jobCreationMetrics)
 			updateProgressBars(nodeBar, podBar, jobBar, nodeCreationMetricsDelta, podCreationMetricsDelta, jobCreationMetricsDelta)
 			oldNodeCreationMetrics, oldPodCreationMetrics, oldJobCreationMetrics = nodeCreationMetrics, podCreationMetrics, jobCreationMetrics
-			if finished(nodeCreationMetrics, podCreationMetrics, jobCreationMetrics) {
+			for _, workload := range manager.Workloads() {
+				workloadMetrics := workload.RateLimiter.Metrics()
+				if bar, ok := workloadBars[workload.Name]; ok {
+					delta := calculateDelta(oldWorkloadMetrics[workload.Name], workloadMetrics)
+					bar.Add(delta.Succeeded + delta.Failed)
+				}
+				oldWorkloadMetrics[workload.Name] = workloadMetrics
+			}
+			if finished(manager) {
 				if onFinished != nil {
 					onFinished()
 				}
@@ -130,11 +177,146 @@ func printMetricsEvery(ctx context.Context, interval time.Duration, manager *k8s
 	}
 }
 
-// finished returns true if the node and pod creation metrics have reached their limits.
-func finished(nodeCreationMetrics, podCreationMetrics, jobCreationMetrics ratelimiter.Metrics) bool {
-	return nodeCreationMetrics.Executed == config.NodeCreatorLimit &&
-		podCreationMetrics.Executed == config.PodCreatorLimit &&
-		jobCreationMetrics.Executed == config.JobCreatorLimit
+// emitMetricsEvery emits a metrics snapshot every interval until the context is cancelled.
+func emitMetricsEvery(ctx context.Context, interval time.Duration, manager *k8s.Manager) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emitter.Metrics(metricsSnapshot(manager))
+		}
+	}
+}
+
+// trackDeleteProgress shows the deletion progress of the resource in the step every second until the returned function is called,
+// stop must be called before the step is finished.
+func trackDeleteProgress(s *step, manager *k8s.Manager, resource string) (stop func()) {
+	ticker := time.NewTicker(1 * time.Second)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				progress, ok := manager.DeleteProgress()[resource]
+				if !ok {
+					continue
+				}
+				s.UpdateText(fmt.Sprintf("cleaning up %s... %d/%d deleted, %d failed", resource, progress.Deleted, progress.Total, progress.Failed))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// emitDeleteProgressEvery emits a deletion progress snapshot every interval until the context is cancelled.
+func emitDeleteProgressEvery(ctx context.Context, interval time.Duration, manager *k8s.Manager) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emitter.Metrics(manager.DeleteProgress())
+		}
+	}
+}
+
+// metricsSnapshot returns the current creation metrics keyed by resource, and by executor identifier for workloads.
+func metricsSnapshot(manager *k8s.Manager) map[string]ratelimiter.Metrics {
+	nodeCreationMetrics, podCreationMetrics, jobCreationMetrics := manager.Metrics()
+	snapshot := map[string]ratelimiter.Metrics{
+		"nodes": nodeCreationMetrics,
+		"pods":  podCreationMetrics,
+		"jobs":  jobCreationMetrics,
+	}
+	for _, workload := range manager.Workloads() {
+		snapshot[workload.RateLimiter.Identifier()] = workload.RateLimiter.Metrics()
+	}
+	return snapshot
+}
+
+// simulationConfig returns the simulation configuration as a map which can be serialized.
+func simulationConfig() map[string]any {
+	return map[string]any{
+		"runId":                  config.RunID,
+		"seed":                   config.Seed,
+		"naming":                 config.Naming,
+		"resume":                 config.Resume,
+		"namespace":              config.Namespace,
+		"namespaceCount":         config.NamespaceCount,
+		"namespaceDistribution":  config.NamespaceDistribution,
+		"namespaceWeights":       config.NamespaceWeights,
+		"zipfExponent":           config.ZipfExponent,
+		"workloads":              config.Workloads,
+		"simulatorNamespace":     config.SimulatorNamespace,
+		"remote":                 config.Remote,
+		"offline":                config.Offline,
+		"qps":                    config.QPS,
+		"burst":                  config.Burst,
+		"nodeCreatorFrequency":   config.NodeCreatorFrequency.String(),
+		"nodeCreatorRequests":    config.NodeCreatorRequests,
+		"nodeCreatorLimit":       config.NodeCreatorLimit,
+		"podCreatorFrequency":    config.PodCreatorFrequency.String(),
+		"podCreatorRequests":     config.PodCreatorRequests,
+		"podCreatorLimit":        config.PodCreatorLimit,
+		"jobCreatorFrequency":    config.JobCreatorFrequency.String(),
+		"jobCreatorRequests":     config.JobCreatorRequests,
+		"jobCreatorLimit":        config.JobCreatorLimit,
+		"jobClass":               config.JobClass,
+		"objectTemplate":         config.ObjectTemplate,
+		"objectResource":         config.ObjectResource,
+		"objectClusterScoped":    config.ObjectClusterScoped,
+		"objectCreatorFrequency": config.ObjectCreatorFrequency.String(),
+		"objectCreatorRequests":  config.ObjectCreatorRequests,
+		"objectCreatorLimit":     config.ObjectCreatorLimit,
+		"kueue":                  config.Kueue,
+		"kueueResourceFlavor":    config.KueueResourceFlavor,
+		"kueueClusterQueue":      config.KueueClusterQueue,
+		"kueueLocalQueue":        config.KueueLocalQueue,
+		"gangs":                  config.Gangs,
+		"gangStyle":              config.GangStyle,
+		"gangSchedulerName":      config.GangSchedulerName,
+		"gangSizes":              config.GangSizes,
+		"gangSizeWeights":        config.GangSizeWeights,
+		"gangCreatorFrequency":   config.GangCreatorFrequency.String(),
+		"gangCreatorRequests":    config.GangCreatorRequests,
+		"gangCreatorLimit":       config.GangCreatorLimit,
+		"measure":                config.Measure,
+		"settleTimeout":          config.SettleTimeout.String(),
+		"randomEnvVars":          config.RandomEnvVars,
+		"defaultEnvVarsType":     config.DefaultEnvVarsType,
+		"envVarCount":            config.EnvVarCount,
+		"maxEnvVarSize":          config.MaxEnvVarSize,
+	}
+}
+
+// finished returns true if the node, pod and job creators and all workloads have reached their limits,
+// including the resources which were created by the resumed run.
+func finished(manager *k8s.Manager) bool {
+	nodeCreationMetrics, podCreationMetrics, jobCreationMetrics := manager.Metrics()
+	resumedNodes, resumedPods, resumedJobs := manager.Resumed()
+	for _, workload := range manager.Workloads() {
+		if workload.RateLimiter.Metrics().Executed+workload.RateLimiter.Resumed() < workload.RateLimiter.Limit() {
+			return false
+		}
+	}
+	return nodeCreationMetrics.Executed+resumedNodes >= config.NodeCreatorLimit &&
+		podCreationMetrics.Executed+resumedPods >= config.PodCreatorLimit &&
+		jobCreationMetrics.Executed+resumedJobs >= config.JobCreatorLimit
 }
 
 // calculateMetricsDelta calculates the delta between the old and new node and pod creation metrics.
@@ -187,6 +369,337 @@ func formatMetric(metric int) string {
 	return pterm.Sprintf("%d", metric)
 }
 
+// printRuns prints the runs and their object counts in a table.
+func printRuns(runs []k8s.Run) {
+	data := pterm.TableData{{"Run ID", "Nodes", "Pods", "Jobs", "First Created", "Last Created"}}
+	for _, run := range runs {
+		data = append(data, []string{
+			run.ID,
+			formatMetric(run.Nodes),
+			formatMetric(run.Pods),
+			formatMetric(run.Jobs),
+			run.FirstCreatedAt.Format(time.RFC3339),
+			run.LastCreatedAt.Format(time.RFC3339),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// maxForceCleanReportRows is the maximum number of removed resources printed by printForceCleanReport.
+const maxForceCleanReportRows = 100
+
+// printForceCleanReport prints the resources removed by a forced clean in a table.
+func printForceCleanReport(report *k8s.ForceCleanReport) {
+	data := pterm.TableData{{"Reason", "Kind", "Namespace/Name"}}
+	for _, key := range report.TerminatingPods {
+		data = append(data, []string{"stuck terminating", "Pod", key})
+	}
+	for _, key := range report.OrphanedPods {
+		data = append(data, []string{"bound to missing node", "Pod", key})
+	}
+	for _, key := range report.OrphanedJobs {
+		data = append(data, []string{"no pods", "Job", key})
+	}
+	for _, key := range report.SimulatorJobs {
+		data = append(data, []string{"leftover simulator job", "Job", key})
+	}
+	// the header is not counted
+	if len(data) > maxForceCleanReportRows+1 {
+		more := len(data) - maxForceCleanReportRows - 1
+		data = append(data[:maxForceCleanReportRows+1], []string{fmt.Sprintf("... and %d more", more), "", ""})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printPreflightFindings prints the preflight findings in a table.
+func printPreflightFindings(findings []simulator.Finding) {
+	data := pterm.TableData{{"Check", "Status", "Message"}}
+	for _, finding := range findings {
+		var status string
+		switch finding.Severity {
+		case simulator.SeverityFatal:
+			status = pterm.Red(string(finding.Severity))
+		case simulator.SeverityWarning:
+			status = pterm.Yellow(string(finding.Severity))
+		default:
+			status = pterm.Green(string(finding.Severity))
+		}
+		data = append(data, []string{finding.Check, status, finding.Message})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printPodReport prints the pod phase counts and lifecycle latency percentiles.
+func printPodReport(report measurement.PodReport) {
+	pterm.Info.Printf("pods %s\n", report.Counts.String())
+	data := pterm.TableData{{"Latency", "Count", "p50", "p90", "p99", "Max"}}
+	for _, row := range []struct {
+		name    string
+		summary stats.Summary
+	}{
+		{"scheduling", report.SchedulingLatency},
+		{"startup", report.StartupLatency},
+		{"end-to-end", report.EndToEndLatency},
+	} {
+		data = append(data, []string{
+			row.name,
+			fmt.Sprintf("%d", row.summary.Count),
+			formatSeconds(row.summary.P50),
+			formatSeconds(row.summary.P90),
+			formatSeconds(row.summary.P99),
+			formatSeconds(row.summary.Max),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printSchedulerReport prints the scheduler throughput and warns if the scheduler was saturated.
+func printSchedulerReport(report measurement.SchedulerReport) {
+	pterm.Info.Printf(
+		"scheduler bound %d pods (mean %.2f pods/s, peak %.2f pods/s), %d failed scheduling attempts, peak pending %d\n",
+		report.Bound, report.Throughput.Mean, report.Throughput.Max, report.FailedScheduling, report.PeakPending,
+	)
+	if report.Saturated {
+		pterm.Warning.Printf("scheduler saturated at %s, the pending queue kept growing\n", report.SaturatedAt.String())
+	}
+}
+
+// printJobsReport prints the job metrics of every job class.
+func printJobsReport(report map[string]measurement.JobClassReport) {
+	if len(report) == 0 {
+		return
+	}
+	data := pterm.TableData{{"Job Class", "Jobs", "Succeeded", "Failed", "Queue Wait p50", "Queue Wait p99", "Completion p50", "Completion p99", "Makespan"}}
+	for _, class := range measurement.Classes(report) {
+		r := report[class]
+		data = append(data, []string{
+			class,
+			fmt.Sprintf("%d", r.Jobs),
+			fmt.Sprintf("%d", r.Succeeded),
+			fmt.Sprintf("%d", r.Failed),
+			formatSeconds(r.QueueWait.P50),
+			formatSeconds(r.QueueWait.P99),
+			formatSeconds(r.CompletionTime.P50),
+			formatSeconds(r.CompletionTime.P99),
+			formatSeconds(r.Makespan),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printQueueReport prints the admitted jobs, admission latency and admission throughput of every Kueue queue,
+// nothing is printed if no jobs were submitted to queues.
+func printQueueReport(report map[string]measurement.QueueReport) {
+	if len(report) == 0 {
+		return
+	}
+	data := pterm.TableData{{"Queue", "Jobs", "Admitted", "Admission p50", "Admission p99", "Throughput"}}
+	for _, queue := range sortedKeys(report) {
+		r := report[queue]
+		data = append(data, []string{
+			queue,
+			fmt.Sprintf("%d", r.Jobs),
+			fmt.Sprintf("%d", r.Admitted),
+			formatSeconds(r.AdmissionLatency.P50),
+			formatSeconds(r.AdmissionLatency.P99),
+			fmt.Sprintf("%.2f/s", r.Throughput),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printGangReport prints the placement of the gangs of every size and of all gangs.
+func printGangReport(report *measurement.GangReport) {
+	data := pterm.TableData{{"Gang Size", "Gangs", "Fully Placed", "Partially Placed", "Unplaced", "Full Placement p50", "Full Placement p99"}}
+	row := func(size string, r measurement.GangReport) []string {
+		return []string{
+			size,
+			fmt.Sprintf("%d", r.Gangs),
+			fmt.Sprintf("%d", r.FullyPlaced),
+			fmt.Sprintf("%d", r.PartiallyPlaced),
+			fmt.Sprintf("%d", r.Unplaced),
+			formatSeconds(r.TimeToFullPlacement.P50),
+			formatSeconds(r.TimeToFullPlacement.P99),
+		}
+	}
+	for _, size := range measurement.GangSizes(*report) {
+		data = append(data, row(fmt.Sprintf("%d", size), report.Sizes[size]))
+	}
+	data = append(data, row("all", *report))
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printNamespaceMetrics prints the created, failed and total objects per namespace of every executor.
+func printNamespaceMetrics(metrics map[string]map[string]ratelimiter.Metrics) {
+	data := pterm.TableData{{"Executor", "Namespace", "Executed", "Succeeded", "Failed"}}
+	for _, identifier := range sortedKeys(metrics) {
+		for _, namespace := range sortedKeys(metrics[identifier]) {
+			m := metrics[identifier][namespace]
+			data = append(data, []string{
+				identifier,
+				namespace,
+				fmt.Sprintf("%d", m.Executed),
+				fmt.Sprintf("%d", m.Succeeded),
+				fmt.Sprintf("%d", m.Failed),
+			})
+		}
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printWorkloads prints the created and failed objects, the configured & achieved rate and the latency of every workload,
+// so tenants which were starved by the API server or the scheduler stand out.
+func printWorkloads(workloads []k8s.Workload) {
+	data := pterm.TableData{{"Workload", "Executed", "Succeeded", "Failed", "Configured Rate", "Achieved Rate", "Latency p50", "Latency p99"}}
+	for _, workload := range workloads {
+		summary := workload.RateLimiter.Summary()
+		data = append(data, []string{
+			workload.Name,
+			fmt.Sprintf("%d", summary.Executed),
+			fmt.Sprintf("%d", summary.Succeeded),
+			fmt.Sprintf("%d", summary.Failed),
+			fmt.Sprintf("%.2f/s", summary.Rate.Configured),
+			fmt.Sprintf("%.2f/s", summary.Rate.Achieved),
+			formatSeconds(summary.Latency.P50),
+			formatSeconds(summary.Latency.P99),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// sortedKeys returns the keys of the map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printRequestReport prints the traced API requests and separates server latency from client-side throttling.
+func printRequestReport(report *k8s.RequestReport) {
+	data := pterm.TableData{{"Verb", "Resource", "Count", "Errors", "Latency p50", "Latency p99", "Throttle Wait p99", "Throttled"}}
+	for _, r := range report.Requests {
+		data = append(data, []string{
+			r.Verb,
+			r.Resource,
+			fmt.Sprintf("%d", r.Count),
+			fmt.Sprintf("%d", r.Errors),
+			formatSeconds(r.Latency.P50),
+			formatSeconds(r.Latency.P99),
+			formatSeconds(r.RateLimiterWait.P99),
+			fmt.Sprintf("%d", r.Throttled),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	if report.Throttled > 0 {
+		pterm.Warning.Printf(
+			"%d requests were throttled by client-side rate limits (p99 wait %s), consider increasing the client QPS and burst\n",
+			report.Throttled, formatSeconds(report.RateLimiterWait.P99),
+		)
+	}
+}
+
+// printAPIServerReport prints the API server request latencies, stored objects and rejected requests.
+func printAPIServerReport(report *metrics.APIServerReport) {
+	data := pterm.TableData{{"Verb", "Resource", "Requests", "Mean", "p50", "p99"}}
+	for _, d := range report.RequestDuration {
+		resource := d.Labels["resource"]
+		if subresource := d.Labels["subresource"]; subresource != "" {
+			resource += "/" + subresource
+		}
+		data = append(data, []string{
+			d.Labels["verb"],
+			resource,
+			fmt.Sprintf("%.0f", d.Count),
+			formatSeconds(d.Mean),
+			formatSeconds(d.P50),
+			formatSeconds(d.P99),
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	for _, d := range report.StorageObjects {
+		pterm.Info.Printf("api server stores %.0f %s (%+.0f)\n", d.After, d.Labels["resource"], d.Delta)
+	}
+	for _, d := range report.Rejections {
+		pterm.Warning.Printf(
+			"api priority and fairness rejected %.0f requests in priority level %s (%s)\n",
+			d.Delta, d.Labels["priority_level"], d.Labels["reason"],
+		)
+	}
+}
+
+// printAssertions prints the expected and actual value of every evaluated assertion.
+func printAssertions(evaluated []assertion.Result) {
+	data := pterm.TableData{{"Assertion", "Metric", "Actual", "Status"}}
+	for _, r := range evaluated {
+		actual := r.Message
+		if r.Actual != nil && r.Message == "" {
+			actual = formatMetricValue(*r.Actual, r.Unit)
+		}
+		status := pterm.Green("pass")
+		if !r.Passed {
+			status = pterm.Red("fail")
+		}
+		data = append(data, []string{r.Expression, r.Resolved, actual, status})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+// printComparison prints the change of every compared metric and the metrics present in only one run.
+func printComparison(result *compare.Result) {
+	data := pterm.TableData{{"Metric", "Base", "Current", "Change", "Threshold", "Status"}}
+	for _, change := range result.Changes {
+		percent := "n/a"
+		if change.Percent != nil {
+			percent = fmt.Sprintf("%+.1f%%", *change.Percent)
+		}
+		var status string
+		switch change.Status {
+		case compare.StatusRegression:
+			status = pterm.Red(string(change.Status))
+		case compare.StatusWorse:
+			status = pterm.Yellow(string(change.Status))
+		case compare.StatusImproved:
+			status = pterm.Green(string(change.Status))
+		default:
+			status = string(change.Status)
+		}
+		data = append(data, []string{
+			change.Name,
+			formatMetricValue(change.Base, change.Unit),
+			formatMetricValue(change.Current, change.Unit),
+			percent,
+			fmt.Sprintf("%.1f%%", change.Threshold),
+			status,
+		})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	if len(result.Missing) > 0 {
+		pterm.Warning.Printf("metrics present in only one run: %s\n", strings.Join(result.Missing, ", "))
+	}
+}
+
+// formatMetricValue formats a metric value in its unit, latencies are rounded to microseconds.
+func formatMetricValue(value float64, unit results.Unit) string {
+	switch unit {
+	case results.UnitSeconds:
+		return time.Duration(value * float64(time.Second)).Round(time.Microsecond).String()
+	case results.UnitPerSecond:
+		return fmt.Sprintf("%.2f/s", value)
+	case results.UnitRatio:
+		return fmt.Sprintf("%.2f%%", value*100)
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+// formatSeconds formats seconds as a duration rounded to milliseconds.
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
 // blip is a helper function which is used to slow down the output.
 func blip() {
 	time.Sleep(200 * time.Millisecond)
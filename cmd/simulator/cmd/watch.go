@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"context"
-	"log/slog"
-	"os"
 	"time"
 
 	"github.com/pterm/pterm"
@@ -13,18 +11,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/output"
 	"github.com/dejanzele/batch-simulator/internal/simulator"
 	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
-	"github.com/dejanzele/batch-simulator/internal/k8s"
 )
 
 var watchCmd = &cobra.Command{
 	Use:   "watch",
 	Short: "Watch simulation until all pods complete",
-	Long:  `This command watches the simulation until all pods complete.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `This command watches the simulation until all pods complete.
+It uses a pod informer to timestamp the lifecycle transitions of every fake pod and reports the distributions
+of scheduling latency (created to scheduled), startup latency (created to running) and end-to-end latency
+(created to succeeded or failed), together with live pod counts by phase.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Print config section
 		blip()
@@ -34,18 +36,8 @@ var watchCmd = &cobra.Command{
 		blip()
 		pterm.DefaultSection.Println("init")
 
-		pterm.Info.Println("initializing kubernetes clients...")
-		cfg := getKubernetesConfig()
-		client, err := k8s.NewClient(&config.Kubeconfig, cfg)
-		if err != nil {
-			pterm.Error.Printf("failed to initialize k8s client: %v", err)
-			os.Exit(1)
-		}
-		pterm.Success.Println("kubernetes client initialized successfully!")
-
-		pterm.Info.Println("initializing kubernetes resource manager...")
-		manager := k8s.NewManager(client, &k8s.ManagerConfig{Namespace: config.Namespace})
-		pterm.Success.Println("kubernetes resource manager initialized successfully!")
+		client := newKubernetesClient()
+		stepSucceeded("init", "kubernetes client initialized successfully!")
 
 		// watch section
 		blip()
@@ -58,8 +50,8 @@ var watchCmd = &cobra.Command{
 
 		simulationJob, err := getSimulatorJob(cmd.Context(), client, simulatorJobName)
 		if err != nil {
-			pterm.Error.Printf("failed to get simulator job: %v\n", err)
-			os.Exit(1)
+			stepFailed("watch", "failed to get simulator job", err)
+			exit(exitCodeFatal)
 		}
 
 		now := time.Now()
@@ -73,18 +65,68 @@ var watchCmd = &cobra.Command{
 			now = simulationJob.CreationTimestamp.Time
 		}
 
-		pterm.Info.Println("waiting for simulation pods to complete...")
-		if err := manager.WaitForPodsToComplete(cmd.Context(), resources.LabelSelectorFakePod, slog.Default()); err != nil {
-			pterm.Error.Printf("failed to wait for pods to complete: %v", err)
+		if config.RunID != "" {
+			pterm.Info.Printf("watching run %s\n", config.RunID)
+		}
+
+		stepStarted("watch", "starting pod informer...")
+		podSelector := resources.RunSelector(resources.LabelSelectorFakePod, config.RunID)
+		tracker := measurement.NewPodTracker(client, config.Namespace, podSelector)
+		if err := tracker.Start(cmd.Context()); err != nil {
+			stepFailed("watch", "failed to start pod informer", err)
+			exit(exitCodeFatal)
+		}
+
+		scheduler := measurement.NewSchedulerTracker(client, config.Namespace, podSelector)
+		if err := scheduler.Start(cmd.Context(), 1*time.Second); err != nil {
+			stepFailed("watch", "failed to start scheduler throughput measurement", err)
+			exit(exitCodeFatal)
+		}
+
+		jobs := newJobTracker(client)
+		if err := jobs.Start(cmd.Context()); err != nil {
+			stepFailed("watch", "failed to start job metrics measurement", err)
+			exit(exitCodeFatal)
+		}
+
+		stepStarted("watch", "waiting for simulation pods to complete...")
+		waitCtx, cancel := context.WithTimeout(cmd.Context(), config.WaitTimeout)
+		waitErr := tracker.WaitForCompletion(waitCtx, config.DefaultPollInterval, func(counts measurement.PhaseCounts) {
+			pterm.Info.Printf("pods %s\n", counts.String())
+			emitter.Metrics(counts)
+		})
+		cancel()
+		if waitErr != nil {
+			stepFailed("watch", "failed to wait for pods to complete", waitErr)
+		} else {
+			emitter.Step(output.EventStepSucceeded, "watch", "all simulation pods completed", nil, nil)
 		}
 
 		// status section
 		blip()
 		pterm.DefaultSection.Println("status")
 		end := time.Now()
+		report := tracker.Report()
+		schedulerReport := scheduler.Report()
+		jobsReport := jobs.Report()
 		pterm.Info.Printf("simulation watch started at %s\n", now.String())
 		pterm.Info.Printf("simulation watch ended at %s\n", end.String())
 		pterm.Info.Printf("simulation watch duration: %s\n", time.Since(now).String())
+		printPodReport(report)
+		printSchedulerReport(schedulerReport)
+		printJobsReport(jobsReport)
+		emitter.SetSummary(map[string]any{
+			"runId":     config.RunID,
+			"start":     now,
+			"end":       end,
+			"duration":  end.Sub(now).String(),
+			"pods":      report,
+			"scheduler": schedulerReport,
+			"jobs":      jobsReport,
+		})
+		if waitErr != nil {
+			exit(exitCodeFailure)
+		}
 	},
 }
 
@@ -125,6 +167,8 @@ func findSimulatorJob(ctx context.Context, client kubernetes.Interface) (*batchv
 
 func NewWatchCmd() *cobra.Command {
 	watchCmd.Flags().StringVarP(&config.Namespace, "namespace", "n", config.Namespace, "namespace in which to create simulation resources")
+	watchCmd.Flags().StringVar(&config.RunID, "run-id", config.RunID, "only watch the pods and jobs of the run, all runs are watched if empty")
+	watchCmd.Flags().DurationVar(&config.WaitTimeout, "timeout", config.WaitTimeout, "maximum time to wait for the simulation pods to complete")
 
 	return watchCmd
 }
@@ -13,6 +13,8 @@ var (
 	Kubeconfig string
 	// NoGUI disables printing graphical elements like spinners, progress bars...
 	NoGUI bool
+	// Output is the output format (human, json or ndjson).
+	Output = "human"
 	// Verbose configures verbose output.
 	Verbose bool
 	// Silent disables all internal logs.
@@ -23,8 +25,38 @@ var (
 	KWOKNamespace = "kube-system"
 	// Namespace is the namespace in which pods should be created.
 	Namespace = "default"
+	// NamespaceCount is the number of namespaces, named <namespace>-<i>, across which pods and jobs are spread.
+	// Pods and jobs are created in Namespace if it is 1.
+	NamespaceCount = 1
+	// NamespaceDistribution is the distribution of pods and jobs across the namespaces, round-robin, weighted or zipf.
+	NamespaceDistribution = "round-robin"
+	// NamespaceWeights are the weights of the namespaces of the weighted distribution.
+	NamespaceWeights []float64
+	// ZipfExponent is the exponent of the zipf distribution, higher values concentrate more objects in the first namespaces.
+	ZipfExponent = 1.0
+	// Workloads is the path of a YAML file defining tenants, each creating jobs or pods in its own namespaces
+	// with its own template, arrival process and rate, concurrently with the node, pod and job creators.
+	Workloads string
+	// Seed seeds all randomness of a run (names, env var payloads, random sizes), 0 picks a random seed.
+	// The seed is recorded in the results, so runs can be reproduced.
+	Seed int64
+	// RunID identifies the nodes, pods and jobs created by a run, run mints a new one if it is empty,
+	// which is derived from the Seed if it is set.
+	// Clean and watch only act on the objects of the run if it is set.
+	RunID string
+	// Naming is the naming scheme of created nodes, pods and jobs, random (<prefix>-<random>) or sequential (<prefix>-<run-id>-<seq>).
+	Naming = "random"
+	// Resume configures whether a run continues an interrupted run with the same run ID instead of starting from zero.
+	Resume bool
 	// Resources is the list of resources that should be deleted. If not specified, default is all.
 	Resources []string
+	// Force configures whether clean removes pods stuck terminating, orphaned pods & jobs and leftover simulator jobs,
+	// and deletes pods with a grace period of 0.
+	Force bool
+	// DeleteFrequency is the frequency at which the deleters should be invoked.
+	DeleteFrequency = 1 * time.Second
+	// DeleteRequests is the number of resources of each kind that should be deleted in each iteration.
+	DeleteRequests = 100
 	// SimulatorNamespace is the namespace in which simulator pods should be created.
 	SimulatorNamespace = "default"
 	// PodCreatorFrequency is the frequency at which the pod creator should be invoked.
@@ -45,10 +77,85 @@ var (
 	JobCreatorRequests = 2
 	// JobCreatorLimit is the maximum number of jobs that should be created.
 	JobCreatorLimit int
+	// ObjectTemplate is the path of a YAML template of objects of an arbitrary kind which should be created
+	// through the dynamic client, e.g. custom resources of a batch system. No objects are created if it is empty.
+	ObjectTemplate string
+	// ObjectResource is the resource of the created objects as <resource>.<version>.<group>,
+	// it is guessed from the kind of the template if empty.
+	ObjectResource string
+	// ObjectClusterScoped configures whether the created objects are cluster-scoped instead of namespaced.
+	ObjectClusterScoped bool
+	// ObjectCreatorFrequency is the frequency at which the object creator should be invoked.
+	ObjectCreatorFrequency = 1 * time.Second
+	// ObjectCreatorRequests is the number of requests that should be made to the object creator in each iteration.
+	ObjectCreatorRequests = 2
+	// ObjectCreatorLimit is the maximum number of objects that should be created.
+	ObjectCreatorLimit int
+	// Kueue configures whether jobs are created suspended and submitted to Kueue LocalQueues,
+	// which are set up with a ClusterQueue whose quota matches the fake node pool.
+	Kueue bool
+	// KueueResourceFlavor is the name of the Kueue ResourceFlavor which matches the fake nodes.
+	KueueResourceFlavor = "kwok"
+	// KueueClusterQueue is the name of the Kueue ClusterQueue which admits the jobs.
+	KueueClusterQueue = "simulator"
+	// KueueLocalQueue is the name of the Kueue LocalQueue created in every namespace in which jobs are created.
+	KueueLocalQueue = "simulator"
+	// Gangs configures whether gangs, a PodGroup and its member pods which are scheduled all-or-nothing, are created.
+	Gangs bool
+	// GangStyle is the gang scheduler whose PodGroups are created, coscheduling or volcano.
+	GangStyle = "coscheduling"
+	// GangSchedulerName is the scheduler of the member pods of gangs, the default scheduler of the gang style if empty.
+	GangSchedulerName string
+	// GangSizes are the numbers of member pods of gangs, every gang draws one of them.
+	GangSizes = []int{4}
+	// GangSizeWeights are the weights of the gang sizes, one per size, sizes are drawn uniformly if empty.
+	GangSizeWeights []float64
+	// GangCreatorFrequency is the frequency at which the gang creator should be invoked.
+	GangCreatorFrequency = 1 * time.Second
+	// GangCreatorRequests is the number of gangs that should be created in each iteration.
+	GangCreatorRequests = 1
+	// GangCreatorLimit is the maximum number of gangs that should be created.
+	GangCreatorLimit int
+	// JobClass is the value of the job class label set on created jobs, job metrics are reported per job class.
+	JobClass = "default"
 	// DefaultPollInterval is the default interval at which the polling functions should be invoked.
 	DefaultPollInterval = 2 * time.Second
 	// DefaultPollTimeout is the default timeout for polling functions.
 	DefaultPollTimeout = 150 * time.Second
+	// WaitTimeout is the maximum time watch waits for the simulation pods to complete.
+	WaitTimeout = 3 * time.Hour
+	// Measure configures whether scheduler throughput, pod lifecycle and job metrics are measured with informers during a run.
+	Measure bool
+	// SettleTimeout is the maximum time a run keeps measuring after the simulation until the pods and the gang placement settle.
+	SettleTimeout = 10 * time.Minute
+	// Preflight configures whether cluster capacity & admission checks should run before the simulation starts.
+	Preflight bool
+	// MetricsAddr is the address on which Prometheus metrics are served during a simulation, empty disables the endpoint.
+	MetricsAddr string
+	// ScrapeAPIServerMetrics configures whether API server metrics are scraped during a simulation and reported as a delta.
+	ScrapeAPIServerMetrics bool
+	// ScrapeInterval is the interval at which API server metrics are scraped.
+	ScrapeInterval = 30 * time.Second
+	// TraceRequests configures whether latency, status codes, sizes and rate limiter wait of API requests are recorded.
+	TraceRequests bool
+	// ResultsDir is the directory in which the run configuration, summary and metrics time series are written, empty disables writing results.
+	ResultsDir string
+	// ReportFile is the path of the generated HTML report, empty writes report.html into the results directory.
+	ReportFile string
+	// Assertions holds the <metric> <operator> <value> expressions evaluated against the final metrics of a run.
+	Assertions []string
+	// CompareThreshold is the maximum allowed worsening of a metric, in percent, before compare reports a regression.
+	CompareThreshold = 10.0
+	// CompareMetricThresholds overrides CompareThreshold for metrics matching a pattern, in <pattern>=<percent> format.
+	CompareMetricThresholds []string
+	// CompareIgnore holds the patterns of metrics which compare never reports as regressions.
+	CompareIgnore []string
+	// Offline configures whether the simulation runs against an in-memory fake clientset instead of a cluster.
+	Offline bool
+	// OfflineLatency is the mean latency injected into write requests in offline mode.
+	OfflineLatency time.Duration
+	// OfflineErrorRate is the fraction of write requests, between 0 and 1, which fail in offline mode.
+	OfflineErrorRate float64
 	// Remote configures whether the simulator should be executed in a Kubernetes cluster.
 	Remote bool
 	// PodSpecSize is the size of the pod spec in bytes.
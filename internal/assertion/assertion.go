@@ -0,0 +1,196 @@
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/results"
+)
+
+// Operator compares a metric value against the expected value.
+type Operator string
+
+const (
+	LessThan       Operator = "<"
+	LessOrEqual    Operator = "<="
+	GreaterThan    Operator = ">"
+	GreaterOrEqual Operator = ">="
+	Equal          Operator = "=="
+	NotEqual       Operator = "!="
+)
+
+// Kind is the kind of the expected value, it must match the unit of the metric.
+type Kind string
+
+const (
+	// KindNumber is a plain number which can be compared against any metric.
+	KindNumber Kind = "number"
+	// KindDuration is a Go duration (e.g. 500ms) which can only be compared against latencies and durations.
+	KindDuration Kind = "duration"
+	// KindPercent is a percentage (e.g. 1%) which can only be compared against ratios like the error rate.
+	KindPercent Kind = "percent"
+)
+
+// Assertion is a parsed <metric> <operator> <value> expression.
+type Assertion struct {
+	// Expression is the original expression.
+	Expression string `json:"expression"`
+	// Metric is the metric name, or the suffix of a metric name (e.g. scheduling.p95 for pod.scheduling.p95).
+	Metric string `json:"metric"`
+	// Operator is the comparison operator.
+	Operator Operator `json:"operator"`
+	// Value is the expected value in the base unit of the metric, durations are in seconds and percentages are ratios.
+	Value float64 `json:"value"`
+	// Kind is the kind of the expected value.
+	Kind Kind `json:"kind"`
+}
+
+var expression = regexp.MustCompile(`^\s*([A-Za-z0-9_.\-]+)\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+
+// Parse parses an expression like pod.create.p99 < 500ms, pod.failed == 0 or pod.create.error_rate <= 1%.
+func Parse(s string) (Assertion, error) {
+	match := expression.FindStringSubmatch(s)
+	if match == nil {
+		return Assertion{}, fmt.Errorf("invalid assertion %q: expected <metric> <operator> <value>, e.g. pod.create.p99 < 500ms", s)
+	}
+	a := Assertion{Expression: strings.TrimSpace(s), Metric: match[1], Operator: Operator(match[2])}
+	raw := match[3]
+	switch {
+	case strings.HasSuffix(raw, "%"):
+		value, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+		if err != nil {
+			return Assertion{}, fmt.Errorf("invalid assertion %q: invalid percentage %q", s, raw)
+		}
+		a.Value, a.Kind = value/100, KindPercent
+	default:
+		if value, err := strconv.ParseFloat(raw, 64); err == nil {
+			a.Value, a.Kind = value, KindNumber
+			break
+		}
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return Assertion{}, fmt.Errorf("invalid assertion %q: %q is neither a number, a percentage nor a duration", s, raw)
+		}
+		a.Value, a.Kind = duration.Seconds(), KindDuration
+	}
+	return a, nil
+}
+
+// ParseAll parses every expression and returns the first error.
+func ParseAll(expressions []string) ([]Assertion, error) {
+	assertions := make([]Assertion, 0, len(expressions))
+	for _, s := range expressions {
+		a, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, a)
+	}
+	return assertions, nil
+}
+
+// Result is the outcome of evaluating an assertion.
+type Result struct {
+	Assertion
+	// Resolved is the full name of the evaluated metric, empty if the metric was not found.
+	Resolved string `json:"resolved,omitempty"`
+	// Actual is the metric value, nil if the metric was not found.
+	Actual *float64 `json:"actual"`
+	// Unit is the unit of the metric.
+	Unit results.Unit `json:"unit,omitempty"`
+	// Passed is true if the metric satisfied the assertion.
+	Passed bool `json:"passed"`
+	// Message explains why the assertion could not be evaluated.
+	Message string `json:"message,omitempty"`
+}
+
+// Evaluate evaluates every assertion against the metrics, an assertion fails if its metric does not exist.
+func Evaluate(assertions []Assertion, metrics []results.Metric) []Result {
+	evaluated := make([]Result, 0, len(assertions))
+	for _, a := range assertions {
+		evaluated = append(evaluated, evaluate(a, metrics))
+	}
+	return evaluated
+}
+
+// Failed returns the number of results which did not pass.
+func Failed(evaluated []Result) int {
+	failed := 0
+	for _, r := range evaluated {
+		if !r.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+func evaluate(a Assertion, metrics []results.Metric) Result {
+	r := Result{Assertion: a}
+	metric, err := lookup(a.Metric, metrics)
+	if err != nil {
+		r.Message = err.Error()
+		return r
+	}
+	r.Resolved = metric.Name
+	r.Unit = metric.Unit
+	r.Actual = &metric.Value
+	switch {
+	case a.Kind == KindDuration && metric.Unit != results.UnitSeconds:
+		r.Message = fmt.Sprintf("metric %s is measured in %s and cannot be compared with a duration", metric.Name, metric.Unit)
+		return r
+	case a.Kind == KindPercent && metric.Unit != results.UnitRatio:
+		r.Message = fmt.Sprintf("metric %s is measured in %s and cannot be compared with a percentage", metric.Name, metric.Unit)
+		return r
+	}
+	r.Passed = compare(metric.Value, a.Operator, a.Value)
+	return r
+}
+
+// lookup returns the metric with the given name, or the only metric whose name ends with .<name>.
+// The old names of renamed metrics resolve to their current name.
+func lookup(name string, metrics []results.Metric) (results.Metric, error) {
+	name = results.CanonicalMetricName(name)
+	var candidates []results.Metric
+	for _, m := range metrics {
+		if m.Name == name {
+			return m, nil
+		}
+		if strings.HasSuffix(m.Name, "."+name) {
+			candidates = append(candidates, m)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return results.Metric{}, fmt.Errorf("metric %s was not measured", name)
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			names = append(names, c.Name)
+		}
+		return results.Metric{}, fmt.Errorf("metric %s is ambiguous, it matches %s", name, strings.Join(names, ", "))
+	}
+}
+
+func compare(actual float64, operator Operator, expected float64) bool {
+	switch operator {
+	case LessThan:
+		return actual < expected
+	case LessOrEqual:
+		return actual <= expected
+	case GreaterThan:
+		return actual > expected
+	case GreaterOrEqual:
+		return actual >= expected
+	case Equal:
+		return actual == expected
+	case NotEqual:
+		return actual != expected
+	default:
+		return false
+	}
+}
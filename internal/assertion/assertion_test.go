@@ -0,0 +1,75 @@
+package assertion
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expression string
+		want       Assertion
+	}{
+		{"pod.create.p99 < 500ms", Assertion{Expression: "pod.create.p99 < 500ms", Metric: "pod.create.p99", Operator: LessThan, Value: 0.5, Kind: KindDuration}},
+		{"pod.failed==0", Assertion{Expression: "pod.failed==0", Metric: "pod.failed", Operator: Equal, Value: 0, Kind: KindNumber}},
+		{" scheduling.p95 <= 5s ", Assertion{Expression: "scheduling.p95 <= 5s", Metric: "scheduling.p95", Operator: LessOrEqual, Value: 5, Kind: KindDuration}},
+		{"pod.create.error_rate < 1%", Assertion{Expression: "pod.create.error_rate < 1%", Metric: "pod.create.error_rate", Operator: LessThan, Value: 0.01, Kind: KindPercent}},
+		{"pod.create.throughput >= 99.5", Assertion{Expression: "pod.create.throughput >= 99.5", Metric: "pod.create.throughput", Operator: GreaterOrEqual, Value: 99.5, Kind: KindNumber}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expression)
+		assert.NoError(t, err, tt.expression)
+		assert.Equal(t, tt.want, got, tt.expression)
+	}
+
+	for _, invalid := range []string{"", "pod.create.p99", "pod.create.p99 < ", "pod.create.p99 =< 1s", "pod.create.p99 < fast", "pod.create.p99 < x%"} {
+		_, err := Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	metrics := []results.Metric{
+		{Name: "pod.create.p99", Value: 0.3, Unit: results.UnitSeconds},
+		{Name: "pod.create.error_rate", Value: 0.02, Unit: results.UnitRatio},
+		{Name: "pod.failed", Value: 0, Unit: results.UnitCount},
+		{Name: "pod.scheduling.p95", Value: 6, Unit: results.UnitSeconds},
+		{Name: "pod.startup.p95", Value: 1, Unit: results.UnitSeconds},
+		{Name: "pod.end_to_end.p95", Value: 2, Unit: results.UnitSeconds},
+	}
+	assertions, err := ParseAll([]string{
+		"pod.create.p99 < 500ms",
+		"pod.failed == 0",
+		"scheduling.p95 < 5s",
+		"pod.create.error_rate <= 1%",
+		"pod.create.p99 < 10%",
+		"p95 < 10s",
+		"node.create.p99 < 1s",
+		"pods.failed == 0",
+	})
+	assert.NoError(t, err)
+
+	evaluated := Evaluate(assertions, metrics)
+	passed := make([]bool, 0, len(evaluated))
+	for _, r := range evaluated {
+		passed = append(passed, r.Passed)
+	}
+	assert.Equal(t, []bool{true, true, false, false, false, false, false, true}, passed)
+	assert.Equal(t, 5, Failed(evaluated))
+
+	assert.Equal(t, "pod.scheduling.p95", evaluated[2].Resolved)
+	if assert.NotNil(t, evaluated[2].Actual) {
+		assert.Equal(t, 6.0, *evaluated[2].Actual)
+	}
+	assert.Empty(t, evaluated[2].Message)
+	assert.Contains(t, evaluated[4].Message, "cannot be compared with a percentage")
+	assert.Contains(t, evaluated[5].Message, "ambiguous")
+	assert.Nil(t, evaluated[6].Actual)
+	assert.Contains(t, evaluated[6].Message, "was not measured")
+	assert.Equal(t, "pod.failed", evaluated[7].Resolved, "the old pods.* names must resolve to the pod.* metrics")
+}
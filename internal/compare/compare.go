@@ -0,0 +1,195 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dejanzele/batch-simulator/internal/results"
+)
+
+// Status is the outcome of comparing a single metric.
+type Status string
+
+const (
+	// StatusUnchanged is set when the metric did not change or is neutral.
+	StatusUnchanged Status = "unchanged"
+	// StatusImproved is set when the metric changed in the better direction.
+	StatusImproved Status = "improved"
+	// StatusWorse is set when the metric got worse within the threshold.
+	StatusWorse Status = "worse"
+	// StatusRegression is set when the metric got worse by more than the threshold.
+	StatusRegression Status = "regression"
+	// StatusIgnored is set when the metric matches an ignore pattern.
+	StatusIgnored Status = "ignored"
+)
+
+// Options configures which changes are reported as regressions.
+type Options struct {
+	// Threshold is the maximum allowed worsening of a metric, in percent.
+	Threshold float64
+	// MetricThresholds overrides Threshold for metrics matching a pattern, patterns are matched with path.Match
+	// so pod.* matches every pod creation metric and *.p99 matches every 99th percentile.
+	// The first matching pattern in the order of the slice is used.
+	MetricThresholds []MetricThreshold
+	// Ignore holds the patterns of metrics which are never reported as regressions.
+	Ignore []string
+}
+
+// MetricThreshold is the maximum allowed worsening, in percent, of metrics matching Pattern.
+type MetricThreshold struct {
+	Pattern   string  `json:"pattern"`
+	Threshold float64 `json:"threshold"`
+}
+
+// ParseMetricThreshold parses a pattern=percent threshold, e.g. pod.create.p99=20 or *.error_rate=0.
+func ParseMetricThreshold(s string) (MetricThreshold, error) {
+	pattern, value, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" {
+		return MetricThreshold{}, fmt.Errorf("invalid metric threshold %q: expected <pattern>=<percent>", s)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return MetricThreshold{}, fmt.Errorf("invalid metric threshold pattern %q: %w", pattern, err)
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || threshold < 0 {
+		return MetricThreshold{}, fmt.Errorf("invalid metric threshold %q: percent must be a non-negative number", s)
+	}
+	return MetricThreshold{Pattern: pattern, Threshold: threshold}, nil
+}
+
+// Change is the difference of a single metric between the base and the current run.
+type Change struct {
+	// Name is the metric name.
+	Name string `json:"name"`
+	// Unit is the unit of the metric values.
+	Unit results.Unit `json:"unit"`
+	// Base is the value in the base run.
+	Base float64 `json:"base"`
+	// Current is the value in the current run.
+	Current float64 `json:"current"`
+	// Percent is the relative change from the base value, in percent.
+	// It is nil if the base value is zero and the current one is not.
+	Percent *float64 `json:"percent"`
+	// Threshold is the maximum allowed worsening, in percent.
+	Threshold float64 `json:"threshold"`
+	// Status is the outcome of the comparison.
+	Status Status `json:"status"`
+}
+
+// Result holds the changes of every metric present in both runs.
+type Result struct {
+	// Changes holds the changes ordered by metric name.
+	Changes []Change `json:"changes"`
+	// Missing holds the names of metrics present in only one of the runs.
+	Missing []string `json:"missing,omitempty"`
+	// Regressions is the number of metrics which got worse by more than their threshold.
+	Regressions int `json:"regressions"`
+}
+
+// Runs compares the summary metrics of the current run against the base run.
+func Runs(base, current *results.Summary, opts Options) *Result {
+	return Metrics(results.Metrics(base), results.Metrics(current), opts)
+}
+
+// Metrics compares the current metrics against the base metrics.
+func Metrics(base, current []results.Metric, opts Options) *Result {
+	currentByName := make(map[string]results.Metric, len(current))
+	for _, m := range current {
+		currentByName[m.Name] = m
+	}
+
+	result := &Result{}
+	for _, b := range base {
+		c, ok := currentByName[b.Name]
+		if !ok {
+			result.Missing = append(result.Missing, b.Name)
+			continue
+		}
+		delete(currentByName, b.Name)
+
+		change := compare(b, c, opts)
+		if change.Status == StatusRegression {
+			result.Regressions++
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	for name := range currentByName {
+		result.Missing = append(result.Missing, name)
+	}
+	sort.Slice(result.Changes, func(i, j int) bool { return result.Changes[i].Name < result.Changes[j].Name })
+	sort.Strings(result.Missing)
+	return result
+}
+
+func compare(base, current results.Metric, opts Options) Change {
+	change := Change{
+		Name:      base.Name,
+		Unit:      base.Unit,
+		Base:      base.Value,
+		Current:   current.Value,
+		Percent:   percentChange(base.Value, current.Value),
+		Threshold: opts.threshold(base.Name),
+		Status:    StatusUnchanged,
+	}
+
+	if matchAny(opts.Ignore, base.Name) {
+		change.Status = StatusIgnored
+		return change
+	}
+	if base.Value == current.Value || base.Direction == results.Neutral {
+		return change
+	}
+	worse := current.Value > base.Value
+	if base.Direction == results.HigherIsBetter {
+		worse = !worse
+	}
+	switch {
+	case !worse:
+		change.Status = StatusImproved
+	// a metric which was zero in the base run exceeds any threshold once it gets worse
+	case change.Percent == nil || math.Abs(*change.Percent) > change.Threshold:
+		change.Status = StatusRegression
+	default:
+		change.Status = StatusWorse
+	}
+	return change
+}
+
+func (o Options) threshold(name string) float64 {
+	for _, t := range o.MetricThresholds {
+		if match(t.Pattern, name) {
+			return t.Threshold
+		}
+	}
+	return o.Threshold
+}
+
+func percentChange(base, current float64) *float64 {
+	if base == 0 {
+		if current == 0 {
+			return new(float64)
+		}
+		return nil
+	}
+	percent := (current - base) / math.Abs(base) * 100
+	return &percent
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// match matches the name against the pattern, patterns using the old name of a renamed metric match its current name.
+func match(pattern, name string) bool {
+	ok, _ := path.Match(results.CanonicalMetricName(pattern), name)
+	return ok
+}
@@ -0,0 +1,92 @@
+package compare
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	base := []results.Metric{
+		{Name: "pod.create.p99", Value: 0.2, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+		{Name: "pod.create.p50", Value: 0.1, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+		{Name: "pod.create.throughput", Value: 100, Unit: results.UnitPerSecond, Direction: results.HigherIsBetter},
+		{Name: "pod.create.error_rate", Value: 0, Unit: results.UnitRatio, Direction: results.LowerIsBetter},
+		{Name: "pod.create.executed", Value: 1000, Unit: results.UnitCount, Direction: results.Neutral},
+		{Name: "scheduler.peak_pending", Value: 10, Unit: results.UnitCount, Direction: results.LowerIsBetter},
+		{Name: "jobs.batch.makespan", Value: 60, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+	}
+	current := []results.Metric{
+		{Name: "pod.create.p99", Value: 0.25, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+		{Name: "pod.create.p50", Value: 0.105, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+		{Name: "pod.create.throughput", Value: 120, Unit: results.UnitPerSecond, Direction: results.HigherIsBetter},
+		{Name: "pod.create.error_rate", Value: 0.01, Unit: results.UnitRatio, Direction: results.LowerIsBetter},
+		{Name: "pod.create.executed", Value: 500, Unit: results.UnitCount, Direction: results.Neutral},
+		{Name: "scheduler.peak_pending", Value: 100, Unit: results.UnitCount, Direction: results.LowerIsBetter},
+		{Name: "requests.latency.p99", Value: 0.1, Unit: results.UnitSeconds, Direction: results.LowerIsBetter},
+	}
+
+	result := Metrics(base, current, Options{
+		Threshold:        10,
+		MetricThresholds: []MetricThreshold{{Pattern: "*.p99", Threshold: 30}},
+		Ignore:           []string{"scheduler.*"},
+	})
+
+	statuses := make(map[string]Status, len(result.Changes))
+	for _, change := range result.Changes {
+		statuses[change.Name] = change.Status
+	}
+	assert.Equal(t, map[string]Status{
+		"pod.create.p99":         StatusWorse,
+		"pod.create.p50":         StatusWorse,
+		"pod.create.throughput":  StatusImproved,
+		"pod.create.error_rate":  StatusRegression,
+		"pod.create.executed":    StatusUnchanged,
+		"scheduler.peak_pending": StatusIgnored,
+	}, statuses)
+	assert.Equal(t, 1, result.Regressions)
+	assert.Equal(t, []string{"jobs.batch.makespan", "requests.latency.p99"}, result.Missing)
+
+	p99 := result.Changes[3]
+	assert.Equal(t, "pod.create.p99", p99.Name)
+	assert.Equal(t, 30.0, p99.Threshold)
+	if assert.NotNil(t, p99.Percent) {
+		assert.InDelta(t, 25, *p99.Percent, 1e-9)
+	}
+	assert.Nil(t, result.Changes[0].Percent, "the change of a metric which was zero is undefined")
+
+	result = Metrics(base, current, Options{Threshold: 20})
+	assert.Equal(t, 3, result.Regressions, "p99, error rate and peak pending exceed 20%")
+}
+
+func TestMetrics_RenamedMetrics(t *testing.T) {
+	t.Parallel()
+
+	base := []results.Metric{{Name: "pod.startup.p99", Value: 1, Unit: results.UnitSeconds, Direction: results.LowerIsBetter}}
+	current := []results.Metric{{Name: "pod.startup.p99", Value: 2, Unit: results.UnitSeconds, Direction: results.LowerIsBetter}}
+
+	result := Metrics(base, current, Options{Threshold: 10, MetricThresholds: []MetricThreshold{{Pattern: "pods.startup.*", Threshold: 200}}})
+	assert.Equal(t, StatusWorse, result.Changes[0].Status, "thresholds of the old pods.* names must apply to the pod.* metrics")
+
+	result = Metrics(base, current, Options{Threshold: 10, Ignore: []string{"pods.*"}})
+	assert.Equal(t, StatusIgnored, result.Changes[0].Status, "ignore patterns of the old pods.* names must apply to the pod.* metrics")
+}
+
+func TestParseMetricThreshold(t *testing.T) {
+	t.Parallel()
+
+	threshold, err := ParseMetricThreshold("pod.create.p99=20%")
+	assert.NoError(t, err)
+	assert.Equal(t, MetricThreshold{Pattern: "pod.create.p99", Threshold: 20}, threshold)
+
+	threshold, err = ParseMetricThreshold("*.error_rate=0")
+	assert.NoError(t, err)
+	assert.Equal(t, MetricThreshold{Pattern: "*.error_rate", Threshold: 0}, threshold)
+
+	for _, invalid := range []string{"pod.create.p99", "=10", "pod=fast", "pod=-1", "[=10"} {
+		_, err := ParseMetricThreshold(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,215 @@
+// Package fault injects latencies and errors into the requests of fake clientsets,
+// it backs the offline mode of the simulator and the fault tests of the simulator packages.
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// Fault is a latency and error injected into the requests of a fake clientset.
+type Fault struct {
+	// Verb is the request verb (e.g. create, list, delete-collection), * matches every verb.
+	Verb string
+	// Resource is the request resource (e.g. pods, jobs), * matches every resource.
+	Resource string
+	// Latency delays every matching request.
+	Latency time.Duration
+	// Jitter is the fraction by which the latency varies, e.g. 0.5 delays requests between half and one and a half of Latency.
+	Jitter float64
+	// Err is returned by matching requests, if nil the request is handled by the clientset after the latency.
+	Err error
+	// Probability is the fraction of matching requests, between 0 and 1, the fault is injected into.
+	// 0 injects it into every matching request.
+	Probability float64
+	// Times is the number of requests the fault is injected into, 0 injects it into every matching request.
+	Times int
+}
+
+// Injector injects faults into requests and counts the requests by verb and resource.
+// Every matching fault which has not been exhausted is injected in order, until one of them returns an error,
+// so latencies add up and the first matching error is returned.
+type Injector struct {
+	faults   []*injectedFault
+	requests map[string]int
+	rand     *util.Rand
+	mutex    sync.Mutex
+}
+
+type injectedFault struct {
+	Fault
+	injected int
+}
+
+// NewInjector creates an Injector of the faults, the jitter and probability of faults are drawn from rnd.
+func NewInjector(rnd *util.Rand, faults ...Fault) *Injector {
+	injector := &Injector{requests: make(map[string]int), rand: rnd}
+	for _, fault := range faults {
+		injector.faults = append(injector.faults, &injectedFault{Fault: fault})
+	}
+	return injector
+}
+
+// Inject prepends a reactor which injects the faults into the requests of the clientset.
+// Requests of a fake clientset are handled under a single lock, so the latency also delays concurrent requests.
+func Inject(clientset *fake.Clientset, faults ...Fault) *Injector {
+	injector := NewInjector(util.NewRand(1), faults...)
+	clientset.PrependReactor("*", "*", injector.react)
+	return injector
+}
+
+// Requests returns the number of requests with the verb and resource, including the failed ones.
+func (i *Injector) Requests(verb, resource string) int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.requests[verb+" "+resource]
+}
+
+// Inject counts the request, waits for the latencies of the matching faults and returns the injected error, if any.
+// It returns the error of the context if it is cancelled while waiting.
+func (i *Injector) Inject(ctx context.Context, verb, resource string) error {
+	latency, err := i.match(verb, resource)
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (i *Injector) react(action k8stesting.Action) (bool, runtime.Object, error) {
+	if err := i.Inject(context.Background(), action.GetVerb(), action.GetResource().Resource); err != nil {
+		return true, nil, err
+	}
+	return false, nil, nil
+}
+
+// match counts the request and returns the latency and the error which should be injected.
+func (i *Injector) match(verb, resource string) (time.Duration, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.requests[verb+" "+resource]++
+	var latency time.Duration
+	for _, fault := range i.faults {
+		if (fault.Verb != "*" && fault.Verb != verb) || (fault.Resource != "*" && fault.Resource != resource) {
+			continue
+		}
+		if fault.Times > 0 && fault.injected >= fault.Times {
+			continue
+		}
+		if fault.Probability > 0 && i.rand.Float64() >= fault.Probability {
+			continue
+		}
+		fault.injected++
+		jitter := 1.0
+		if fault.Jitter > 0 {
+			jitter += (2*i.rand.Float64() - 1) * fault.Jitter
+		}
+		latency += time.Duration(jitter * float64(fault.Latency))
+		if fault.Err != nil {
+			return latency, fault.Err
+		}
+	}
+	return latency, nil
+}
+
+// EnableDeleteCollection prepends a reactor which deletes the objects matching the label selector of delete collection
+// requests, the fake clientset accepts them without deleting anything.
+// It must be called before Inject so faults are injected into delete collection requests as well.
+func EnableDeleteCollection(clientset *fake.Clientset) {
+	tracker := clientset.Tracker()
+	clientset.PrependReactor("delete-collection", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteCollectionAction)
+		gvr := deleteAction.GetResource()
+		gvk, err := kindFor(gvr)
+		if err != nil {
+			return true, nil, err
+		}
+		list, err := tracker.List(gvr, gvk, deleteAction.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return true, nil, err
+		}
+		selector := deleteAction.GetListRestrictions().Labels
+		for _, item := range items {
+			accessor, err := meta.Accessor(item)
+			if err != nil {
+				return true, nil, err
+			}
+			if selector != nil && !selector.Matches(labels.Set(accessor.GetLabels())) {
+				continue
+			}
+			if err := tracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName()); err != nil {
+				return true, nil, err
+			}
+		}
+		return true, nil, nil
+	})
+}
+
+// kindFor returns the kind of the resource registered in the client-go scheme.
+func kindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if gvk.GroupVersion() != gvr.GroupVersion() || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		if plural, _ := meta.UnsafeGuessKindToResource(gvk); plural == gvr {
+			return gvk, nil
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("resource %s is not registered in the client-go scheme", gvr)
+}
+
+// TooManyRequests returns the error of a request rejected by API priority and fairness or max in-flight limits.
+func TooManyRequests() error {
+	return apierrors.NewTooManyRequests("the server has received too many requests and has asked us to try again later", 1)
+}
+
+// InternalError returns the error of a request which failed in the API server, e.g. due to an etcd timeout.
+func InternalError() error {
+	return apierrors.NewInternalError(errors.New("etcdserver: request timed out"))
+}
+
+// Conflict returns the error of a write which raced with another write of the same object.
+func Conflict(resource, name string) error {
+	return apierrors.NewConflict(
+		schema.GroupResource{Resource: resource},
+		name,
+		errors.New("the object has been modified; please apply your changes to the latest version and try again"),
+	)
+}
+
+// ConnectionReset returns the error of a request whose connection was reset, as returned by the HTTP client.
+func ConnectionReset() error {
+	return &url.Error{
+		Op:  "Post",
+		URL: "https://127.0.0.1:6443",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+	}
+}
@@ -0,0 +1,139 @@
+package fault
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestInject(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	injector := Inject(clientset,
+		Fault{Verb: "create", Resource: "pods", Err: TooManyRequests(), Times: 2},
+		Fault{Verb: "create", Resource: "pods", Err: ConnectionReset(), Times: 1},
+		Fault{Verb: "list", Resource: "*", Latency: 20 * time.Millisecond},
+	)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	for i := 0; i < 2; i++ {
+		_, err := clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+		assert.True(t, apierrors.IsTooManyRequests(err))
+	}
+	_, err := clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	assert.True(t, utilnet.IsConnectionReset(err))
+	_, err = clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	assert.NoError(t, err, "faults are exhausted after the configured number of times")
+
+	start := time.Now()
+	pods, err := clientset.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, pods.Items, 1)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	assert.Equal(t, 4, injector.Requests("create", "pods"))
+	assert.Equal(t, 1, injector.Requests("list", "pods"))
+	assert.Equal(t, 0, injector.Requests("delete", "pods"))
+}
+
+func TestInjector_Inject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("latencies add up until an error is injected", func(t *testing.T) {
+		t.Parallel()
+
+		injector := NewInjector(util.NewRand(1),
+			Fault{Verb: "*", Resource: "*", Latency: 10 * time.Millisecond},
+			Fault{Verb: "create", Resource: "pods", Latency: 10 * time.Millisecond, Err: InternalError()},
+			Fault{Verb: "create", Resource: "*", Err: Conflict("pods", "pod")},
+		)
+		start := time.Now()
+		err := injector.Inject(context.Background(), "create", "pods")
+		assert.True(t, apierrors.IsInternalError(err))
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.NoError(t, injector.Inject(context.Background(), "delete", "pods"))
+		assert.Equal(t, 1, injector.Requests("create", "pods"))
+	})
+
+	t.Run("faults are injected into a fraction of the requests", func(t *testing.T) {
+		t.Parallel()
+
+		injector := NewInjector(util.NewRand(1), Fault{Verb: "*", Resource: "*", Err: TooManyRequests(), Probability: 0.5})
+		failed := 0
+		for i := 0; i < 1000; i++ {
+			if injector.Inject(context.Background(), "create", "pods") != nil {
+				failed++
+			}
+		}
+		assert.InDelta(t, 500, failed, 100)
+	})
+
+	t.Run("jitter varies the latency", func(t *testing.T) {
+		t.Parallel()
+
+		injector := NewInjector(util.NewRand(1), Fault{Verb: "*", Resource: "*", Latency: time.Second, Jitter: 0.5})
+		for i := 0; i < 100; i++ {
+			latency, err := injector.match("create", "pods")
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, latency, 500*time.Millisecond)
+			assert.LessOrEqual(t, latency, 1500*time.Millisecond)
+		}
+	})
+
+	t.Run("waiting for the latency respects context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		injector := NewInjector(util.NewRand(1), Fault{Verb: "*", Resource: "*", Latency: time.Minute})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, injector.Inject(ctx, "delete", "jobs"), context.DeadlineExceeded)
+	})
+}
+
+func TestEnableDeleteCollection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	labels := map[string]string{"app": "batch-simulator"}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "labeled", Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other", Labels: labels}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: labels}},
+	)
+	EnableDeleteCollection(clientset)
+
+	err := clientset.CoreV1().Pods("default").DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: "app=batch-simulator"})
+	assert.NoError(t, err)
+	err = clientset.CoreV1().Nodes().DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: "app=batch-simulator"})
+	assert.NoError(t, err)
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	names := make([]string, 0, len(pods.Items))
+	for i := range pods.Items {
+		names = append(names, pods.Items[i].Name)
+	}
+	assert.ElementsMatch(t, []string{"unlabeled", "other-namespace"}, names)
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, nodes.Items)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, apierrors.IsTooManyRequests(TooManyRequests()))
+	assert.True(t, apierrors.IsInternalError(InternalError()))
+	assert.True(t, apierrors.IsConflict(Conflict("pods", "pod")))
+	assert.True(t, utilnet.IsConnectionReset(ConnectionReset()))
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Permission describes a single verb on a Kubernetes resource which the current identity needs.
+type Permission struct {
+	// Purpose is the command or scenario which needs the permission (e.g. run, clean).
+	Purpose string `json:"purpose"`
+	// Verb is the Kubernetes API verb (e.g. create, list, deletecollection).
+	Verb string `json:"verb"`
+	// Group is the API group of the resource, empty for the core group.
+	Group string `json:"group,omitempty"`
+	// Resource is the plural resource name (e.g. pods, nodes).
+	Resource string `json:"resource"`
+	// Namespace is the namespace in which the verb is checked, empty for cluster-scoped resources.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = p.Resource + "." + p.Group
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in %s", p.Verb, resource, p.Namespace)
+}
+
+// PermissionResult is the outcome of a SelfSubjectAccessReview for a single Permission.
+type PermissionResult struct {
+	Permission
+	// Allowed is true if the current identity is allowed to perform the verb.
+	Allowed bool `json:"allowed"`
+	// Reason is the optional reason returned by the authorizer.
+	Reason string `json:"reason,omitempty"`
+}
+
+// CheckPermissions issues a SelfSubjectAccessReview for every provided permission and returns the results in the same order.
+// It returns an error if the API server fails to evaluate any of the reviews.
+func CheckPermissions(ctx context.Context, client kubernetes.Interface, permissions []Permission) ([]PermissionResult, error) {
+	results := make([]PermissionResult, 0, len(permissions))
+	for _, p := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: p.Namespace,
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+				},
+			},
+		}
+		response, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review permission to %s: %w", p, err)
+		}
+		results = append(results, PermissionResult{
+			Permission: p,
+			Allowed:    response.Status.Allowed,
+			Reason:     response.Status.Reason,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	t.Parallel()
+
+	permissions := []Permission{
+		{Purpose: "run", Verb: "create", Resource: "nodes"},
+		{Purpose: "run", Verb: "create", Resource: "pods", Namespace: "default"},
+		{Purpose: "clean", Verb: "deletecollection", Group: "batch", Resource: "jobs", Namespace: "default"},
+	}
+
+	t.Run("returns review results in order", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset()
+		fakeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			review.Status.Allowed = attributes.Verb == "create"
+			if !review.Status.Allowed {
+				review.Status.Reason = "denied by test"
+			}
+			return true, review, nil
+		})
+
+		results, err := CheckPermissions(context.Background(), fakeClient, permissions)
+		assert.NoError(t, err)
+		assert.Len(t, results, 3)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, "nodes", results[0].Resource)
+		assert.True(t, results[1].Allowed)
+		assert.Equal(t, "default", results[1].Namespace)
+		assert.False(t, results[2].Allowed)
+		assert.Equal(t, "denied by test", results[2].Reason)
+		assert.Equal(t, "deletecollection jobs.batch in default", results[2].String())
+	})
+
+	t.Run("returns error if review fails", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset()
+		fakeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("review failed")
+		})
+
+		results, err := CheckPermissions(context.Background(), fakeClient, permissions)
+		assert.ErrorContains(t, err, "review failed")
+		assert.Nil(t, results)
+	})
+}
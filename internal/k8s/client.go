@@ -13,6 +13,8 @@ import (
 type Config struct {
 	QPS   float32
 	Burst int
+	// Tracer, if set, traces every API request and the time it waited for the client-side rate limiter.
+	Tracer *RequestTracer
 }
 
 // NewClient creates a new Kubernetes client and automatically detects in-cluster and out-of-cluster config.
@@ -21,8 +23,7 @@ func NewClient(kubeconfig *string, config Config) (kubernetes.Interface, error)
 	if err != nil {
 		return nil, err
 	}
-	restConfig.QPS = config.QPS
-	restConfig.Burst = config.Burst
+	applyConfig(restConfig, config)
 
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -37,12 +38,21 @@ func NewDynamicClient(kubeconfig *string, config Config) (dynamic.Interface, err
 	if err != nil {
 		return nil, err
 	}
-	restConfig.QPS = config.QPS
-	restConfig.Burst = config.Burst
+	applyConfig(restConfig, config)
 
 	return dynamic.NewForConfig(restConfig)
 }
 
+// applyConfig applies the client-side rate limits and the request tracer to the REST config.
+func applyConfig(restConfig *rest.Config, config Config) {
+	restConfig.QPS = config.QPS
+	restConfig.Burst = config.Burst
+	if config.Tracer != nil {
+		config.Tracer.activate()
+		restConfig.Wrap(config.Tracer.WrapTransport)
+	}
+}
+
 // loadRESTConfig first tries to create an in-cluster config, and if it errors with ErrNotInCluster,
 // it tries to create an out-of-cluster config based on provided kubeconfig path.
 func loadRESTConfig(kubeconfig *string) (config *rest.Config, err error) {
@@ -0,0 +1,222 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+// deleteConcurrency is the maximum number of concurrent delete requests of a resource.
+const deleteConcurrency = 20
+
+// DeleteProgress is the progress of deleting the resources of a kind.
+type DeleteProgress struct {
+	// Total is the number of resources which were listed for deletion.
+	Total int `json:"total"`
+	// Deleted is the number of resources which were deleted.
+	Deleted int `json:"deleted"`
+	// Failed is the number of failed delete requests, the resources are deleted again by the next attempt.
+	Failed int `json:"failed"`
+}
+
+// deletion tracks the progress of deleting the resources of a kind across attempts.
+type deletion struct {
+	// previous is the progress of the previous attempts.
+	previous DeleteProgress
+	// listed is the number of resources listed by the current attempt.
+	listed int
+	// rateLimiter is the rate limiter of the current attempt, nil until the resources are listed.
+	rateLimiter *ratelimiter.RateLimiter[string]
+}
+
+// progress returns the progress of all attempts.
+func (d *deletion) progress() DeleteProgress {
+	progress := d.previous
+	progress.Total = d.previous.Deleted + d.listed
+	if d.rateLimiter != nil {
+		metrics := d.rateLimiter.Metrics()
+		progress.Deleted += metrics.Succeeded
+		progress.Failed += metrics.Failed
+	}
+	return progress
+}
+
+// DeleteProgress returns the progress of the deletions started by the Manager keyed by resource (e.g. pods).
+func (m *Manager) DeleteProgress() map[string]DeleteProgress {
+	m.deletionsMutex.Lock()
+	defer m.deletionsMutex.Unlock()
+	progress := make(map[string]DeleteProgress, len(m.deletions))
+	for resource, d := range m.deletions {
+		progress[resource] = d.progress()
+	}
+	return progress
+}
+
+// deleteObjects lists the resources having provided label in pages with every lister and deletes them through a rate limiter,
+// which deletes the resources of every interval concurrently, so deleting a very large number of resources neither overloads
+// the API server nor times out like a single delete collection, while the configured rate is reached even if delete requests
+// are slow. deleteFunc is called with the namespace/name key of namespaced resources and the name of cluster-scoped resources.
+// If async is set to false, this function will block until the resources are terminated or context exceeds deadline.
+func (m *Manager) deleteObjects(
+	ctx context.Context,
+	resource string,
+	lists []objectLister,
+	labelSelector string,
+	deleteFunc executor.DeleteFunc,
+	async bool,
+) error {
+	var keys []string
+	for _, list := range lists {
+		err := forEachObject(ctx, list, labelSelector, func(obj metav1.Object) {
+			keys = append(keys, cache.MetaObjectToName(obj).String())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	d := m.startDeletion(resource, len(keys))
+	err := m.deleteNames(ctx, resource, keys, deleteFunc, func(rl *ratelimiter.RateLimiter[string]) {
+		m.deletionsMutex.Lock()
+		defer m.deletionsMutex.Unlock()
+		d.rateLimiter = rl
+	})
+	if err != nil {
+		return err
+	}
+	if !async {
+		return waitFor(ctx, m.client, labelSelector, listersEmpty(lists))
+	}
+	return nil
+}
+
+// namespacedDeleteFunc deletes the object with the provided name in the namespace.
+type namespacedDeleteFunc func(ctx context.Context, namespace, name string) error
+
+// byKey returns a DeleteFunc which deletes the object of a namespace/name key with deleteFunc.
+func byKey(deleteFunc namespacedDeleteFunc) executor.DeleteFunc {
+	return func(ctx context.Context, key string) error {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return err
+		}
+		return deleteFunc(ctx, namespace, name)
+	}
+}
+
+// listersEmpty returns a ListFunc which reports whether none of the listers lists any resource.
+func listersEmpty(lists []objectLister) ListFunc {
+	return func(ctx context.Context, _ kubernetes.Interface, opts metav1.ListOptions) (bool, error) {
+		for _, list := range lists {
+			objects, _, err := list(ctx, opts)
+			if err != nil {
+				return false, err
+			}
+			if len(objects) > 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// cleanedNamespaces returns the namespace of the Manager followed by the generated namespaces of all runs.
+// The generated namespaces are shared by the runs which spread their pods and jobs across namespaces with the same names,
+// so the resources of a run are deleted in all of them instead of deleting the namespaces of the run.
+func (m *Manager) cleanedNamespaces(ctx context.Context) ([]string, error) {
+	namespaces := []string{m.namespace}
+	err := forEachObject(ctx, namespaceLister(m.client), resources.LabelSelectorFakeNamespace, func(obj metav1.Object) {
+		if obj.GetName() != m.namespace {
+			namespaces = append(namespaces, obj.GetName())
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list generated namespaces: %w", err)
+	}
+	return namespaces, nil
+}
+
+// cleanedListers returns the listers created by newLister for every namespace returned by cleanedNamespaces.
+func (m *Manager) cleanedListers(ctx context.Context, newLister func(namespace string) objectLister) ([]objectLister, error) {
+	namespaces, err := m.cleanedNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lists := make([]objectLister, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		lists = append(lists, newLister(namespace))
+	}
+	return lists, nil
+}
+
+// deleteNames deletes the resources with the provided names through a rate limiter, up to deleteConcurrency at a time,
+// onStart is called with the rate limiter before it is started, e.g. to track its progress.
+func (m *Manager) deleteNames(
+	ctx context.Context,
+	resource string,
+	names []string,
+	deleteFunc executor.DeleteFunc,
+	onStart func(rl *ratelimiter.RateLimiter[string]),
+) error {
+	if len(names) == 0 {
+		return nil
+	}
+	rl := ratelimiter.New[string](
+		m.deleteRateLimiterConfig.Frequency,
+		m.deleteRateLimiterConfig.Requests,
+		len(names),
+		executor.NewDeleter(resource, names, deleteFunc),
+		ratelimiter.WithConcurrency[string](min(m.deleteRateLimiterConfig.Requests, deleteConcurrency)),
+	)
+	if onStart != nil {
+		onStart(rl)
+	}
+	lastErr := runToCompletion(ctx, rl)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if metrics := rl.Metrics(); metrics.Failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d %s: %w", metrics.Failed, len(names), resource, lastErr)
+	}
+	return nil
+}
+
+// startDeletion records the progress of the previous attempt to delete the resources, if any, and starts a new attempt.
+func (m *Manager) startDeletion(resource string, listed int) *deletion {
+	m.deletionsMutex.Lock()
+	defer m.deletionsMutex.Unlock()
+	d, ok := m.deletions[resource]
+	if !ok {
+		d = &deletion{}
+		m.deletions[resource] = d
+	}
+	d.previous = d.progress()
+	d.listed = listed
+	d.rateLimiter = nil
+	return d
+}
+
+// runToCompletion runs the rate limiter until it reaches its limit or the context is done and returns the last error
+// of its work items, if any.
+func runToCompletion[T any](ctx context.Context, rl *ratelimiter.RateLimiter[T]) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rl.Run(ctx)
+	}()
+	var lastErr error
+	for {
+		select {
+		case err := <-rl.ErrChan():
+			lastErr = err
+		case <-done:
+			return lastErr
+		}
+	}
+}
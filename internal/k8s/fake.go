@@ -0,0 +1,318 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	batchv1client "k8s.io/client-go/kubernetes/typed/batch/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/dejanzele/batch-simulator/internal/fault"
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// FaultConfig configures the faults injected into write requests of a fake clientset.
+type FaultConfig struct {
+	// Latency is the mean latency of write requests, every request waits between half and one and a half of it.
+	Latency time.Duration
+	// ErrorRate is the fraction of write requests, between 0 and 1, which fail with a TooManyRequests error.
+	ErrorRate float64
+}
+
+// faults returns the faults of the config, they are injected into every write request.
+func (c FaultConfig) faults() []fault.Fault {
+	var faults []fault.Fault
+	if c.Latency > 0 {
+		faults = append(faults, fault.Fault{Verb: "*", Resource: "*", Latency: c.Latency, Jitter: 0.5})
+	}
+	if c.ErrorRate > 0 {
+		faults = append(faults, fault.Fault{Verb: "*", Resource: "*", Err: fault.TooManyRequests(), Probability: c.ErrorRate})
+	}
+	return faults
+}
+
+// fakeWatchBuffer is the number of events buffered for every watch of a fake clientset.
+const fakeWatchBuffer = 1000
+
+// clearActionsEvery is the number of write requests after which the actions recorded by the fake clientset are cleared,
+// as they would otherwise grow without bounds during long simulations.
+const clearActionsEvery = 1000
+
+// NewFakeClient creates an in-memory clientset backed by the client-go object tracker.
+// Creates, updates and deletes of pods, nodes and jobs are delayed and failed as configured by faults,
+// outside the lock of the fake clientset so concurrent requests are not serialized by the injected latency.
+// Latencies and errors are drawn from a source forked from util.Default().
+func NewFakeClient(faults FaultConfig, objects ...runtime.Object) kubernetes.Interface {
+	clientset := fake.NewSimpleClientset(objects...)
+	tracker := &watchingTracker{ObjectTracker: clientset.Tracker()}
+	clientset.PrependReactor("*", "*", k8stesting.ObjectReaction(tracker))
+	clientset.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := tracker.Watch(action.GetResource(), action.GetNamespace())
+		return true, w, err
+	})
+	injector := fault.NewInjector(util.Default().Fork("faults"), faults.faults()...)
+	return &faultyClientset{Clientset: clientset, faults: &faultInjector{injector: injector, clientset: clientset}}
+}
+
+// NewFakeDynamicClient creates an in-memory dynamic client which serves objects of the resources,
+// listKinds maps every resource to the kind of its list (e.g. PodGroupList). Faults are not injected into its requests.
+func NewFakeDynamicClient(listKinds map[schema.GroupVersionResource]string) dynamic.Interface {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+}
+
+// watchingTracker serves watches of an object tracker with its own watchers.
+// The watchers of the client-go object tracker panic once they fall more than 100 events behind,
+// which happens under high create rates, while these block the writer until the event is consumed.
+type watchingTracker struct {
+	k8stesting.ObjectTracker
+	watchers []*trackerWatcher
+	mutex    sync.Mutex
+}
+
+type trackerWatcher struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	proxy     *watch.ProxyWatcher
+	events    chan watch.Event
+}
+
+// Create sets the UID and creation timestamp like the API server does, as trackers key objects by UID.
+func (t *watchingTracker) Create(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		if accessor.GetUID() == "" {
+			accessor.SetUID(uuid.NewUUID())
+		}
+		if accessor.GetCreationTimestamp().Time.IsZero() {
+			accessor.SetCreationTimestamp(metav1.Now())
+		}
+	}
+	if err := t.ObjectTracker.Create(gvr, obj, ns); err != nil {
+		return err
+	}
+	t.notify(gvr, ns, watch.Added, obj)
+	return nil
+}
+
+func (t *watchingTracker) Update(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
+	if err := t.ObjectTracker.Update(gvr, obj, ns); err != nil {
+		return err
+	}
+	t.notify(gvr, ns, watch.Modified, obj)
+	return nil
+}
+
+func (t *watchingTracker) Delete(gvr schema.GroupVersionResource, ns, name string) error {
+	obj, err := t.ObjectTracker.Get(gvr, ns, name)
+	if err != nil {
+		return err
+	}
+	if err := t.ObjectTracker.Delete(gvr, ns, name); err != nil {
+		return err
+	}
+	t.notify(gvr, ns, watch.Deleted, obj)
+	return nil
+}
+
+// Watch returns a watch of the objects of the resource in the namespace, or in all namespaces if it is empty.
+func (t *watchingTracker) Watch(gvr schema.GroupVersionResource, ns string) (watch.Interface, error) {
+	events := make(chan watch.Event, fakeWatchBuffer)
+	w := &trackerWatcher{gvr: gvr, namespace: ns, proxy: watch.NewProxyWatcher(events), events: events}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.watchers = append(t.watchers, w)
+	return w.proxy, nil
+}
+
+// notify sends the event to every watcher of the resource, it removes stopped watchers.
+func (t *watchingTracker) notify(gvr schema.GroupVersionResource, ns string, eventType watch.EventType, obj runtime.Object) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	active := t.watchers[:0]
+	for _, w := range t.watchers {
+		select {
+		case <-w.proxy.StopChan():
+			continue
+		default:
+		}
+		active = append(active, w)
+		if w.gvr != gvr || (w.namespace != "" && w.namespace != ns) {
+			continue
+		}
+		select {
+		case w.events <- watch.Event{Type: eventType, Object: obj.DeepCopyObject()}:
+		case <-w.proxy.StopChan():
+		}
+	}
+	t.watchers = active
+}
+
+// faultInjector delays and fails write requests with the faults of the injector.
+type faultInjector struct {
+	injector  *fault.Injector
+	clientset *fake.Clientset
+	requests  atomic.Int64
+}
+
+// inject waits for the injected latency and returns the injected error, if any.
+func (f *faultInjector) inject(ctx context.Context, verb, resource string) error {
+	if f.requests.Add(1)%clearActionsEvery == 0 {
+		f.clientset.ClearActions()
+	}
+	return f.injector.Inject(ctx, verb, resource)
+}
+
+// SchedulesPods returns false if the client is an in-memory clientset created by NewFakeClient,
+// nothing schedules its pods so they stay pending and never settle.
+func SchedulesPods(client kubernetes.Interface) bool {
+	_, offline := client.(*faultyClientset)
+	return !offline
+}
+
+type faultyClientset struct {
+	*fake.Clientset
+	faults *faultInjector
+}
+
+func (c *faultyClientset) CoreV1() corev1client.CoreV1Interface {
+	return &faultyCoreV1{CoreV1Interface: c.Clientset.CoreV1(), faults: c.faults}
+}
+
+func (c *faultyClientset) BatchV1() batchv1client.BatchV1Interface {
+	return &faultyBatchV1{BatchV1Interface: c.Clientset.BatchV1(), faults: c.faults}
+}
+
+type faultyCoreV1 struct {
+	corev1client.CoreV1Interface
+	faults *faultInjector
+}
+
+func (c *faultyCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &faultyPods{PodInterface: c.CoreV1Interface.Pods(namespace), faults: c.faults}
+}
+
+func (c *faultyCoreV1) Nodes() corev1client.NodeInterface {
+	return &faultyNodes{NodeInterface: c.CoreV1Interface.Nodes(), faults: c.faults}
+}
+
+type faultyBatchV1 struct {
+	batchv1client.BatchV1Interface
+	faults *faultInjector
+}
+
+func (c *faultyBatchV1) Jobs(namespace string) batchv1client.JobInterface {
+	return &faultyJobs{JobInterface: c.BatchV1Interface.Jobs(namespace), faults: c.faults}
+}
+
+type faultyPods struct {
+	corev1client.PodInterface
+	faults *faultInjector
+}
+
+func (c *faultyPods) Create(ctx context.Context, pod *corev1.Pod, opts metav1.CreateOptions) (*corev1.Pod, error) {
+	if err := c.faults.inject(ctx, "create", "pods"); err != nil {
+		return nil, err
+	}
+	return c.PodInterface.Create(ctx, pod, opts)
+}
+
+func (c *faultyPods) Update(ctx context.Context, pod *corev1.Pod, opts metav1.UpdateOptions) (*corev1.Pod, error) {
+	if err := c.faults.inject(ctx, "update", "pods"); err != nil {
+		return nil, err
+	}
+	return c.PodInterface.Update(ctx, pod, opts)
+}
+
+func (c *faultyPods) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := c.faults.inject(ctx, "delete", "pods"); err != nil {
+		return err
+	}
+	return c.PodInterface.Delete(ctx, name, opts)
+}
+
+func (c *faultyPods) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	if err := c.faults.inject(ctx, "delete-collection", "pods"); err != nil {
+		return err
+	}
+	return c.PodInterface.DeleteCollection(ctx, opts, listOpts)
+}
+
+type faultyNodes struct {
+	corev1client.NodeInterface
+	faults *faultInjector
+}
+
+func (c *faultyNodes) Create(ctx context.Context, node *corev1.Node, opts metav1.CreateOptions) (*corev1.Node, error) {
+	if err := c.faults.inject(ctx, "create", "nodes"); err != nil {
+		return nil, err
+	}
+	return c.NodeInterface.Create(ctx, node, opts)
+}
+
+func (c *faultyNodes) Update(ctx context.Context, node *corev1.Node, opts metav1.UpdateOptions) (*corev1.Node, error) {
+	if err := c.faults.inject(ctx, "update", "nodes"); err != nil {
+		return nil, err
+	}
+	return c.NodeInterface.Update(ctx, node, opts)
+}
+
+func (c *faultyNodes) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := c.faults.inject(ctx, "delete", "nodes"); err != nil {
+		return err
+	}
+	return c.NodeInterface.Delete(ctx, name, opts)
+}
+
+func (c *faultyNodes) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	if err := c.faults.inject(ctx, "delete-collection", "nodes"); err != nil {
+		return err
+	}
+	return c.NodeInterface.DeleteCollection(ctx, opts, listOpts)
+}
+
+type faultyJobs struct {
+	batchv1client.JobInterface
+	faults *faultInjector
+}
+
+func (c *faultyJobs) Create(ctx context.Context, job *batchv1.Job, opts metav1.CreateOptions) (*batchv1.Job, error) {
+	if err := c.faults.inject(ctx, "create", "jobs"); err != nil {
+		return nil, err
+	}
+	return c.JobInterface.Create(ctx, job, opts)
+}
+
+func (c *faultyJobs) Update(ctx context.Context, job *batchv1.Job, opts metav1.UpdateOptions) (*batchv1.Job, error) {
+	if err := c.faults.inject(ctx, "update", "jobs"); err != nil {
+		return nil, err
+	}
+	return c.JobInterface.Update(ctx, job, opts)
+}
+
+func (c *faultyJobs) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := c.faults.inject(ctx, "delete", "jobs"); err != nil {
+		return err
+	}
+	return c.JobInterface.Delete(ctx, name, opts)
+}
+
+func (c *faultyJobs) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	if err := c.faults.inject(ctx, "delete-collection", "jobs"); err != nil {
+		return err
+	}
+	return c.JobInterface.DeleteCollection(ctx, opts, listOpts)
+}
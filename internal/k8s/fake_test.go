@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewFakeClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("injected errors fail write requests", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewFakeClient(FaultConfig{ErrorRate: 1})
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+		_, err := client.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+		assert.True(t, apierrors.IsTooManyRequests(err))
+		_, err = client.CoreV1().Nodes().Create(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}, metav1.CreateOptions{})
+		assert.True(t, apierrors.IsTooManyRequests(err))
+
+		pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err, "read requests are not failed")
+		assert.Empty(t, pods.Items)
+	})
+
+	t.Run("injected latency does not serialize concurrent requests", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewFakeClient(FaultConfig{Latency: 100 * time.Millisecond})
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-" + string(rune('a'+i))}}
+				_, err := client.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+		elapsed := time.Since(start)
+		assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+		assert.Less(t, elapsed, time.Second)
+
+		nodes, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, nodes.Items, 20)
+		for _, node := range nodes.Items {
+			assert.NotEmpty(t, node.UID)
+			assert.False(t, node.CreationTimestamp.IsZero())
+		}
+	})
+
+	t.Run("injected latency respects context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewFakeClient(FaultConfig{Latency: time.Minute})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := client.BatchV1().Jobs("default").Delete(ctx, "job", metav1.DeleteOptions{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("informers receive bursts of events", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client := NewFakeClient(FaultConfig{})
+		factory := informers.NewSharedInformerFactory(client, 0)
+		informer := factory.Core().V1().Pods().Informer()
+		var added atomic.Int64
+		_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(any) { added.Add(1) },
+		})
+		assert.NoError(t, err)
+		factory.Start(ctx.Done())
+		assert.True(t, cache.WaitForCacheSync(ctx.Done(), informer.HasSynced))
+
+		for i := 0; i < 1000; i++ {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-" + time.Duration(i).String(), Namespace: "default"}}
+			_, err := client.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool { return added.Load() == 1000 }, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSchedulesPods(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, SchedulesPods(NewFakeClient(FaultConfig{})))
+	assert.True(t, SchedulesPods(fake.NewSimpleClientset()))
+}
@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+// removeFinalizersPatch is a merge patch which removes all finalizers of an object.
+var removeFinalizersPatch = []byte(`{"metadata":{"finalizers":null}}`)
+
+// ForceCleanOptions configures ForceClean.
+type ForceCleanOptions struct {
+	// RunID limits ForceClean to the resources of the run, resources of all runs are removed if empty.
+	RunID string
+	// SimulatorNamespace is the namespace of the simulator jobs of remote runs.
+	SimulatorNamespace string
+	// SimulatorLabelSelector selects the simulator jobs of remote runs, they are not removed if empty.
+	SimulatorLabelSelector string
+}
+
+// ForceCleanReport lists the namespace/name keys of the resources which were found and removed by ForceClean.
+type ForceCleanReport struct {
+	// TerminatingPods are fake pods which were stuck terminating on fake nodes.
+	TerminatingPods []string `json:"terminatingPods"`
+	// OrphanedPods are fake pods which were bound to nodes which do not exist.
+	OrphanedPods []string `json:"orphanedPods"`
+	// OrphanedJobs are fake jobs which had no pods.
+	OrphanedJobs []string `json:"orphanedJobs"`
+	// SimulatorJobs are leftover simulator jobs of remote runs.
+	SimulatorJobs []string `json:"simulatorJobs"`
+}
+
+// Total returns the number of removed resources.
+func (r *ForceCleanReport) Total() int {
+	return len(r.TerminatingPods) + len(r.OrphanedPods) + len(r.OrphanedJobs) + len(r.SimulatorJobs)
+}
+
+// ForceClean removes the resources which a regular deletion cannot remove, e.g. when nodes were deleted before their pods
+// or KWOK is not running:
+//   - fake pods which are terminating on fake nodes have their finalizers removed and are deleted with a grace period of 0
+//   - fake pods bound to nodes which do not exist are removed the same way
+//   - fake jobs which have no pods are deleted
+//   - simulator jobs of remote runs are deleted
+//
+// Fake pods and jobs are found in the namespace of the Manager and in the generated namespaces, see cleanedNamespaces.
+// Resources are removed at the rate of the deletion rate limiter. If an error is returned, some of the reported
+// resources may not have been removed.
+func (m *Manager) ForceClean(ctx context.Context, opts ForceCleanOptions) (*ForceCleanReport, error) {
+	report := &ForceCleanReport{}
+
+	fakeNodeSelector, err := labels.Parse(resources.LabelSelectorFakeNode)
+	if err != nil {
+		return nil, err
+	}
+	// nodes holds whether every existing node is a fake node
+	nodes := make(map[string]bool)
+	err = forEachObject(ctx, nodeLister(m.client), "", func(obj metav1.Object) {
+		nodes[obj.GetName()] = fakeNodeSelector.Matches(labels.Set(obj.GetLabels()))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	namespaces, err := m.cleanedNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// only pods which are not removed count as pods of their job, so jobs whose pods are all removed are removed as well,
+	// jobsWithPods is keyed by the namespace/name key of the jobs
+	jobsWithPods := make(map[string]bool)
+	for _, namespace := range namespaces {
+		err = forEachObject(ctx, podLister(m.client, namespace), resources.RunSelector(resources.LabelSelectorFakePod, opts.RunID), func(obj metav1.Object) {
+			pod := obj.(*corev1.Pod)
+			isFakeNode, nodeExists := nodes[pod.Spec.NodeName]
+			switch {
+			case pod.Spec.NodeName != "" && !nodeExists:
+				report.OrphanedPods = append(report.OrphanedPods, cache.MetaObjectToName(pod).String())
+			case pod.DeletionTimestamp != nil && (pod.Spec.NodeName == "" || isFakeNode):
+				report.TerminatingPods = append(report.TerminatingPods, cache.MetaObjectToName(pod).String())
+			default:
+				for _, owner := range pod.OwnerReferences {
+					if owner.Kind == "Job" {
+						jobsWithPods[cache.NewObjectName(pod.Namespace, owner.Name).String()] = true
+					}
+				}
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		err = forEachObject(ctx, jobLister(m.client, namespace), resources.RunSelector(resources.LabelSelectorFakeJob, opts.RunID), func(obj metav1.Object) {
+			if key := cache.MetaObjectToName(obj).String(); !jobsWithPods[key] {
+				report.OrphanedJobs = append(report.OrphanedJobs, key)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs in namespace %s: %w", namespace, err)
+		}
+	}
+
+	if opts.SimulatorLabelSelector != "" {
+		err = forEachObject(ctx, jobLister(m.client, opts.SimulatorNamespace), opts.SimulatorLabelSelector, func(obj metav1.Object) {
+			if opts.RunID == "" || isSimulatorJobOfRun(obj, opts.RunID) {
+				report.SimulatorJobs = append(report.SimulatorJobs, cache.MetaObjectToName(obj).String())
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list simulator jobs: %w", err)
+		}
+	}
+
+	m.logger.Info(
+		"force cleaning resources",
+		"terminatingPods", len(report.TerminatingPods),
+		"orphanedPods", len(report.OrphanedPods),
+		"orphanedJobs", len(report.OrphanedJobs),
+		"simulatorJobs", len(report.SimulatorJobs),
+	)
+	deleteJob := func(ctx context.Context, namespace, name string) error {
+		deletePropagationBackground := metav1.DeletePropagationBackground
+		deleteOpts := metav1.DeleteOptions{PropagationPolicy: &deletePropagationBackground}
+		return m.client.BatchV1().Jobs(namespace).Delete(ctx, name, deleteOpts)
+	}
+	pods := append(append([]string{}, report.TerminatingPods...), report.OrphanedPods...)
+	err = errors.Join(
+		m.deleteNames(ctx, "pods", pods, byKey(m.forceDeletePod), nil),
+		m.deleteNames(ctx, "jobs", report.OrphanedJobs, byKey(deleteJob), nil),
+		m.deleteNames(ctx, "jobs", report.SimulatorJobs, byKey(deleteJob), nil),
+	)
+	return report, err
+}
+
+// forceDeletePod removes the finalizers of the pod and deletes it with a grace period of 0,
+// so the pod is removed without waiting for the kubelet (KWOK) to confirm its termination.
+func (m *Manager) forceDeletePod(ctx context.Context, namespace, name string) error {
+	pods := m.client.CoreV1().Pods(namespace)
+	if _, err := pods.Patch(ctx, name, types.MergePatchType, removeFinalizersPatch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	return pods.Delete(ctx, name, metav1.DeleteOptions{GracePeriodSeconds: ptr.To[int64](0)})
+}
+
+// isSimulatorJobOfRun returns true if the simulator job runs the simulation with the run ID.
+func isSimulatorJobOfRun(obj metav1.Object, runID string) bool {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return false
+	}
+	for _, container := range job.Spec.Template.Spec.Containers {
+		for i := 0; i+1 < len(container.Args); i++ {
+			if container.Args[i] == "--run-id" && container.Args[i+1] == runID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/fault"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestManager_ForceClean(t *testing.T) {
+	t.Parallel()
+
+	now := metav1.Now()
+	fakePod := func(name, nodeName, runID string, terminating bool, ownerJob string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": "fake-pod", "run-id": runID},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+		if terminating {
+			pod.DeletionTimestamp = &now
+			pod.Finalizers = []string{"batch-simulator/test"}
+		}
+		if ownerJob != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: ownerJob}}
+		}
+		return pod
+	}
+	fakeJob := func(name, runID string) *batchv1.Job {
+		return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "fake-job", "run-id": runID},
+		}}
+	}
+	simulatorJob := func(name, runID string) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "simulator", Labels: map[string]string{"app": "simulator"}},
+			Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "simulator", Args: []string{"run", "--run-id", runID}}},
+			}}},
+		}
+	}
+	inGeneratedNamespace := func(obj metav1.Object) runtime.Object {
+		obj.SetNamespace("default-1")
+		return obj.(runtime.Object)
+	}
+	objects := []runtime.Object{
+		resources.NewFakeNamespace("default-1"),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake-node-1", Labels: map[string]string{"type": "kwok"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "real-node"}},
+		fakePod("stuck", "fake-node-1", "r1", true, ""),
+		fakePod("orphan", "deleted-node", "r1", false, ""),
+		fakePod("healthy", "fake-node-1", "r1", false, "job-1"),
+		fakePod("terminating-on-real-node", "real-node", "r1", true, ""),
+		fakePod("other-run-orphan", "deleted-node", "r2", false, ""),
+		fakeJob("job-1", "r1"),
+		fakeJob("job-2", "r1"),
+		fakeJob("other-run-job", "r2"),
+		inGeneratedNamespace(fakePod("generated-orphan", "deleted-node", "r1", false, "")),
+		inGeneratedNamespace(fakePod("generated-healthy", "fake-node-1", "r1", false, "job-1")),
+		inGeneratedNamespace(fakeJob("job-1", "r1")),
+		inGeneratedNamespace(fakeJob("generated-job", "r1")),
+		simulatorJob("simulator-job-1", "r1"),
+		simulatorJob("simulator-job-2", "r2"),
+	}
+	opts := ForceCleanOptions{SimulatorNamespace: "simulator", SimulatorLabelSelector: "app=simulator"}
+	deleteConfig := RateLimiterConfig{Frequency: time.Millisecond, Requests: 10}
+
+	t.Run("stuck and orphaned resources of all runs are removed", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		faults := fault.Inject(fakeClient)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		report, err := manager.ForceClean(context.Background(), opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"default/stuck"}, report.TerminatingPods)
+		assert.ElementsMatch(t, []string{"default/orphan", "default/other-run-orphan", "default-1/generated-orphan"}, report.OrphanedPods)
+		assert.ElementsMatch(t, []string{"default/job-2", "default/other-run-job", "default-1/generated-job"}, report.OrphanedJobs)
+		assert.ElementsMatch(t, []string{"simulator/simulator-job-1", "simulator/simulator-job-2"}, report.SimulatorJobs)
+		assert.Equal(t, 9, report.Total())
+		assert.Equal(t, 4, faults.Requests("patch", "pods"), "finalizers of removed pods must be stripped")
+
+		podList, err := fakeClient.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		var pods []string
+		for _, pod := range podList.Items {
+			pods = append(pods, pod.Name)
+		}
+		assert.ElementsMatch(t, []string{"healthy", "terminating-on-real-node"}, pods)
+		podList, err = fakeClient.CoreV1().Pods("default-1").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, podList.Items, 1)
+		assert.Equal(t, "generated-healthy", podList.Items[0].Name)
+		jobList, err := fakeClient.BatchV1().Jobs("").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		var jobs []string
+		for _, job := range jobList.Items {
+			jobs = append(jobs, job.Namespace+"/"+job.Name)
+		}
+		assert.ElementsMatch(t, []string{"default/job-1", "default-1/job-1"}, jobs)
+	})
+
+	t.Run("only resources of the run are removed", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		runOpts := opts
+		runOpts.RunID = "r1"
+		report, err := manager.ForceClean(context.Background(), runOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"default/stuck"}, report.TerminatingPods)
+		assert.ElementsMatch(t, []string{"default/orphan", "default-1/generated-orphan"}, report.OrphanedPods)
+		assert.ElementsMatch(t, []string{"default/job-2", "default-1/generated-job"}, report.OrphanedJobs)
+		assert.Equal(t, []string{"simulator/simulator-job-1"}, report.SimulatorJobs)
+	})
+
+	t.Run("failed removals are reported", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		fault.Inject(fakeClient, fault.Fault{Verb: "patch", Resource: "pods", Err: fault.InternalError()})
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		report, err := manager.ForceClean(context.Background(), opts)
+		assert.ErrorContains(t, err, "failed to delete 4 of 4 pods")
+		assert.Len(t, report.TerminatingPods, 1)
+	})
+}
@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+// GangsConfig is used to configure the creation of gangs, which are scheduled all-or-nothing by a gang scheduler.
+type GangsConfig struct {
+	// Style is the gang scheduler whose PodGroups are created, e.g. coscheduling or volcano.
+	Style resources.GangStyle
+	// SchedulerName is the scheduler of the member pods, the default scheduler of the style if empty.
+	SchedulerName string
+	// Sizes is the distribution of the number of member pods of a gang.
+	Sizes []resources.GangSize
+	// RateLimiterConfig is the configuration for the rate limited GangCreator, its limit is the number of gangs.
+	RateLimiterConfig RateLimiterConfig
+}
+
+// addGangsWorkload creates the rate limited GangCreator of the gangs config and runs it as a workload.
+// Gangs are created in the namespace of the Manager or spread across the namespaces of the spread like pods and jobs.
+func (m *Manager) addGangsWorkload(cfg *ManagerConfig) {
+	gangs := cfg.Gangs
+	if cfg.Naming == executor.NamingSequential {
+		m.gangNamer = executor.NewSequentialNamer(executor.GangNamePrefix, cfg.RunID)
+	} else {
+		m.gangNamer = executor.NewRandomNamer(executor.GangNamePrefix, cfg.Rand.Fork("gang-names"))
+	}
+	gangExecutor := executor.NewGangCreator(
+		m.client,
+		cfg.DynamicClient,
+		cfg.Namespace,
+		gangs.Style,
+		gangs.SchedulerName,
+		gangs.Sizes,
+		cfg.RandomEnvVars,
+		cfg.Rand.Fork("gangs"),
+		m.gangNamer,
+		spreadOptions(m.client, cfg, "gang-namespaces")...,
+	)
+	m.rateLimitedGangCreator = ratelimiter.New[*unstructured.Unstructured](
+		gangs.RateLimiterConfig.Frequency,
+		gangs.RateLimiterConfig.Requests,
+		gangs.RateLimiterConfig.Limit,
+		gangExecutor,
+		ratelimiter.WithObserver[*unstructured.Unstructured](cfg.Observer),
+	)
+	m.workloads = append(m.workloads, Workload{
+		Name:             gangsWorkloadName,
+		RateLimiter:      m.rateLimitedGangCreator,
+		NamespaceMetrics: gangExecutor.NamespaceMetrics,
+	})
+}
+
+// DeleteGangs deletes the PodGroups of the gang style having provided label in the namespace of the Manager and in the
+// generated namespaces, their member pods are deleted by the garbage collector or with the fake pods.
+// If async is set to false, this function will block until the PodGroups are terminated or context exceeds deadline.
+func (m *Manager) DeleteGangs(ctx context.Context, style resources.GangStyle, labelSelector string, async bool) error {
+	return m.DeleteObjects(ctx, style.PodGroupResource(), false, labelSelector, async)
+}
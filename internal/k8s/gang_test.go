@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestManager_Gangs(t *testing.T) {
+	t.Parallel()
+
+	podGroups := resources.GangStyleCoscheduling.PodGroupResource()
+	dynamicClient := NewFakeDynamicClient(map[schema.GroupVersionResource]string{podGroups: "PodGroupList"})
+	client := fake.NewSimpleClientset()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// the interrupted run created the gang with sequence number 0
+	existing := resources.NewFakePodGroup(resources.GangStyleCoscheduling, "fake-gang-run-1-0", "default", 2)
+	existing.SetLabels(map[string]string{resources.LabelKeyApp: resources.LabelValueFakePodGroup, resources.LabelKeyRunID: "run-1"})
+	_, err := dynamicClient.Resource(podGroups).Namespace("default").Create(ctx, existing, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	rateLimiterConfig := func(limit int) RateLimiterConfig {
+		return RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 1, Limit: limit}
+	}
+	manager := NewManager(client, &ManagerConfig{
+		RunID:                   "run-1",
+		Naming:                  executor.NamingSequential,
+		NodeRateLimiterConfig:   rateLimiterConfig(0),
+		PodRateLimiterConfig:    rateLimiterConfig(0),
+		JobRateLimiterConfig:    rateLimiterConfig(0),
+		DeleteRateLimiterConfig: RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 10},
+		DynamicClient:           dynamicClient,
+		Gangs: &GangsConfig{
+			Style:             resources.GangStyleCoscheduling,
+			Sizes:             []resources.GangSize{{Size: 2}},
+			RateLimiterConfig: rateLimiterConfig(3),
+		},
+	})
+	if !assert.Len(t, manager.Workloads(), 1) {
+		t.FailNow()
+	}
+	_, pods, _, err := manager.Resume(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, pods, "member pods are not counted as standalone pods")
+	assert.Equal(t, 1, manager.Workloads()[0].RateLimiter.Resumed())
+	assert.NoError(t, manager.Start(ctx))
+
+	list, err := dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakePodGroup})
+	assert.NoError(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	assert.ElementsMatch(t, []string{"fake-gang-run-1-0", "fake-gang-run-1-1", "fake-gang-run-1-2"}, names)
+	memberPods, err := client.CoreV1().Pods("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeGangPod})
+	assert.NoError(t, err)
+	assert.Len(t, memberPods.Items, 4)
+	assert.Equal(t, 2, manager.Summaries()["kubernetes-gang-creator"].Succeeded)
+
+	assert.NoError(t, manager.DeleteGangs(ctx, resources.GangStyleCoscheduling, resources.LabelSelectorFakePodGroup, true))
+	list, err = dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestManager_DeleteGangsInGeneratedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	podGroups := resources.GangStyleCoscheduling.PodGroupResource()
+	dynamicClient := NewFakeDynamicClient(map[schema.GroupVersionResource]string{podGroups: "PodGroupList"})
+	client := fake.NewSimpleClientset(resources.NewFakeNamespace("default-1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, gang := range []struct{ name, namespace, runID string }{
+		{"gang-a", "default", "run-1"},
+		{"gang-b", "default-1", "run-1"},
+		{"gang-c", "default-1", "run-2"},
+	} {
+		podGroup := resources.NewFakePodGroup(resources.GangStyleCoscheduling, gang.name, gang.namespace, 2)
+		podGroup.SetLabels(map[string]string{resources.LabelKeyApp: resources.LabelValueFakePodGroup, resources.LabelKeyRunID: gang.runID})
+		_, err := dynamicClient.Resource(podGroups).Namespace(gang.namespace).Create(ctx, podGroup, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	manager := NewManager(client, &ManagerConfig{
+		DeleteRateLimiterConfig: RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 10},
+		DynamicClient:           dynamicClient,
+	})
+
+	selector := resources.RunSelector(resources.LabelSelectorFakePodGroup, "run-1")
+	assert.NoError(t, manager.DeleteGangs(ctx, resources.GangStyleCoscheduling, selector, true))
+	list, err := dynamicClient.Resource(podGroups).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, list.Items, 1) {
+		assert.Equal(t, "gang-c", list.Items[0].GetName(), "only the PodGroups of the run must be deleted")
+	}
+}
@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+// KueueConfig is used to configure the Kueue queues to which the jobs of the Manager are submitted.
+type KueueConfig struct {
+	// ResourceFlavor is the name of the ResourceFlavor which matches the fake nodes.
+	ResourceFlavor string
+	// ClusterQueue is the name of the ClusterQueue which admits the jobs.
+	ClusterQueue string
+	// LocalQueue is the name of the LocalQueue created in every namespace in which jobs are created.
+	LocalQueue string
+	// Quota is the nominal quota of the ClusterQueue, e.g. the resources of the simulated node pool.
+	Quota corev1.ResourceList
+}
+
+// kueueOptions returns the executor options which create suspended jobs submitted to the LocalQueue of the Kueue config.
+func kueueOptions(cfg *ManagerConfig) []executor.Option {
+	if cfg.Kueue == nil {
+		return nil
+	}
+	return []executor.Option{
+		executor.WithTemplate(&resources.Template{
+			Suspend: true,
+			Labels:  map[string]string{resources.LabelKeyQueueName: cfg.Kueue.LocalQueue},
+		}),
+	}
+}
+
+// SetupKueue creates or updates the ResourceFlavor, the ClusterQueue and a LocalQueue in every namespace
+// in which jobs are created, the namespaces are created if they do not exist. It must be called before Start.
+func (m *Manager) SetupKueue(ctx context.Context) error {
+	if m.kueue == nil {
+		return fmt.Errorf("kueue is not configured")
+	}
+	if m.dynamicClient == nil {
+		return fmt.Errorf("setting up kueue requires a dynamic client")
+	}
+	flavor := resources.NewFakeResourceFlavor(m.kueue.ResourceFlavor)
+	if err := applyObject(ctx, m.dynamicClient.Resource(resources.ResourceFlavors), flavor); err != nil {
+		return fmt.Errorf("failed to create resource flavor %s: %w", flavor.GetName(), err)
+	}
+	clusterQueue := resources.NewFakeClusterQueue(m.kueue.ClusterQueue, m.kueue.ResourceFlavor, m.kueue.Quota)
+	if err := applyObject(ctx, m.dynamicClient.Resource(resources.ClusterQueues), clusterQueue); err != nil {
+		return fmt.Errorf("failed to create cluster queue %s: %w", clusterQueue.GetName(), err)
+	}
+	for _, namespace := range m.namespaces {
+		if m.spread {
+			if err := CreateFakeNamespaceIfNeed(ctx, m.client, namespace, m.logger); err != nil {
+				return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+			}
+		}
+		localQueue := resources.NewFakeLocalQueue(m.kueue.LocalQueue, namespace, m.kueue.ClusterQueue)
+		if err := applyObject(ctx, m.dynamicClient.Resource(resources.LocalQueues).Namespace(namespace), localQueue); err != nil {
+			return fmt.Errorf("failed to create local queue %s/%s: %w", namespace, localQueue.GetName(), err)
+		}
+	}
+	m.logger.Info(
+		"kueue set up",
+		"resourceFlavor", m.kueue.ResourceFlavor,
+		"clusterQueue", m.kueue.ClusterQueue,
+		"localQueue", m.kueue.LocalQueue,
+		"namespaces", len(m.namespaces),
+	)
+	return nil
+}
+
+// applyObject creates the object or updates it if it already exists, e.g. when a run is repeated with a different quota.
+func applyObject(ctx context.Context, client dynamic.ResourceInterface, object *unstructured.Unstructured) error {
+	_, err := client.Create(ctx, object, metav1.CreateOptions{})
+	if !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := client.Get(ctx, object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	object.SetResourceVersion(existing.GetResourceVersion())
+	_, err = client.Update(ctx, object, metav1.UpdateOptions{})
+	return err
+}
+
+// DeleteQueues deletes the LocalQueues, ClusterQueues and ResourceFlavors having provided label, in that order,
+// as Kueue keeps ClusterQueues and ResourceFlavors which are in use. LocalQueues are deleted in the namespace of the Manager
+// and in every generated namespace, in which SetupKueue created them.
+// If async is set to false, this function will block until the objects are terminated or context exceeds deadline.
+func (m *Manager) DeleteQueues(ctx context.Context, labelSelector string, async bool) error {
+	if err := m.DeleteObjects(ctx, resources.LocalQueues, false, labelSelector, async); err != nil {
+		return err
+	}
+	if err := m.DeleteObjects(ctx, resources.ClusterQueues, true, labelSelector, async); err != nil {
+		return err
+	}
+	return m.DeleteObjects(ctx, resources.ResourceFlavors, true, labelSelector, async)
+}
@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestManager_Kueue(t *testing.T) {
+	t.Parallel()
+
+	dynamicClient := NewFakeDynamicClient(map[schema.GroupVersionResource]string{
+		resources.ResourceFlavors: "ResourceFlavorList",
+		resources.ClusterQueues:   "ClusterQueueList",
+		resources.LocalQueues:     "LocalQueueList",
+	})
+	client := fake.NewSimpleClientset()
+	rateLimiterConfig := func(limit int) RateLimiterConfig {
+		return RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 1, Limit: limit}
+	}
+	newManager := func(cpu string) *Manager {
+		return NewManager(client, &ManagerConfig{
+			NodeRateLimiterConfig:   rateLimiterConfig(0),
+			PodRateLimiterConfig:    rateLimiterConfig(0),
+			JobRateLimiterConfig:    rateLimiterConfig(2),
+			DeleteRateLimiterConfig: RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 10},
+			Spread:                  executor.Spread{Namespaces: []string{"team-a", "team-b"}},
+			DynamicClient:           dynamicClient,
+			Kueue: &KueueConfig{
+				ResourceFlavor: "kwok",
+				ClusterQueue:   "simulator",
+				LocalQueue:     "queue",
+				Quota:          corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			},
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, newManager("10").SetupKueue(ctx))
+	// repeated runs update the queues
+	manager := newManager("20")
+	assert.NoError(t, manager.SetupKueue(ctx))
+
+	clusterQueue, err := dynamicClient.Resource(resources.ClusterQueues).Get(ctx, "simulator", metav1.GetOptions{})
+	assert.NoError(t, err)
+	groups, _, _ := unstructured.NestedSlice(clusterQueue.Object, "spec", "resourceGroups")
+	assert.Contains(t, groups[0].(map[string]any)["flavors"].([]any)[0].(map[string]any)["resources"], map[string]any{"name": "cpu", "nominalQuota": "20"})
+	for _, namespace := range []string{"team-a", "team-b"} {
+		_, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		assert.NoError(t, err)
+		localQueue, err := dynamicClient.Resource(resources.LocalQueues).Namespace(namespace).Get(ctx, "queue", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, resources.LabelValueFakeQueue, localQueue.GetLabels()[resources.LabelKeyApp])
+	}
+
+	assert.NoError(t, manager.Start(ctx))
+	jobs, err := client.BatchV1().Jobs("").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeJob})
+	assert.NoError(t, err)
+	assert.Len(t, jobs.Items, 2)
+	for _, job := range jobs.Items {
+		assert.True(t, *job.Spec.Suspend)
+		assert.Equal(t, "queue", job.Labels[resources.LabelKeyQueueName])
+	}
+
+	// clean does not spread, the LocalQueues must be deleted in the generated namespaces of the run
+	cleaner := NewManager(client, &ManagerConfig{
+		DeleteRateLimiterConfig: RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 10},
+		DynamicClient:           dynamicClient,
+	})
+	assert.NoError(t, cleaner.DeleteQueues(ctx, resources.LabelSelectorFakeQueue, true))
+	for _, gvr := range []schema.GroupVersionResource{resources.LocalQueues, resources.ClusterQueues, resources.ResourceFlavors} {
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, list.Items, gvr.Resource)
+	}
+}
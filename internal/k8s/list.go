@@ -0,0 +1,118 @@
+package k8s
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// listPageSize is the number of objects fetched per list request when iterating over many objects.
+const listPageSize = 500
+
+// objectLister lists a page of objects and returns the continue token of the next page.
+type objectLister func(ctx context.Context, opts metav1.ListOptions) (objects []metav1.Object, next string, err error)
+
+// forEachObject calls f for every object matching the label selector, the objects are listed in pages.
+func forEachObject(ctx context.Context, list objectLister, labelSelector string, f func(metav1.Object)) error {
+	opts := metav1.ListOptions{LabelSelector: labelSelector, Limit: listPageSize}
+	for {
+		objects, next, err := list(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			f(obj)
+		}
+		if next == "" {
+			return nil
+		}
+		opts.Continue = next
+	}
+}
+
+func nodeLister(client kubernetes.Interface) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.CoreV1().Nodes().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.Continue, nil
+	}
+}
+
+func podLister(client kubernetes.Interface, namespace string) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.Continue, nil
+	}
+}
+
+func jobLister(client kubernetes.Interface, namespace string) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.BatchV1().Jobs(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.Continue, nil
+	}
+}
+
+func eventLister(client kubernetes.Interface, namespace string) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.CoreV1().Events(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.Continue, nil
+	}
+}
+
+func namespaceLister(client kubernetes.Interface) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.CoreV1().Namespaces().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.Continue, nil
+	}
+}
+
+func unstructuredLister(client dynamic.Interface, resource schema.GroupVersionResource, namespace string) objectLister {
+	return func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+		list, err := client.Resource(resource).Namespace(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		objects := make([]metav1.Object, 0, len(list.Items))
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		return objects, list.GetContinue(), nil
+	}
+}
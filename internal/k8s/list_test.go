@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestForEachObject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("objects are listed in pages", func(t *testing.T) {
+		t.Parallel()
+
+		var requests []metav1.ListOptions
+		list := func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+			requests = append(requests, opts)
+			if opts.Continue == "" {
+				return []metav1.Object{newPod("pod-1"), newPod("pod-2")}, "page-2", nil
+			}
+			return []metav1.Object{newPod("pod-3")}, "", nil
+		}
+
+		var names []string
+		err := forEachObject(context.Background(), list, "app=batch-simulator", func(obj metav1.Object) {
+			names = append(names, obj.GetName())
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"pod-1", "pod-2", "pod-3"}, names)
+		assert.Len(t, requests, 2)
+		for _, opts := range requests {
+			assert.Equal(t, int64(listPageSize), opts.Limit)
+			assert.Equal(t, "app=batch-simulator", opts.LabelSelector)
+		}
+		assert.Equal(t, "page-2", requests[1].Continue)
+	})
+
+	t.Run("list error is returned", func(t *testing.T) {
+		t.Parallel()
+
+		list := func(ctx context.Context, opts metav1.ListOptions) ([]metav1.Object, string, error) {
+			return nil, "", fmt.Errorf("list failed")
+		}
+		err := forEachObject(context.Background(), list, "", func(obj metav1.Object) {
+			t.Fatal("no object must be visited")
+		})
+		assert.EqualError(t, err, "list failed")
+	})
+}
+
+func newPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,12 +13,18 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 
 	"github.com/dejanzele/batch-simulator/cmd/simulator/config"
 	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
 	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
 )
 
 const (
@@ -28,16 +35,31 @@ const (
 	defaultNodeRateLimiterRequests  = 5
 	defaultJobRateLimiterFrequency  = 1 * time.Second
 	defaultJobRateLimiterRequests   = 5
-	defaultRetryCount               = 15
+	// objectsWorkloadName is the name of the workload which creates objects from a template.
+	objectsWorkloadName = "objects"
+	// gangsWorkloadName is the name of the workload which creates gangs of pods.
+	gangsWorkloadName = "gangs"
+	// defaultDeleteRateLimiterFrequency and defaultDeleteRateLimiterRequests bound deletion to 100 resources per second.
+	defaultDeleteRateLimiterFrequency = 1 * time.Second
+	defaultDeleteRateLimiterRequests  = 100
+	defaultRetryCount                 = 15
 )
 
 // Manager is used to manage Kubernetes resources.
 type Manager struct {
 	// client is the Kubernetes client that should be used by the Manager.
 	client kubernetes.Interface
+	// dynamicClient is the dynamic Kubernetes client used to create and delete objects of arbitrary kinds, it is optional.
+	dynamicClient dynamic.Interface
 	// namespace is the namespace in which resources should be created.
 	// If no namespace is provided, the default namespace will be used.
 	namespace string
+	// spread holds whether pods and jobs are spread across the namespaces of the spread instead of the namespace.
+	spread bool
+	// namespaces are the namespaces in which pods and jobs are created, the namespaces of the spread or the namespace.
+	namespaces []string
+	// kueue is the configuration of the Kueue queues to which jobs are submitted, it is nil if jobs are not queued.
+	kueue *KueueConfig
 	// logger is the logger that should be used by the Manager.
 	// If no logger is provided, a new logger will be created.
 	logger *slog.Logger
@@ -47,6 +69,35 @@ type Manager struct {
 	rateLimitedNodeCreator *ratelimiter.RateLimiter[*corev1.Node]
 	// rateLimitedJobCreator is the rate limiter that should be used for Job resources.
 	rateLimitedJobCreator *ratelimiter.RateLimiter[*batchv1.Job]
+	// podExecutor and jobExecutor are the executors of the pod and job rate limiters.
+	podExecutor *executor.PodCreator
+	jobExecutor *executor.JobCreator
+	// rateLimitedObjectCreator and objectNamer create the objects of the objects config, they are nil if it is not set.
+	rateLimitedObjectCreator *ratelimiter.RateLimiter[*unstructured.Unstructured]
+	objectNamer              executor.Namer
+	// objects is the configuration of the objects created from a template, it is nil if none are created.
+	objects *ObjectsConfig
+	// rateLimitedGangCreator and gangNamer create the gangs of the gangs config, they are nil if it is not set.
+	rateLimitedGangCreator *ratelimiter.RateLimiter[*unstructured.Unstructured]
+	gangNamer              executor.Namer
+	// gangs is the configuration of the created gangs, it is nil if no gangs are created.
+	gangs *GangsConfig
+	// workloads are the named workloads which run alongside the node, pod and job rate limiters.
+	workloads []Workload
+	// runners are the rate limiters of the node, pod and job creators followed by the rate limiters of the workloads.
+	runners []ratelimiter.Runner
+	// runID is the run ID of the created resources.
+	runID string
+	// nodeNamer, podNamer and jobNamer generate the names of created resources.
+	nodeNamer, podNamer, jobNamer executor.Namer
+	// deleteGracePeriod is the grace period of deleted pods, the default grace period of the pods is used if nil.
+	deleteGracePeriod *int64
+	// deleteRateLimiterConfig configures the rate at which resources are deleted.
+	deleteRateLimiterConfig RateLimiterConfig
+	// deletions tracks the progress of deleting resources keyed by resource (e.g. pods).
+	deletions map[string]*deletion
+	// deletionsMutex is used to synchronize access to the deletions.
+	deletionsMutex sync.Mutex
 }
 
 // ManagerConfig is used to configure a new Manager.
@@ -63,6 +114,60 @@ type ManagerConfig struct {
 	NodeRateLimiterConfig RateLimiterConfig
 	// JobRateLimiterConfig is the configuration for the rate limited JobCreator.
 	JobRateLimiterConfig RateLimiterConfig
+	// DeleteRateLimiterConfig is the configuration for the rate limited deletion of resources, Limit is ignored
+	// as every deletion is limited to the number of listed resources.
+	DeleteRateLimiterConfig RateLimiterConfig
+	// ForceDelete deletes pods with a grace period of 0, so they are removed without waiting for KWOK to terminate them.
+	ForceDelete bool
+	// Observer is notified about every processed work item of all rate limiters, it is optional.
+	Observer ratelimiter.Observer
+	// Rand is the source from which every executor forks its own source of names and random env vars.
+	// If no source is provided, util.Default() will be used.
+	Rand *util.Rand
+	// RunID is the run ID of the created resources, it is required by sequential naming and Resume.
+	RunID string
+	// Naming is the naming scheme of created resources, random if empty.
+	Naming executor.Naming
+	// Spread spreads the created pods and jobs across its namespaces, they are created in Namespace if it has no namespaces.
+	// The namespaces are created on demand and labeled as fake namespaces of the run.
+	Spread executor.Spread
+	// Workloads are named workloads, e.g. the jobs of tenants, which run concurrently with the node, pod and job rate limiters.
+	Workloads []Workload
+	// DynamicClient is used to create and delete objects of arbitrary kinds, it is required by Objects and DeleteObjects.
+	DynamicClient dynamic.Interface
+	// Objects creates objects of an arbitrary kind from a template through DynamicClient, e.g. custom resources
+	// of a batch system, concurrently with the node, pod and job rate limiters. It is optional.
+	Objects *ObjectsConfig
+	// Kueue submits suspended jobs to Kueue LocalQueues, which are created by SetupKueue through DynamicClient.
+	// It is optional.
+	Kueue *KueueConfig
+	// Gangs creates gangs, a PodGroup through DynamicClient and its member pods, concurrently with the node, pod
+	// and job rate limiters. It is optional.
+	Gangs *GangsConfig
+}
+
+// ObjectsConfig is used to configure the creation of objects of an arbitrary kind from a template.
+type ObjectsConfig struct {
+	// Resource is the resource of the created objects, e.g. podgroups.v1alpha1.scheduling.x-k8s.io.
+	Resource schema.GroupVersionResource
+	// ClusterScoped is used to determine if the objects are cluster-scoped, otherwise they are created
+	// in the namespace of the Manager or spread across the namespaces of the spread like pods and jobs.
+	ClusterScoped bool
+	// Template is the template of the created objects.
+	Template *unstructured.Unstructured
+	// RateLimiterConfig is the configuration for the rate limited UnstructuredCreator.
+	RateLimiterConfig RateLimiterConfig
+}
+
+// Workload is a named generator of resources which runs concurrently with the other generators of the Manager,
+// e.g. the jobs of a tenant with its own namespaces, template and rate.
+type Workload struct {
+	// Name identifies the workload, it must be unique.
+	Name string
+	// RateLimiter generates the resources of the workload, its identifier must be unique.
+	RateLimiter ratelimiter.Runner
+	// NamespaceMetrics returns the metrics of the created resources per namespace, it is optional.
+	NamespaceMetrics func() map[string]ratelimiter.Metrics
 }
 
 // RateLimiterConfig is used to configure the rate limiter for a specific resource type.
@@ -78,39 +183,165 @@ type RateLimiterConfig struct {
 func NewManager(client kubernetes.Interface, cfg *ManagerConfig) *Manager {
 	defaultedConfig := cfg
 	defaultManagerConfig(defaultedConfig)
-	nodeExecutor := executor.NewNodeCreator(client)
+	nodeNamer, podNamer, jobNamer := newNamers(defaultedConfig)
+	nodeExecutor := executor.NewNodeCreator(client, defaultedConfig.Rand.Fork("nodes"), nodeNamer)
 	nodeRateLimiter := ratelimiter.New[*corev1.Node](
 		defaultedConfig.NodeRateLimiterConfig.Frequency,
 		defaultedConfig.NodeRateLimiterConfig.Requests,
 		defaultedConfig.NodeRateLimiterConfig.Limit,
 		nodeExecutor,
+		ratelimiter.WithObserver[*corev1.Node](defaultedConfig.Observer),
+	)
+	podExecutor := executor.NewPodCreator(
+		client,
+		defaultedConfig.Namespace,
+		defaultedConfig.RandomEnvVars,
+		defaultedConfig.Rand.Fork("pods"),
+		podNamer,
+		spreadOptions(client, defaultedConfig, "pod-namespaces")...,
 	)
-	podExecutor := executor.NewPodCreator(client, defaultedConfig.Namespace, defaultedConfig.RandomEnvVars)
 	podRateLimiter := ratelimiter.New[*corev1.Pod](
 		defaultedConfig.PodRateLimiterConfig.Frequency,
 		defaultedConfig.PodRateLimiterConfig.Requests,
 		defaultedConfig.PodRateLimiterConfig.Limit,
 		podExecutor,
+		ratelimiter.WithObserver[*corev1.Pod](defaultedConfig.Observer),
+	)
+	jobExecutor := executor.NewJobCreator(
+		client,
+		defaultedConfig.Namespace,
+		defaultedConfig.RandomEnvVars,
+		defaultedConfig.Rand.Fork("jobs"),
+		jobNamer,
+		append(spreadOptions(client, defaultedConfig, "job-namespaces"), kueueOptions(defaultedConfig)...)...,
 	)
-	jobExecutor := executor.NewJobCreator(client, defaultedConfig.Namespace, defaultedConfig.RandomEnvVars)
 	jobRateLimiter := ratelimiter.New[*batchv1.Job](
 		defaultedConfig.JobRateLimiterConfig.Frequency,
 		defaultedConfig.JobRateLimiterConfig.Requests,
 		defaultedConfig.JobRateLimiterConfig.Limit,
 		jobExecutor,
+		ratelimiter.WithObserver[*batchv1.Job](defaultedConfig.Observer),
 	)
 	m := &Manager{
-		client:                 client,
-		namespace:              defaultedConfig.Namespace,
-		logger:                 defaultedConfig.Logger,
-		rateLimitedNodeCreator: nodeRateLimiter,
-		rateLimitedPodCreator:  podRateLimiter,
-		rateLimitedJobCreator:  jobRateLimiter,
+		client:                  client,
+		namespace:               defaultedConfig.Namespace,
+		spread:                  len(defaultedConfig.Spread.Namespaces) > 0,
+		namespaces:              createdNamespaces(defaultedConfig),
+		kueue:                   defaultedConfig.Kueue,
+		logger:                  defaultedConfig.Logger,
+		rateLimitedNodeCreator:  nodeRateLimiter,
+		rateLimitedPodCreator:   podRateLimiter,
+		rateLimitedJobCreator:   jobRateLimiter,
+		podExecutor:             podExecutor,
+		jobExecutor:             jobExecutor,
+		workloads:               append([]Workload(nil), defaultedConfig.Workloads...),
+		runners:                 []ratelimiter.Runner{nodeRateLimiter, podRateLimiter, jobRateLimiter},
+		runID:                   defaultedConfig.RunID,
+		nodeNamer:               nodeNamer,
+		podNamer:                podNamer,
+		jobNamer:                jobNamer,
+		deleteRateLimiterConfig: defaultedConfig.DeleteRateLimiterConfig,
+		deleteGracePeriod:       deleteGracePeriod(defaultedConfig.ForceDelete),
+		deletions:               make(map[string]*deletion),
+		dynamicClient:           defaultedConfig.DynamicClient,
+		objects:                 defaultedConfig.Objects,
+		gangs:                   defaultedConfig.Gangs,
+	}
+	if defaultedConfig.Objects != nil {
+		m.addObjectsWorkload(defaultedConfig)
+	}
+	if defaultedConfig.Gangs != nil {
+		m.addGangsWorkload(defaultedConfig)
+	}
+	for _, workload := range m.workloads {
+		m.runners = append(m.runners, workload.RateLimiter)
 	}
 	m.logger = slog.With("process", "manager")
 	return m
 }
 
+// addObjectsWorkload creates the rate limited UnstructuredCreator of the objects config and runs it as a workload.
+func (m *Manager) addObjectsWorkload(cfg *ManagerConfig) {
+	objects := cfg.Objects
+	prefix := executor.ObjectNamePrefix(objects.Template.GetKind())
+	if cfg.Naming == executor.NamingSequential {
+		m.objectNamer = executor.NewSequentialNamer(prefix, cfg.RunID)
+	} else {
+		m.objectNamer = executor.NewRandomNamer(prefix, cfg.Rand.Fork("object-names"))
+	}
+	namespace := cfg.Namespace
+	var opts []executor.Option
+	if objects.ClusterScoped {
+		namespace = metav1.NamespaceNone
+	} else {
+		opts = spreadOptions(m.client, cfg, "object-namespaces")
+	}
+	objectExecutor := executor.NewUnstructuredCreator(
+		cfg.DynamicClient,
+		objects.Resource,
+		namespace,
+		objects.Template,
+		cfg.Rand.Fork("objects"),
+		m.objectNamer,
+		opts...,
+	)
+	m.rateLimitedObjectCreator = ratelimiter.New[*unstructured.Unstructured](
+		objects.RateLimiterConfig.Frequency,
+		objects.RateLimiterConfig.Requests,
+		objects.RateLimiterConfig.Limit,
+		objectExecutor,
+		ratelimiter.WithObserver[*unstructured.Unstructured](cfg.Observer),
+	)
+	m.workloads = append(m.workloads, Workload{
+		Name:             objectsWorkloadName,
+		RateLimiter:      m.rateLimitedObjectCreator,
+		NamespaceMetrics: objectExecutor.NamespaceMetrics,
+	})
+}
+
+// newNamers creates the namers of the node, pod and job creators.
+func newNamers(cfg *ManagerConfig) (nodeNamer, podNamer, jobNamer executor.Namer) {
+	if cfg.Naming == executor.NamingSequential {
+		return executor.NewSequentialNamer(executor.NodeNamePrefix, cfg.RunID),
+			executor.NewSequentialNamer(executor.PodNamePrefix, cfg.RunID),
+			executor.NewSequentialNamer(executor.JobNamePrefix, cfg.RunID)
+	}
+	return executor.NewRandomNamer(executor.NodeNamePrefix, cfg.Rand.Fork("node-names")),
+		executor.NewRandomNamer(executor.PodNamePrefix, cfg.Rand.Fork("pod-names")),
+		executor.NewRandomNamer(executor.JobNamePrefix, cfg.Rand.Fork("job-names"))
+}
+
+// spreadOptions returns the executor options which spread the created objects across the namespaces of the spread,
+// drawing random namespaces from a source forked with name, and create the namespaces on demand.
+func spreadOptions(client kubernetes.Interface, cfg *ManagerConfig, name string) []executor.Option {
+	if len(cfg.Spread.Namespaces) == 0 {
+		return nil
+	}
+	logger := slog.With("process", "manager")
+	return []executor.Option{
+		executor.WithNamespaces(cfg.Spread.Picker(cfg.Rand.Fork(name))),
+		executor.WithNamespaceCreator(func(ctx context.Context, namespace string) error {
+			return CreateFakeNamespaceIfNeed(ctx, client, namespace, logger)
+		}),
+	}
+}
+
+// createdNamespaces returns the namespaces in which pods and jobs are created.
+func createdNamespaces(cfg *ManagerConfig) []string {
+	if len(cfg.Spread.Namespaces) > 0 {
+		return cfg.Spread.Namespaces
+	}
+	return []string{cfg.Namespace}
+}
+
+// deleteGracePeriod returns the grace period of deleted pods.
+func deleteGracePeriod(force bool) *int64 {
+	if force {
+		return ptr.To[int64](0)
+	}
+	return nil
+}
+
 // defaultManagerConfig returns a new ManagerConfig with default values set.
 func defaultManagerConfig(cfg *ManagerConfig) {
 	if cfg.Namespace == "" {
@@ -119,6 +350,9 @@ func defaultManagerConfig(cfg *ManagerConfig) {
 	if cfg.Logger == nil {
 		cfg.Logger = &slog.Logger{}
 	}
+	if cfg.Rand == nil {
+		cfg.Rand = util.Default()
+	}
 	if cfg.PodRateLimiterConfig.Frequency == 0 {
 		cfg.PodRateLimiterConfig.Frequency = defaultPodRateLimiterFrequency
 	}
@@ -137,35 +371,38 @@ func defaultManagerConfig(cfg *ManagerConfig) {
 	if cfg.JobRateLimiterConfig.Requests == 0 {
 		cfg.JobRateLimiterConfig.Requests = defaultJobRateLimiterRequests
 	}
+	if cfg.DeleteRateLimiterConfig.Frequency == 0 {
+		cfg.DeleteRateLimiterConfig.Frequency = defaultDeleteRateLimiterFrequency
+	}
+	if cfg.DeleteRateLimiterConfig.Requests == 0 {
+		cfg.DeleteRateLimiterConfig.Requests = defaultDeleteRateLimiterRequests
+	}
 }
 
-// Start starts the Manager and the pod & node creation rate limiters.
+// Start starts the Manager, the node, pod & job creation rate limiters and the rate limiters of the workloads.
 // It blocks until the Manager is stopped the context is cancelled or all rate limited executors have finished.
 func (m *Manager) Start(ctx context.Context) error {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
-	m.logger.Info("starting kubernetes resource manager with rate limiting")
-	go m.rateLimitedNodeCreator.Run(ctx)
-	go m.rateLimitedPodCreator.Run(ctx)
-	go m.rateLimitedJobCreator.Run(ctx)
+	m.logger.Info("starting kubernetes resource manager with rate limiting", "workloads", len(m.workloads))
+	errs := make(chan error)
+	done := make(chan struct{})
+	defer close(done)
+	for _, runner := range m.runners {
+		go runner.Run(ctx)
+		go forwardErrors(runner, errs, done)
+	}
 
 	for {
 		select {
-		case err := <-m.rateLimitedNodeCreator.ErrChan():
-			m.logger.Error("received error from node rate limiter", "error", err)
-		case err := <-m.rateLimitedPodCreator.ErrChan():
-			m.logger.Error("received error from pod rate limiter", "error", err)
-		case err := <-m.rateLimitedJobCreator.ErrChan():
-			m.logger.Error("received error from job rate limiter", "error", err)
+		case err := <-errs:
+			m.logger.Error("received error from rate limiter", "error", err)
 		case <-ctx.Done():
 			m.Stop()
 			return ctx.Err()
 		case <-ticker.C:
-			nodeCreatorStopped := !m.rateLimitedNodeCreator.IsRunning()
-			podCreatorStopped := !m.rateLimitedPodCreator.IsRunning()
-			jobCreatorStopped := !m.rateLimitedJobCreator.IsRunning()
-			if nodeCreatorStopped && podCreatorStopped && jobCreatorStopped {
+			if !m.running() {
 				m.Stop()
 				return nil
 			}
@@ -173,30 +410,209 @@ func (m *Manager) Start(ctx context.Context) error {
 	}
 }
 
+// running returns true if any rate limiter is running.
+func (m *Manager) running() bool {
+	for _, runner := range m.runners {
+		if runner.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardErrors forwards the errors of the rate limiter to errs until done is closed.
+func forwardErrors(runner ratelimiter.Runner, errs chan<- error, done <-chan struct{}) {
+	for {
+		select {
+		case err := <-runner.ErrChan():
+			select {
+			case errs <- fmt.Errorf("%s: %w", runner.Identifier(), err):
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Resume counts the nodes, pods and jobs which were created for the run ID by a previous, interrupted run,
+// so the rate limiters only create the remaining resources up to their limits.
+// With sequential naming, names continue after the highest existing sequence number so no name is created twice.
+// Resources of the run which were deleted in the meantime (e.g. finished jobs removed by their TTL) are created again.
+// It must be called before Start.
+func (m *Manager) Resume(ctx context.Context) (nodes, pods, jobs int, err error) {
+	if m.runID == "" {
+		return 0, 0, 0, fmt.Errorf("resuming requires a run ID")
+	}
+	nodes, err = resume(ctx, m.rateLimitedNodeCreator, nodeLister(m.client), resources.LabelSelectorFakeNode, m.runID, m.nodeNamer)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count nodes of run %s: %w", m.runID, err)
+	}
+	pods, err = resume(ctx, m.rateLimitedPodCreator, podLister(m.client, m.createdNamespace()), resources.LabelSelectorStandaloneFakePod, m.runID, m.podNamer)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count pods of run %s: %w", m.runID, err)
+	}
+	jobs, err = resume(ctx, m.rateLimitedJobCreator, jobLister(m.client, m.createdNamespace()), resources.LabelSelectorFakeJob, m.runID, m.jobNamer)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count jobs of run %s: %w", m.runID, err)
+	}
+	if m.rateLimitedObjectCreator != nil {
+		objects, err := resume(ctx, m.rateLimitedObjectCreator, m.objectLister(), resources.LabelSelectorFakeObject, m.runID, m.objectNamer)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("failed to count %s of run %s: %w", m.objects.Resource.Resource, m.runID, err)
+		}
+		m.logger.Info("resumed objects", "runID", m.runID, "resource", m.objects.Resource.String(), "objects", objects)
+	}
+	if m.rateLimitedGangCreator != nil {
+		list := unstructuredLister(m.dynamicClient, m.gangs.Style.PodGroupResource(), m.createdNamespace())
+		gangs, err := resume(ctx, m.rateLimitedGangCreator, list, resources.LabelSelectorFakePodGroup, m.runID, m.gangNamer)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("failed to count gangs of run %s: %w", m.runID, err)
+		}
+		m.logger.Info("resumed gangs", "runID", m.runID, "gangs", gangs)
+	}
+	m.logger.Info("resumed run", "runID", m.runID, "nodes", nodes, "pods", pods, "jobs", jobs)
+	return nodes, pods, jobs, nil
+}
+
+// objectLister lists the objects of the objects config in the namespaces in which they are created.
+func (m *Manager) objectLister() objectLister {
+	if m.objects.ClusterScoped {
+		return unstructuredLister(m.dynamicClient, m.objects.Resource, metav1.NamespaceNone)
+	}
+	return unstructuredLister(m.dynamicClient, m.objects.Resource, m.createdNamespace())
+}
+
+// createdNamespace returns the namespace in which pods and jobs are created, all namespaces if they are spread.
+func (m *Manager) createdNamespace() string {
+	if m.spread {
+		return metav1.NamespaceAll
+	}
+	return m.namespace
+}
+
+// resume counts the existing resources of the run, resumes the rate limiter from the count
+// and continues the sequence of a sequential namer after the highest existing sequence number.
+func resume[T any](
+	ctx context.Context,
+	rl *ratelimiter.RateLimiter[T],
+	list objectLister,
+	labelSelector, runID string,
+	namer executor.Namer,
+) (int, error) {
+	count := 0
+	next := int64(0)
+	sequential, isSequential := namer.(*executor.SequentialNamer)
+	err := forEachObject(ctx, list, resources.RunSelector(labelSelector, runID), func(obj metav1.Object) {
+		count++
+		if !isSequential {
+			return
+		}
+		if seq, ok := sequential.Seq(obj.GetName()); ok && seq >= next {
+			next = seq + 1
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	if isSequential {
+		sequential.SetNext(next)
+	}
+	rl.Resume(count)
+	return count, nil
+}
+
+// Resumed returns the number of resources which were created by a previous run and count toward the limits.
+func (m *Manager) Resumed() (nodes, pods, jobs int) {
+	return m.rateLimitedNodeCreator.Resumed(), m.rateLimitedPodCreator.Resumed(), m.rateLimitedJobCreator.Resumed()
+}
+
 // Stop stops the Manager.
 func (m *Manager) Stop() {
 	m.logger.Info("stopping kubernetes resource manager")
-	m.rateLimitedPodCreator.Stop()
-	m.rateLimitedNodeCreator.Stop()
-	m.rateLimitedJobCreator.Stop()
+	for _, runner := range m.runners {
+		runner.Stop()
+	}
+}
+
+// Workloads returns the workloads of the Manager.
+func (m *Manager) Workloads() []Workload {
+	return m.workloads
 }
 
-// DeleteNodes deletes all Kubernetes Node resources having provided label.
+// DeleteNodes retries to delete Kubernetes Node resources having provided label.
 // If async is set to false, this function will block until nodes are terminated or context exceeds deadline.
 func (m *Manager) DeleteNodes(ctx context.Context, labelSelector string, async bool) error {
-	deleteFunc := func(ctx context.Context, client kubernetes.Interface, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions, async bool) error {
-		return client.CoreV1().Nodes().DeleteCollection(ctx, deleteOpts, listOpts)
+	return retryable(ctx, func() error { return m.deleteNodes(ctx, labelSelector, async) }, defaultRetryCount)
+}
+
+// deleteNodes deletes Kubernetes Node resources having provided label.
+// If async is set to false, this function will block until nodes are terminated or context exceeds deadline.
+func (m *Manager) deleteNodes(ctx context.Context, labelSelector string, async bool) error {
+	deleteFunc := func(ctx context.Context, name string) error {
+		return m.client.CoreV1().Nodes().Delete(ctx, name, metav1.DeleteOptions{})
 	}
-	listFunc := func(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions) (bool, error) {
-		nodeList, err := client.CoreV1().Nodes().List(ctx, opts)
+	m.logger.Info("deleting nodes", "labelSelector", labelSelector, "async", async)
+	if err := m.deleteObjects(ctx, "nodes", []objectLister{nodeLister(m.client)}, labelSelector, deleteFunc, async); err != nil {
+		return fmt.Errorf("failed to delete nodes with labelSelector=%s: %w", labelSelector, err)
+	}
+
+	return nil
+}
+
+// DeleteNamespaces retries to delete the Kubernetes Namespace resources having provided label,
+// which deletes the pods and jobs in the namespaces as well.
+// If async is set to false, this function will block until namespaces are terminated or context exceeds deadline.
+func (m *Manager) DeleteNamespaces(ctx context.Context, labelSelector string, async bool) error {
+	return retryable(ctx, func() error { return m.deleteNamespaces(ctx, labelSelector, async) }, defaultRetryCount)
+}
+
+// deleteNamespaces deletes Kubernetes Namespace resources having provided label.
+// If async is set to false, this function will block until namespaces are terminated or context exceeds deadline.
+func (m *Manager) deleteNamespaces(ctx context.Context, labelSelector string, async bool) error {
+	deleteFunc := func(ctx context.Context, name string) error {
+		return m.client.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	m.logger.Info("deleting namespaces", "labelSelector", labelSelector, "async", async)
+	if err := m.deleteObjects(ctx, "namespaces", []objectLister{namespaceLister(m.client)}, labelSelector, deleteFunc, async); err != nil {
+		return fmt.Errorf("failed to delete namespaces with labelSelector=%s: %w", labelSelector, err)
+	}
+
+	return nil
+}
+
+// DeleteObjects retries to delete the objects of the resource having provided label through the dynamic client.
+// Namespaced objects are deleted in the namespace of the Manager and in the generated namespaces, see cleanedNamespaces.
+// If async is set to false, this function will block until objects are terminated or context exceeds deadline.
+func (m *Manager) DeleteObjects(ctx context.Context, resource schema.GroupVersionResource, clusterScoped bool, labelSelector string, async bool) error {
+	if m.dynamicClient == nil {
+		return fmt.Errorf("deleting %s requires a dynamic client", resource.Resource)
+	}
+	return retryable(ctx, func() error { return m.deleteObjectsOf(ctx, resource, clusterScoped, labelSelector, async) }, defaultRetryCount)
+}
+
+// deleteObjectsOf deletes the objects of the resource having provided label through the dynamic client.
+// If async is set to false, this function will block until objects are terminated or context exceeds deadline.
+func (m *Manager) deleteObjectsOf(ctx context.Context, resource schema.GroupVersionResource, clusterScoped bool, labelSelector string, async bool) error {
+	deleteFunc := func(ctx context.Context, namespace, name string) error {
+		deletePropagationBackground := metav1.DeletePropagationBackground
+		deleteOpts := metav1.DeleteOptions{PropagationPolicy: &deletePropagationBackground}
+		return m.dynamicClient.Resource(resource).Namespace(namespace).Delete(ctx, name, deleteOpts)
+	}
+	lists := []objectLister{unstructuredLister(m.dynamicClient, resource, metav1.NamespaceNone)}
+	if !clusterScoped {
+		var err error
+		lists, err = m.cleanedListers(ctx, func(namespace string) objectLister {
+			return unstructuredLister(m.dynamicClient, resource, namespace)
+		})
 		if err != nil {
-			return false, err
+			return err
 		}
-		return len(nodeList.Items) == 0, nil
 	}
-	m.logger.Info("deleting nodes", "labelSelector", labelSelector, "async", async)
-	if err := deleteCollection(ctx, m.client, labelSelector, deleteFunc, listFunc, async); err != nil {
-		return fmt.Errorf("failed to delete nodes with labelSelector=%s: %w", labelSelector, err)
+	m.logger.Info("deleting objects", "resource", resource.String(), "labelSelector", labelSelector, "async", async)
+	if err := m.deleteObjects(ctx, resource.Resource, lists, labelSelector, byKey(deleteFunc), async); err != nil {
+		return fmt.Errorf("failed to delete %s with labelSelector=%s: %w", resource.Resource, labelSelector, err)
 	}
 
 	return nil
@@ -215,26 +631,24 @@ func (m *Manager) WaitForNodesToTerminate(ctx context.Context, client kubernetes
 }
 
 // DeletePods  retries to delete Kubernetes Pod resources having provided label.
+// Pods are deleted in the namespace of the Manager and in the generated namespaces, see cleanedNamespaces.
 // If async is set to false, this function will block until pods are terminated or context exceeds deadline.
 func (m *Manager) DeletePods(ctx context.Context, labelSelector string, async bool) error {
-	return retryable(func() error { return m.deletePods(ctx, labelSelector, async) }, defaultRetryCount)
+	return retryable(ctx, func() error { return m.deletePods(ctx, labelSelector, async) }, defaultRetryCount)
 }
 
 // deletePods deletes Kubernetes Pod resources having provided label.
 // If async is set to false, this function will block until pods are terminated or context exceeds deadline.
 func (m *Manager) deletePods(ctx context.Context, labelSelector string, async bool) error {
-	deleteFunc := func(ctx context.Context, client kubernetes.Interface, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions, async bool) error {
-		return client.CoreV1().Pods(m.namespace).DeleteCollection(ctx, deleteOpts, listOpts)
+	deleteFunc := func(ctx context.Context, namespace, name string) error {
+		return m.client.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{GracePeriodSeconds: m.deleteGracePeriod})
 	}
-	listFunc := func(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions) (bool, error) {
-		podList, err := client.CoreV1().Pods(m.namespace).List(ctx, opts)
-		if err != nil {
-			return false, err
-		}
-		return len(podList.Items) == 0, nil
+	lists, err := m.cleanedListers(ctx, func(namespace string) objectLister { return podLister(m.client, namespace) })
+	if err != nil {
+		return err
 	}
-	m.logger.Info("deleting pods", "labelSelector", labelSelector, "async", async)
-	if err := deleteCollection(ctx, m.client, labelSelector, deleteFunc, listFunc, async); err != nil {
+	m.logger.Info("deleting pods", "labelSelector", labelSelector, "namespaces", len(lists), "async", async)
+	if err := m.deleteObjects(ctx, "pods", lists, labelSelector, byKey(deleteFunc), async); err != nil {
 		return fmt.Errorf("failed to delete pods with labelSelector=%s: %w", labelSelector, err)
 	}
 
@@ -254,28 +668,26 @@ func (m *Manager) WaitForPodsToTerminate(ctx context.Context, client kubernetes.
 }
 
 // DeleteJobs retries to delete Kubernetes Job resources having provided label.
+// Jobs are deleted in the namespace of the Manager and in the generated namespaces, see cleanedNamespaces.
 // If async is set to false, this function will block until jobs are terminated or context exceeds deadline.
 func (m *Manager) DeleteJobs(ctx context.Context, labelSelector string, async bool) error {
-	return retryable(func() error { return m.deleteJobs(ctx, labelSelector, async) }, defaultRetryCount)
+	return retryable(ctx, func() error { return m.deleteJobs(ctx, labelSelector, async) }, defaultRetryCount)
 }
 
 // deleteJobs deletes Kubernetes Job resources having provided label.
 // If async is set to false, this function will block until jobs are terminated or context exceeds deadline.
 func (m *Manager) deleteJobs(ctx context.Context, labelSelector string, async bool) error {
-	deleteFunc := func(ctx context.Context, client kubernetes.Interface, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions, async bool) error {
+	deleteFunc := func(ctx context.Context, namespace, name string) error {
 		deletePropagationBackground := metav1.DeletePropagationBackground
-		deleteOpts.PropagationPolicy = &deletePropagationBackground
-		return client.BatchV1().Jobs(m.namespace).DeleteCollection(ctx, deleteOpts, listOpts)
+		deleteOpts := metav1.DeleteOptions{PropagationPolicy: &deletePropagationBackground}
+		return m.client.BatchV1().Jobs(namespace).Delete(ctx, name, deleteOpts)
 	}
-	listFunc := func(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions) (bool, error) {
-		jobList, err := client.BatchV1().Jobs(m.namespace).List(ctx, opts)
-		if err != nil {
-			return false, err
-		}
-		return len(jobList.Items) == 0, nil
+	lists, err := m.cleanedListers(ctx, func(namespace string) objectLister { return jobLister(m.client, namespace) })
+	if err != nil {
+		return err
 	}
-	m.logger.Info("deleting jobs", "labelSelector", labelSelector, "async", async)
-	if err := deleteCollection(ctx, m.client, labelSelector, deleteFunc, listFunc, async); err != nil {
+	m.logger.Info("deleting jobs", "labelSelector", labelSelector, "namespaces", len(lists), "async", async)
+	if err := m.deleteObjects(ctx, "jobs", lists, labelSelector, byKey(deleteFunc), async); err != nil {
 		return fmt.Errorf("failed to delete jobs with labelSelector=%s: %w", labelSelector, err)
 	}
 
@@ -297,53 +709,37 @@ func (m *Manager) WaitForJobsToTerminate(ctx context.Context, client kubernetes.
 // DeleteEvents uses retries to deletes Kubernetes Event resources having provided label.
 // If async is set to false, this function will block until jobs are terminated or context exceeds deadline.
 func (m *Manager) DeleteEvents(ctx context.Context, async bool) error {
-	return retryable(func() error { return m.deleteEvents(ctx, async) }, defaultRetryCount)
+	return retryable(ctx, func() error { return m.deleteEvents(ctx, async) }, defaultRetryCount)
 }
 
-// deleteEvents deletes Kubernetes Event resources having provided label.
-// If async is set to false, this function will block until jobs are terminated or context exceeds deadline.
+// deleteEvents deletes the Kubernetes Event resources in the namespace of the Manager and in the generated namespaces.
+// Events are not labeled and a simulation records several of them for every pod, so every namespace is cleaned with a
+// single delete collection request instead of deleting the events one by one.
+// If async is set to false, this function will block until events are terminated or context exceeds deadline.
 func (m *Manager) deleteEvents(ctx context.Context, async bool) error {
-	deleteFunc := func(ctx context.Context, client kubernetes.Interface, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions, async bool) error {
-		deletePropagationBackground := metav1.DeletePropagationBackground
-		deleteOpts.PropagationPolicy = &deletePropagationBackground
-		return client.CoreV1().Events(m.namespace).DeleteCollection(ctx, deleteOpts, listOpts)
+	namespaces, err := m.cleanedNamespaces(ctx)
+	if err != nil {
+		return err
 	}
-	listFunc := func(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions) (bool, error) {
-		eventList, err := client.CoreV1().Events(m.namespace).List(ctx, opts)
-		if err != nil {
-			return false, err
+	m.logger.Info("deleting events", "namespaces", len(namespaces), "async", async)
+	lists := make([]objectLister, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if err := m.client.CoreV1().Events(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
+			return fmt.Errorf("failed to delete events in namespace %s: %w", namespace, err)
 		}
-		return len(eventList.Items) == 0, nil
-	}
-	m.logger.Info("deleting events", "async", async)
-	if err := deleteCollection(ctx, m.client, "", deleteFunc, listFunc, async); err != nil {
-		return fmt.Errorf("failed to delete events: %w", err)
-	}
-
-	return nil
-}
-
-// deleteCollection deletes Kubernetes resources having provided label.
-func deleteCollection(ctx context.Context, client kubernetes.Interface, labelSelector string, deleteFunc DeleteFunc, listFunc ListFunc, async bool) error {
-	deleteOpts := metav1.DeleteOptions{}
-	listOpts := metav1.ListOptions{
-		LabelSelector: labelSelector,
-	}
-	if err := deleteFunc(ctx, client, deleteOpts, listOpts, async); err != nil {
-		return err
+		lists = append(lists, eventLister(m.client, namespace))
 	}
 	if !async {
-		if err := waitFor(ctx, client, labelSelector, listFunc); err != nil {
-			return err
-		}
+		return waitFor(ctx, m.client, "", listersEmpty(lists))
 	}
+
 	return nil
 }
 
-type DeleteFunc func(ctx context.Context, client kubernetes.Interface, deleteOpts metav1.DeleteOptions, listOpts metav1.ListOptions, async bool) error
 type ListFunc func(ctx context.Context, client kubernetes.Interface, opts metav1.ListOptions) (empty bool, err error)
 
 // waitFor waits for the resources with the provided labelSelector to be empty.
+// Only a single resource is listed on every poll, so polling stays cheap regardless of how many resources remain.
 func waitFor(ctx context.Context, client kubernetes.Interface, labelSelector string, listFunc ListFunc) error {
 	return wait.PollUntilContextTimeout(
 		ctx,
@@ -351,7 +747,7 @@ func waitFor(ctx context.Context, client kubernetes.Interface, labelSelector str
 		config.DefaultPollTimeout,
 		false,
 		func(ctx context.Context) (done bool, err error) {
-			listOpts := metav1.ListOptions{LabelSelector: labelSelector}
+			listOpts := metav1.ListOptions{LabelSelector: labelSelector, Limit: 1}
 			empty, err := listFunc(ctx, client, listOpts)
 			if err != nil {
 				return false, err
@@ -364,65 +760,34 @@ func waitFor(ctx context.Context, client kubernetes.Interface, labelSelector str
 
 // CreateNamespaceIfNeed creates the provided namespace if it does not exist.
 func CreateNamespaceIfNeed(ctx context.Context, client kubernetes.Interface, namespace string, logger *slog.Logger) error {
-	logger.Info("checking does namespace exist", "namespace", namespace)
-	_, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	return createNamespaceIfNeed(ctx, client, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, logger)
+}
+
+// CreateFakeNamespaceIfNeed creates the provided namespace, labeled as a fake namespace of the run, if it does not exist.
+func CreateFakeNamespaceIfNeed(ctx context.Context, client kubernetes.Interface, namespace string, logger *slog.Logger) error {
+	return createNamespaceIfNeed(ctx, client, resources.NewFakeNamespace(namespace), logger)
+}
+
+// createNamespaceIfNeed creates the namespace if it does not exist, a namespace created concurrently is not an error.
+func createNamespaceIfNeed(ctx context.Context, client kubernetes.Interface, namespace *corev1.Namespace, logger *slog.Logger) error {
+	logger.Info("checking does namespace exist", "namespace", namespace.Name)
+	_, err := client.CoreV1().Namespaces().Get(ctx, namespace.Name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			logger.Info("namespace does not exist, creating namespace", "namespace", namespace)
-			_, err = client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: namespace,
-				},
-			}, metav1.CreateOptions{})
-			if err != nil {
+			logger.Info("namespace does not exist, creating namespace", "namespace", namespace.Name)
+			_, err = client.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+			if err != nil && !k8serrors.IsAlreadyExists(err) {
 				return err
 			}
 		} else {
 			return err
 		}
 	} else {
-		logger.Info("namespace already exists", "namespace", namespace)
+		logger.Info("namespace already exists", "namespace", namespace.Name)
 	}
 	return nil
 }
 
-// WaitForPodsToComplete waits for the pods with the provided labelSelector to complete.
-func (m *Manager) WaitForPodsToComplete(ctx context.Context, labelSelector string, logger *slog.Logger) error {
-	return wait.PollUntilContextTimeout(
-		ctx,
-		1*time.Minute,
-		3*time.Hour,
-		true,
-		func(ctx context.Context) (done bool, err error) {
-			logger = logger.With("labelSelector", labelSelector)
-			running := 0
-			completed := 0
-			logger.Info("checking if all pods are completed")
-			listOpts := metav1.ListOptions{LabelSelector: labelSelector}
-			pods, err := m.client.CoreV1().Pods(m.namespace).List(ctx, listOpts)
-			if err != nil {
-				return false, err
-			}
-			logger.Info("listed pods", "count", len(pods.Items))
-			for _, pod := range pods.Items { //nolint
-				if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
-					running++
-				} else {
-					completed++
-				}
-			}
-
-			if running > 0 {
-				logger.Info("some pods are still running", "running", running, "completed", completed)
-				return false, nil
-			}
-
-			logger.Info("all pods are completed")
-			return true, nil
-		},
-	)
-}
-
 func (m *Manager) Metrics() (nodeCreationMetrics, podCreationMetrics, jobCreationMetrics ratelimiter.Metrics) {
 	nodeCreationMetrics = m.rateLimitedNodeCreator.Metrics()
 	podCreationMetrics = m.rateLimitedPodCreator.Metrics()
@@ -430,7 +795,51 @@ func (m *Manager) Metrics() (nodeCreationMetrics, podCreationMetrics, jobCreatio
 	return
 }
 
-func retryable(f func() error, retries int) error {
+// NamespaceMetrics returns the metrics of the created pods and jobs per namespace keyed by executor identifier,
+// including the workloads which report them.
+func (m *Manager) NamespaceMetrics() map[string]map[string]ratelimiter.Metrics {
+	metrics := map[string]map[string]ratelimiter.Metrics{
+		m.podExecutor.Identifier(): m.podExecutor.NamespaceMetrics(),
+		m.jobExecutor.Identifier(): m.jobExecutor.NamespaceMetrics(),
+	}
+	for _, workload := range m.workloads {
+		if workload.NamespaceMetrics != nil {
+			metrics[workload.RateLimiter.Identifier()] = workload.NamespaceMetrics()
+		}
+	}
+	return metrics
+}
+
+// Rates returns the configured and achieved rates of all rate limiters keyed by executor identifier.
+func (m *Manager) Rates() map[string]ratelimiter.Rate {
+	rates := make(map[string]ratelimiter.Rate, len(m.runners))
+	for _, runner := range m.runners {
+		rates[runner.Identifier()] = runner.Rate()
+	}
+	return rates
+}
+
+// TimeSeries returns the per-interval samples of all rate limiters keyed by executor identifier.
+func (m *Manager) TimeSeries() map[string][]ratelimiter.Sample {
+	timeSeries := make(map[string][]ratelimiter.Sample, len(m.runners))
+	for _, runner := range m.runners {
+		timeSeries[runner.Identifier()] = runner.TimeSeries()
+	}
+	return timeSeries
+}
+
+// Summaries returns the summaries of all rate limiters keyed by executor identifier.
+func (m *Manager) Summaries() map[string]ratelimiter.Summary {
+	summaries := make(map[string]ratelimiter.Summary, len(m.runners))
+	for _, runner := range m.runners {
+		summaries[runner.Identifier()] = runner.Summary()
+	}
+	return summaries
+}
+
+// retryable calls f until it succeeds, it is called at most retries times or until the context is done.
+// It returns the last error of f.
+func retryable(ctx context.Context, f func() error, retries int) error {
 	var err error
 	for i := 0; i < retries; i++ {
 		err = f()
@@ -438,6 +847,9 @@ func retryable(f func() error, retries int) error {
 			return nil
 		}
 		slog.Error("retryable function failed", "error", err, "retries", i)
+		if ctx.Err() != nil {
+			return err
+		}
 	}
 	return err
 }
@@ -2,10 +2,21 @@ package k8s
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"github.com/dejanzele/batch-simulator/internal/fault"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"strings"
 	"testing"
 	"time"
 )
@@ -31,17 +42,17 @@ func TestNewManager(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
 		defer cancel()
 
-		var err error
+		errCh := make(chan error, 1)
 		go func() {
-			err = manager.Start(ctx)
+			errCh <- manager.Start(ctx)
 		}()
 
-		assert.Eventually(t, func() bool {
-			return errors.Is(err, context.DeadlineExceeded)
-		},
-			30*time.Millisecond,
-			10*time.Millisecond,
-		)
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			t.Fatal("manager did not stop when context timed out")
+		}
 	})
 
 	t.Run("manager stops when context is cancelled", func(t *testing.T) {
@@ -60,20 +71,20 @@ func TestNewManager(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		var err error
+		errCh := make(chan error, 1)
 		go func() {
-			err = manager.Start(ctx)
+			errCh <- manager.Start(ctx)
 		}()
 
 		time.Sleep(10 * time.Millisecond)
 		cancel()
 
-		assert.Eventually(t, func() bool {
-			return errors.Is(err, context.Canceled)
-		},
-			30*time.Millisecond,
-			10*time.Millisecond,
-		)
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("manager did not stop when context was cancelled")
+		}
 	})
 }
 
@@ -106,3 +117,454 @@ func TestManager_Start(t *testing.T) {
 		20*time.Millisecond,
 	)
 }
+
+func TestManager_StartWithFaults(t *testing.T) {
+	t.Parallel()
+
+	fakeClient := fake.NewSimpleClientset()
+	faults := fault.Inject(
+		fakeClient,
+		fault.Fault{Verb: "create", Resource: "pods", Err: fault.TooManyRequests(), Times: 3},
+		fault.Fault{Verb: "create", Resource: "nodes", Err: fault.ConnectionReset(), Times: 1},
+	)
+	config := ManagerConfig{
+		NodeRateLimiterConfig: RateLimiterConfig{
+			Frequency: 10 * time.Millisecond,
+			Requests:  2,
+			Limit:     4,
+		},
+		PodRateLimiterConfig: RateLimiterConfig{
+			Frequency: 10 * time.Millisecond,
+			Requests:  2,
+			Limit:     6,
+		},
+	}
+	manager := NewManager(fakeClient, &config)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, manager.Start(ctx), "manager must finish before the context times out when requests fail")
+
+	summaries := manager.Summaries()
+	assert.Equal(t, 6, summaries["kubernetes-pod-creator"].Executed)
+	assert.Equal(t, 3, summaries["kubernetes-pod-creator"].Failed)
+	assert.Equal(t, 4, summaries["kubernetes-node-creator"].Executed)
+	assert.Equal(t, 1, summaries["kubernetes-node-creator"].Failed)
+	assert.Equal(t, 6, faults.Requests("create", "pods"))
+	assert.Equal(t, 4, faults.Requests("create", "nodes"))
+
+	podList, err := fakeClient.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, podList.Items, 3)
+	nodeList, err := fakeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, nodeList.Items, 3)
+}
+
+func TestManager_DeleteWithFaults(t *testing.T) {
+	t.Parallel()
+
+	labelSelector := "app=batch-simulator"
+	labels := map[string]string{"app": "batch-simulator"}
+	objects := []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-3", Namespace: "default"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job-1", Namespace: "default", Labels: labels}},
+	}
+	deleteConfig := RateLimiterConfig{Frequency: time.Millisecond, Requests: 10}
+
+	t.Run("pods are deleted after transient delete failures", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		faults := fault.Inject(
+			fakeClient,
+			fault.Fault{Verb: "delete", Resource: "pods", Err: fault.InternalError(), Times: 2},
+		)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		assert.NoError(t, manager.DeletePods(context.Background(), labelSelector, true))
+		assert.Equal(t, 4, faults.Requests("delete", "pods"), "both pods must be deleted again by the second attempt")
+		assert.Equal(t, DeleteProgress{Total: 2, Deleted: 2, Failed: 2}, manager.DeleteProgress()["pods"])
+
+		podList, err := fakeClient.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, podList.Items, 1, "pods without the label must not be deleted")
+	})
+
+	t.Run("jobs are deleted after transient delete failures", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		faults := fault.Inject(
+			fakeClient,
+			fault.Fault{Verb: "delete", Resource: "jobs", Err: fault.TooManyRequests(), Times: 1},
+		)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		assert.NoError(t, manager.DeleteJobs(context.Background(), labelSelector, true))
+		assert.Equal(t, 2, faults.Requests("delete", "jobs"))
+
+		jobList, err := fakeClient.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("deletion fails once retries are exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		faults := fault.Inject(
+			fakeClient,
+			fault.Fault{Verb: "delete", Resource: "pods", Err: fault.InternalError()},
+		)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+
+		err := manager.DeletePods(context.Background(), labelSelector, true)
+		assert.True(t, apierrors.IsInternalError(err), "the error of the last attempt must be returned: %v", err)
+		assert.Equal(t, 2*defaultRetryCount, faults.Requests("delete", "pods"))
+	})
+
+	t.Run("waiting for deletion recovers from a failed list", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		faults := fault.Inject(
+			fakeClient,
+			fault.Fault{Verb: "list", Resource: "pods", Err: fault.ConnectionReset(), Times: 1},
+		)
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		assert.NoError(t, manager.DeletePods(ctx, labelSelector, false))
+		assert.Equal(t, 2, faults.Requests("delete", "pods"))
+		assert.Equal(t, 3, faults.Requests("list", "pods"), "the pods must be listed again after the failed list")
+	})
+
+	t.Run("waiting for deletion stops when the context is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(objects...)
+		// Deletions are accepted but never remove the pods, like pods which are stuck terminating.
+		fakeClient.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+		manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: deleteConfig})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := manager.DeletePods(ctx, labelSelector, false)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestManager_DeleteRateLimited(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{"app": "batch-simulator"}
+	var objects []runtime.Object
+	for i := 0; i < 25; i++ {
+		objects = append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i), Labels: labels}})
+	}
+	fakeClient := fake.NewSimpleClientset(objects...)
+	manager := NewManager(fakeClient, &ManagerConfig{
+		DeleteRateLimiterConfig: RateLimiterConfig{Frequency: 20 * time.Millisecond, Requests: 10},
+	})
+
+	start := time.Now()
+	assert.NoError(t, manager.DeleteNodes(context.Background(), "app=batch-simulator", true))
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond, "25 nodes must be deleted in 3 intervals of 10 nodes")
+	assert.Equal(t, DeleteProgress{Total: 25, Deleted: 25}, manager.DeleteProgress()["nodes"])
+
+	nodeList, err := fakeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, nodeList.Items)
+}
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("succeeds after transient errors", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		err := retryable(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return fault.TooManyRequests()
+			}
+			return nil
+		}, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("returns the last error after all retries", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		err := retryable(context.Background(), func() error {
+			calls++
+			return fmt.Errorf("attempt %d failed", calls)
+		}, 5)
+		assert.EqualError(t, err, "attempt 5 failed")
+		assert.Equal(t, 5, calls)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := retryable(ctx, func() error {
+			calls++
+			cancel()
+			return ctx.Err()
+		}, 5)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestManager_Resume(t *testing.T) {
+	t.Parallel()
+
+	runID := "run-1"
+	labels := func(app, runID string) map[string]string {
+		return map[string]string{"type": "kwok", resources.LabelKeyApp: app, resources.LabelKeyRunID: runID}
+	}
+	podLabels := labels(resources.LabelValueFakePod, runID)
+	jobPodLabels := labels(resources.LabelValueFakePod, runID)
+	jobPodLabels[resources.LabelKeyPartOf] = resources.LabelValueFakeJob
+	objects := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake-node-run-1-0", Labels: map[string]string{"type": "kwok", resources.LabelKeyRunID: runID}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "fake-job-run-1-0", Namespace: "default", Labels: labels(resources.LabelValueFakeJob, runID)}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "fake-job-run-1-0-abcde", Namespace: "default", Labels: jobPodLabels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "fake-pod-run-2-0", Namespace: "default", Labels: labels(resources.LabelValueFakePod, "run-2")}},
+	}
+	// the pod with sequence number 2 failed to be created by the interrupted run
+	for _, seq := range []int{0, 1, 3, 4} {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("fake-pod-run-1-%d", seq),
+			Namespace: "default",
+			Labels:    podLabels,
+		}})
+	}
+	fakeClient := fake.NewSimpleClientset(objects...)
+	rateLimiterConfig := func(limit int) RateLimiterConfig {
+		return RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 1, Limit: limit}
+	}
+	manager := NewManager(fakeClient, &ManagerConfig{
+		RunID:                 runID,
+		Naming:                executor.NamingSequential,
+		NodeRateLimiterConfig: rateLimiterConfig(1),
+		PodRateLimiterConfig:  rateLimiterConfig(6),
+		JobRateLimiterConfig:  rateLimiterConfig(2),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nodes, pods, jobs, err := manager.Resume(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, nodes)
+	assert.Equal(t, 4, pods, "pods of jobs and of other runs must not be counted")
+	assert.Equal(t, 1, jobs)
+
+	assert.NoError(t, manager.Start(ctx))
+
+	podList, err := fakeClient.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	var names []string
+	for i := range podList.Items {
+		if strings.HasPrefix(podList.Items[i].Name, "fake-pod-run-1-") {
+			names = append(names, podList.Items[i].Name)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"fake-pod-run-1-0", "fake-pod-run-1-1", "fake-pod-run-1-3", "fake-pod-run-1-4", "fake-pod-run-1-5", "fake-pod-run-1-6",
+	}, names)
+	nodeList, err := fakeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, nodeList.Items, 1, "no nodes must be created once the limit was reached by the interrupted run")
+	jobList, err := fakeClient.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, jobList.Items, 2)
+	assert.Equal(t, 2, manager.Summaries()["kubernetes-pod-creator"].Executed)
+	assert.Equal(t, 4, manager.Summaries()["kubernetes-pod-creator"].Resumed)
+}
+
+func TestManager_ResumeRequiresRunID(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager(fake.NewSimpleClientset(), &ManagerConfig{})
+	_, _, _, err := manager.Resume(context.Background())
+	assert.Error(t, err)
+}
+
+func TestManager_Spread(t *testing.T) {
+	t.Parallel()
+
+	fakeClient := fake.NewSimpleClientset()
+	rateLimiterConfig := func(limit int) RateLimiterConfig {
+		return RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 2, Limit: limit}
+	}
+	manager := NewManager(fakeClient, &ManagerConfig{
+		NodeRateLimiterConfig: rateLimiterConfig(0),
+		PodRateLimiterConfig:  rateLimiterConfig(6),
+		JobRateLimiterConfig:  rateLimiterConfig(3),
+		Spread:                executor.Spread{Namespaces: []string{"tenant-1", "tenant-2", "tenant-3"}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, manager.Start(ctx))
+
+	namespaceList, err := fakeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeNamespace})
+	assert.NoError(t, err)
+	assert.Len(t, namespaceList.Items, 3, "namespaces must be created on demand")
+	for _, namespace := range []string{"tenant-1", "tenant-2", "tenant-3"} {
+		podList, err := fakeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, podList.Items, 2)
+		jobList, err := fakeClient.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, jobList.Items, 1)
+		assert.Equal(t, 2, manager.NamespaceMetrics()["kubernetes-pod-creator"][namespace].Succeeded)
+		assert.Equal(t, 1, manager.NamespaceMetrics()["kubernetes-job-creator"][namespace].Succeeded)
+	}
+	podList, err := fakeClient.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, podList.Items)
+
+	assert.NoError(t, manager.DeleteNamespaces(ctx, resources.LabelSelectorFakeNamespace, true))
+	namespaceList, err = fakeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, namespaceList.Items)
+	assert.Equal(t, DeleteProgress{Total: 3, Deleted: 3}, manager.DeleteProgress()["namespaces"])
+}
+
+func TestManager_DeleteInGeneratedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	pod := func(name, namespace, runID string) *corev1.Pod {
+		labels := map[string]string{resources.LabelKeyApp: resources.LabelValueFakePod, resources.LabelKeyRunID: runID}
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	}
+	// the generated namespace was created by run-a and is shared with run-b
+	generated := resources.NewFakeNamespace("default-1")
+	generated.Labels[resources.LabelKeyRunID] = "run-a"
+	fakeClient := fake.NewSimpleClientset(
+		generated,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		pod("pod-a", "default-1", "run-a"),
+		pod("pod-b", "default-1", "run-b"),
+		pod("pod-c", "default", "run-b"),
+		pod("pod-d", "other", "run-b"),
+	)
+	manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: RateLimiterConfig{Frequency: time.Millisecond, Requests: 10}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, manager.DeletePods(ctx, resources.RunSelector(resources.LabelSelectorFakePod, "run-b"), false))
+	assert.Equal(t, DeleteProgress{Total: 2, Deleted: 2}, manager.DeleteProgress()["pods"])
+	podList, err := fakeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	var remaining []string
+	for _, pod := range podList.Items {
+		remaining = append(remaining, pod.Namespace+"/"+pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"default-1/pod-a", "other/pod-d"}, remaining, "only the pods of the run in the cleaned namespaces must be deleted")
+}
+
+func TestManager_DeleteEventsInGeneratedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	event := func(name, namespace string) *corev1.Event {
+		return &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	fakeClient := fake.NewSimpleClientset(
+		resources.NewFakeNamespace("default-1"),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		event("event-a", "default"),
+		event("event-b", "default-1"),
+		event("event-c", "other"),
+	)
+	fault.EnableDeleteCollection(fakeClient)
+	manager := NewManager(fakeClient, &ManagerConfig{DeleteRateLimiterConfig: RateLimiterConfig{Frequency: time.Millisecond, Requests: 10}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, manager.DeleteEvents(ctx, false))
+	eventList, err := fakeClient.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	var remaining []string
+	for _, event := range eventList.Items {
+		remaining = append(remaining, event.Namespace+"/"+event.Name)
+	}
+	assert.Equal(t, []string{"other/event-c"}, remaining, "only the events in the cleaned namespaces must be deleted")
+	var deleteCollections int
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() == "delete-collection" {
+			deleteCollections++
+		}
+	}
+	assert.Equal(t, 2, deleteCollections, "every cleaned namespace must be deleted with a single request")
+}
+
+func TestManager_Objects(t *testing.T) {
+	t.Parallel()
+
+	podGroups := schema.GroupVersionResource{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Resource: "podgroups"}
+	template := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"minMember": int64(2)}}}
+	template.SetAPIVersion("scheduling.x-k8s.io/v1alpha1")
+	template.SetKind("PodGroup")
+	// the interrupted run created the object with sequence number 0
+	existing := resources.NewFakeObject(template, "fake-podgroup-run-1-0", "default")
+	existing.SetLabels(map[string]string{resources.LabelKeyApp: resources.LabelValueFakeObject, resources.LabelKeyRunID: "run-1"})
+	dynamicClient := NewFakeDynamicClient(map[schema.GroupVersionResource]string{podGroups: "PodGroupList"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := dynamicClient.Resource(podGroups).Namespace("default").Create(ctx, existing, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	rateLimiterConfig := func(limit int) RateLimiterConfig {
+		return RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 1, Limit: limit}
+	}
+	manager := NewManager(fake.NewSimpleClientset(), &ManagerConfig{
+		RunID:                 "run-1",
+		Naming:                executor.NamingSequential,
+		NodeRateLimiterConfig: rateLimiterConfig(0),
+		PodRateLimiterConfig:  rateLimiterConfig(0),
+		JobRateLimiterConfig:  rateLimiterConfig(0),
+		DynamicClient:         dynamicClient,
+		Objects: &ObjectsConfig{
+			Resource:          podGroups,
+			Template:          template,
+			RateLimiterConfig: rateLimiterConfig(3),
+		},
+	})
+	if !assert.Len(t, manager.Workloads(), 1) {
+		t.FailNow()
+	}
+	_, _, _, err = manager.Resume(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, manager.Workloads()[0].RateLimiter.Resumed())
+	assert.NoError(t, manager.Start(ctx))
+
+	list, err := dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeObject})
+	assert.NoError(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	assert.ElementsMatch(t, []string{"fake-podgroup-run-1-0", "fake-podgroup-run-1-1", "fake-podgroup-run-1-2"}, names)
+	assert.Equal(t, 2, manager.Summaries()["kubernetes-podgroup-creator"].Succeeded)
+
+	assert.NoError(t, manager.DeleteObjects(ctx, podGroups, false, resources.LabelSelectorFakeObject, true))
+	list, err = dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+	assert.Equal(t, DeleteProgress{Total: 3, Deleted: 3}, manager.DeleteProgress()["podgroups"])
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+// Run is a simulation run found in the cluster, identified by the run ID label of the objects it created.
+type Run struct {
+	// ID is the run ID.
+	ID string `json:"id"`
+	// Nodes is the number of fake nodes of the run.
+	Nodes int `json:"nodes"`
+	// Pods is the number of fake pods of the run, including the pods of its jobs.
+	Pods int `json:"pods"`
+	// Jobs is the number of fake jobs of the run.
+	Jobs int `json:"jobs"`
+	// FirstCreatedAt is the creation time of the oldest object of the run.
+	FirstCreatedAt time.Time `json:"firstCreatedAt"`
+	// LastCreatedAt is the creation time of the newest object of the run.
+	LastCreatedAt time.Time `json:"lastCreatedAt"`
+}
+
+// ListRuns enumerates the runs which have nodes, or pods and jobs in the namespace, ordered from the oldest to the newest run.
+func ListRuns(ctx context.Context, client kubernetes.Interface, namespace string) ([]Run, error) {
+	runs := make(map[string]*Run)
+	count := func(field func(*Run) *int) func(metav1.Object) {
+		return func(obj metav1.Object) {
+			id := obj.GetLabels()[resources.LabelKeyRunID]
+			run, ok := runs[id]
+			if !ok {
+				run = &Run{ID: id}
+				runs[id] = run
+			}
+			*field(run)++
+			created := obj.GetCreationTimestamp().Time
+			if run.FirstCreatedAt.IsZero() || created.Before(run.FirstCreatedAt) {
+				run.FirstCreatedAt = created
+			}
+			if created.After(run.LastCreatedAt) {
+				run.LastCreatedAt = created
+			}
+		}
+	}
+	lists := []struct {
+		kind string
+		list objectLister
+		add  func(metav1.Object)
+	}{
+		{kind: "nodes", list: nodeLister(client), add: count(func(r *Run) *int { return &r.Nodes })},
+		{kind: "pods", list: podLister(client, namespace), add: count(func(r *Run) *int { return &r.Pods })},
+		{kind: "jobs", list: jobLister(client, namespace), add: count(func(r *Run) *int { return &r.Jobs })},
+	}
+	for _, l := range lists {
+		if err := forEachObject(ctx, l.list, resources.LabelKeyRunID, l.add); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", l.kind, err)
+		}
+	}
+
+	sorted := make([]Run, 0, len(runs))
+	for _, run := range runs {
+		sorted = append(sorted, *run)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].FirstCreatedAt.Equal(sorted[j].FirstCreatedAt) {
+			return sorted[i].FirstCreatedAt.Before(sorted[j].FirstCreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted, nil
+}
@@ -0,0 +1,47 @@
+package k8s
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestListRuns(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	meta := func(name, namespace, runID string, offset time.Duration) metav1.ObjectMeta {
+		labels := map[string]string{"type": "kwok"}
+		if runID != "" {
+			labels[resources.LabelKeyRunID] = runID
+		}
+		return metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(start.Add(offset)),
+		}
+	}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: meta("node-1", "", "second", time.Hour)},
+		&corev1.Pod{ObjectMeta: meta("pod-1", "default", "second", time.Hour+time.Minute)},
+		&corev1.Pod{ObjectMeta: meta("pod-2", "default", "second", time.Hour+2*time.Minute)},
+		&batchv1.Job{ObjectMeta: meta("job-1", "default", "first", 0)},
+		&corev1.Pod{ObjectMeta: meta("job-1-pod", "default", "first", time.Second)},
+		&corev1.Pod{ObjectMeta: meta("other-namespace", "other", "third", 0)},
+		&corev1.Pod{ObjectMeta: meta("no-run", "default", "", 0)},
+	)
+
+	runs, err := ListRuns(context.Background(), client, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, []Run{
+		{ID: "first", Pods: 1, Jobs: 1, FirstCreatedAt: start, LastCreatedAt: start.Add(time.Second)},
+		{ID: "second", Nodes: 1, Pods: 2, FirstCreatedAt: start.Add(time.Hour), LastCreatedAt: start.Add(time.Hour + 2*time.Minute)},
+	}, runs)
+}
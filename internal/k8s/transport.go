@@ -0,0 +1,301 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/metrics"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+// throttledThreshold is the client-side rate limiter wait above which a request is considered throttled.
+const throttledThreshold = 1 * time.Millisecond
+
+// RequestStats summarizes the API requests with the same verb and resource.
+type RequestStats struct {
+	// Verb is the Kubernetes API verb (e.g. create, list, watch).
+	Verb string `json:"verb"`
+	// Resource is the resource of the request, including subresource and group (e.g. pods/status, jobs.batch).
+	Resource string `json:"resource"`
+	// Count is the number of requests.
+	Count int `json:"count"`
+	// Errors is the number of requests which failed without a response.
+	Errors int `json:"errors"`
+	// StatusCodes is the number of responses by HTTP status code.
+	StatusCodes map[string]int `json:"statusCodes"`
+	// RequestBytes is the total size of request bodies.
+	RequestBytes int64 `json:"requestBytes"`
+	// ResponseBytes is the total size of response bodies.
+	ResponseBytes int64 `json:"responseBytes"`
+	// Latency is the distribution of the time until response headers were received, in seconds.
+	Latency stats.Summary `json:"latency"`
+	// RateLimiterWait is the distribution of the time spent waiting for the client-side rate limiter, in seconds.
+	RateLimiterWait stats.Summary `json:"rateLimiterWait"`
+	// Throttled is the number of requests which waited for the client-side rate limiter.
+	Throttled int `json:"throttled"`
+}
+
+// RequestReport summarizes all traced API requests.
+type RequestReport struct {
+	// Requests holds the request stats ordered by resource and verb.
+	Requests []RequestStats `json:"requests"`
+	// Latency is the distribution of the time until response headers were received across all requests, in seconds.
+	Latency stats.Summary `json:"latency"`
+	// RateLimiterWait is the distribution of the client-side rate limiter wait across all requests, in seconds.
+	RateLimiterWait stats.Summary `json:"rateLimiterWait"`
+	// Throttled is the number of requests which waited for the client-side rate limiter.
+	Throttled int `json:"throttled"`
+}
+
+type requestKey struct {
+	verb     string
+	resource string
+}
+
+type requestData struct {
+	count, errors               int
+	statusCodes                 map[string]int
+	requestBytes, responseBytes int64
+	latencies, waits            []time.Duration
+}
+
+// RequestTracer records the latency, status code and size of every API request sent through its transport,
+// and the time each request waited for the client-side rate limiter configured by QPS and Burst.
+type RequestTracer struct {
+	requests map[requestKey]*requestData
+	mutex    sync.Mutex
+}
+
+// NewRequestTracer creates a new RequestTracer.
+func NewRequestTracer() *RequestTracer {
+	return &RequestTracer{requests: make(map[requestKey]*requestData)}
+}
+
+// activeTracer receives client-go rate limiter latencies, which can only be registered once per process.
+var (
+	activeTracer        atomic.Pointer[RequestTracer]
+	registerMetricsOnce sync.Once
+)
+
+// rateLimiterLatency forwards client-go rate limiter latencies to the active tracer.
+type rateLimiterLatency struct{}
+
+// Observe implements metrics.LatencyMetric.
+func (rateLimiterLatency) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	if tracer := activeTracer.Load(); tracer != nil {
+		tracer.observeRateLimiterWait(verb, u, latency)
+	}
+}
+
+// activate makes the tracer receive client-go rate limiter latencies.
+func (t *RequestTracer) activate() {
+	registerMetricsOnce.Do(func() {
+		metrics.Register(metrics.RegisterOpts{RateLimiterLatency: rateLimiterLatency{}})
+	})
+	activeTracer.Store(t)
+}
+
+// WrapTransport wraps the round tripper to trace every request, it can be used as rest.Config WrapTransport.
+func (t *RequestTracer) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &tracingRoundTripper{tracer: t, delegate: rt}
+}
+
+type tracingRoundTripper struct {
+	tracer   *RequestTracer
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := newRequestKey(req.Method, *req.URL)
+	start := time.Now()
+	resp, err := rt.delegate.RoundTrip(req)
+	rt.tracer.observeRequest(key, max(req.ContentLength, 0), time.Since(start), resp, err)
+	if resp != nil && resp.Body != nil {
+		resp.Body = &countingReadCloser{ReadCloser: resp.Body, onClose: func(n int64) {
+			rt.tracer.observeResponseBytes(key, n)
+		}}
+	}
+	return resp, err
+}
+
+// countingReadCloser counts the bytes read from the body and reports them once when the body is closed.
+type countingReadCloser struct {
+	io.ReadCloser
+	read    int64
+	once    sync.Once
+	onClose func(int64)
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+func (c *countingReadCloser) Close() error {
+	c.once.Do(func() { c.onClose(c.read) })
+	return c.ReadCloser.Close()
+}
+
+func (t *RequestTracer) data(key requestKey) *requestData {
+	data, ok := t.requests[key]
+	if !ok {
+		data = &requestData{statusCodes: make(map[string]int)}
+		t.requests[key] = data
+	}
+	return data
+}
+
+func (t *RequestTracer) observeRequest(key requestKey, requestBytes int64, latency time.Duration, resp *http.Response, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	data := t.data(key)
+	data.count++
+	data.requestBytes += requestBytes
+	data.latencies = append(data.latencies, latency)
+	if err != nil || resp == nil {
+		data.errors++
+		return
+	}
+	data.statusCodes[strconv.Itoa(resp.StatusCode)]++
+}
+
+func (t *RequestTracer) observeResponseBytes(key requestKey, n int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.data(key).responseBytes += n
+}
+
+func (t *RequestTracer) observeRateLimiterWait(method string, u url.URL, wait time.Duration) {
+	key := newRequestKey(method, u)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	data := t.data(key)
+	data.waits = append(data.waits, wait)
+}
+
+// Report returns the stats of all traced requests.
+func (t *RequestTracer) Report() RequestReport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	keys := make([]requestKey, 0, len(t.requests))
+	for key := range t.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].resource != keys[j].resource {
+			return keys[i].resource < keys[j].resource
+		}
+		return keys[i].verb < keys[j].verb
+	})
+
+	var report RequestReport
+	var latencies, waits []time.Duration
+	for _, key := range keys {
+		data := t.requests[key]
+		statusCodes := make(map[string]int, len(data.statusCodes))
+		for code, count := range data.statusCodes {
+			statusCodes[code] = count
+		}
+		s := RequestStats{
+			Verb:            key.verb,
+			Resource:        key.resource,
+			Count:           data.count,
+			Errors:          data.errors,
+			StatusCodes:     statusCodes,
+			RequestBytes:    data.requestBytes,
+			ResponseBytes:   data.responseBytes,
+			Latency:         stats.SummarizeDurations(data.latencies),
+			RateLimiterWait: stats.SummarizeDurations(data.waits),
+			Throttled:       countThrottled(data.waits),
+		}
+		report.Requests = append(report.Requests, s)
+		report.Throttled += s.Throttled
+		latencies = append(latencies, data.latencies...)
+		waits = append(waits, data.waits...)
+	}
+	report.Latency = stats.SummarizeDurations(latencies)
+	report.RateLimiterWait = stats.SummarizeDurations(waits)
+	return report
+}
+
+func countThrottled(waits []time.Duration) int {
+	throttled := 0
+	for _, wait := range waits {
+		if wait > throttledThreshold {
+			throttled++
+		}
+	}
+	return throttled
+}
+
+// newRequestKey derives the Kubernetes API verb and resource from the HTTP method and URL of a request.
+func newRequestKey(method string, u url.URL) requestKey {
+	resource, named := resourceFromPath(u.Path)
+	return requestKey{verb: verbFromMethod(method, named, u.Query().Get("watch")), resource: resource}
+}
+
+func verbFromMethod(method string, named bool, watch string) string {
+	switch method {
+	case http.MethodGet:
+		switch {
+		case watch == "true" || watch == "1":
+			return "watch"
+		case named:
+			return "get"
+		default:
+			return "list"
+		}
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		if named {
+			return "delete"
+		}
+		return "deletecollection"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// resourceFromPath returns the resource of an API path and whether the path names a single object.
+// Paths which are not resource paths (e.g. /version, /metrics) are returned as they are.
+func resourceFromPath(path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	group := ""
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		group = segments[1]
+		segments = segments[3:]
+	default:
+		return path, false
+	}
+	// namespaced paths, except for subresources of namespaces themselves
+	if len(segments) >= 3 && segments[0] == "namespaces" && segments[2] != "status" && segments[2] != "finalize" {
+		segments = segments[2:]
+	}
+	resource := segments[0]
+	if group != "" {
+		resource += "." + group
+	}
+	if len(segments) >= 3 {
+		resource += "/" + segments[2]
+	}
+	return resource, len(segments) >= 2
+}
@@ -0,0 +1,104 @@
+package k8s
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequestTracer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/default/pods/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+	}))
+	defer server.Close()
+
+	tracer := NewRequestTracer()
+	restConfig := &rest.Config{Host: server.URL}
+	applyConfig(restConfig, Config{QPS: 20, Burst: 1, Tracer: tracer})
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := client.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+	}
+	_, err = client.CoreV1().Pods("default").Get(ctx, "missing", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	report := tracer.Report()
+	assert.Len(t, report.Requests, 2)
+	get, list := report.Requests[0], report.Requests[1]
+
+	assert.Equal(t, "get", get.Verb)
+	assert.Equal(t, "pods", get.Resource)
+	assert.Equal(t, map[string]int{"404": 1}, get.StatusCodes)
+
+	assert.Equal(t, "list", list.Verb)
+	assert.Equal(t, 3, list.Count)
+	assert.Equal(t, map[string]int{"200": 3}, list.StatusCodes)
+	assert.Equal(t, int64(3*len(`{"kind":"PodList","apiVersion":"v1","items":[]}`)), list.ResponseBytes)
+	assert.Equal(t, 3, list.Latency.Count)
+	assert.Equal(t, 3, list.RateLimiterWait.Count)
+
+	// with a burst of 1 at 20 QPS, every request after the first waits ~50ms for the rate limiter
+	assert.GreaterOrEqual(t, report.Throttled, 3)
+	assert.Greater(t, report.RateLimiterWait.Max, (20 * time.Millisecond).Seconds())
+	assert.Equal(t, 4, report.Latency.Count)
+}
+
+func TestNewRequestKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method   string
+		url      string
+		verb     string
+		resource string
+	}{
+		{http.MethodPost, "/api/v1/namespaces/default/pods", "create", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods?labelSelector=app", "list", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods?watch=true", "watch", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/{namespace}/pods/{name}", "get", "pods"},
+		{http.MethodPut, "/api/v1/namespaces/default/pods/pod-1/status", "update", "pods/status"},
+		{http.MethodDelete, "/api/v1/nodes", "deletecollection", "nodes"},
+		{http.MethodDelete, "/api/v1/nodes/node-1", "delete", "nodes"},
+		{http.MethodPost, "/apis/batch/v1/namespaces/default/jobs", "create", "jobs.batch"},
+		{http.MethodPatch, "/apis/batch/v1/namespaces/default/jobs/job-1/status", "patch", "jobs.batch/status"},
+		{http.MethodGet, "/api/v1/namespaces", "list", "namespaces"},
+		{http.MethodGet, "/api/v1/namespaces/default", "get", "namespaces"},
+		{http.MethodPut, "/api/v1/namespaces/default/finalize", "update", "namespaces/finalize"},
+		{http.MethodGet, "/metrics", "list", "/metrics"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(tc.url)
+			if err != nil {
+				t.Fatalf("failed to parse url: %v", err)
+			}
+			key := newRequestKey(tc.method, *u)
+			assert.Equal(t, tc.verb, key.verb)
+			assert.Equal(t, tc.resource, key.resource)
+		})
+	}
+}
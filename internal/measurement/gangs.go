@@ -0,0 +1,223 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+// GangPlacement holds the placement of the member pods of a single gang.
+type GangPlacement struct {
+	// Size is the number of member pods of the gang, all of which must be placed for the gang to run.
+	Size int `json:"size"`
+	// Created is the creation timestamp of the first observed member pod.
+	Created time.Time `json:"created"`
+	// Placed holds the time at which every placed member pod was bound to a node, keyed by pod name.
+	Placed map[string]time.Time `json:"placed"`
+	// FullyPlaced is the time at which the last member pod was bound, zero if the gang is not fully placed.
+	FullyPlaced time.Time `json:"fullyPlaced,omitempty"`
+}
+
+// GangReport summarizes the placement of gangs.
+type GangReport struct {
+	// Gangs is the number of tracked gangs.
+	Gangs int `json:"gangs"`
+	// FullyPlaced is the number of gangs whose member pods were all bound to nodes.
+	FullyPlaced int `json:"fullyPlaced"`
+	// PartiallyPlaced is the number of gangs of which some, but not all member pods were bound to nodes,
+	// which a gang scheduler should never do as the bound pods hold resources without being able to run.
+	// Gangs which are still being bound are counted as well, wait with WaitForSettled before taking the report.
+	PartiallyPlaced int `json:"partiallyPlaced"`
+	// Unplaced is the number of gangs of which no member pod was bound to a node.
+	Unplaced int `json:"unplaced"`
+	// TimeToFullPlacement is the distribution of the time between the gang creation and binding its last member pod, in seconds.
+	TimeToFullPlacement stats.Summary `json:"timeToFullPlacement"`
+	// Sizes holds the report of the gangs of every size, it is only set in the report of all gangs.
+	Sizes map[int]GangReport `json:"sizes,omitempty"`
+}
+
+// GangTracker uses a shared informer to track when the member pods of gangs are bound to nodes.
+// Pods are grouped into gangs by the gang label and the gang size is read from the size label.
+type GangTracker struct {
+	informer  cache.SharedIndexInformer
+	gangLabel string
+	sizeLabel string
+	gangs     map[string]*GangPlacement
+	mutex     sync.RWMutex
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// NewGangTracker creates a GangTracker which tracks the member pods matching the labelSelector in the namespace.
+// An empty namespace tracks pods in all namespaces.
+func NewGangTracker(client kubernetes.Interface, namespace, labelSelector, gangLabel, sizeLabel string) *GangTracker {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = labelSelector
+		}),
+	)
+	t := &GangTracker{
+		informer:  factory.Core().V1().Pods().Informer(),
+		gangLabel: gangLabel,
+		sizeLabel: sizeLabel,
+		gangs:     make(map[string]*GangPlacement),
+		now:       time.Now,
+	}
+	_, _ = t.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observe(obj) },
+		UpdateFunc: func(_, obj any) { t.observe(obj) },
+	})
+	return t
+}
+
+// Start runs the informer until the context is cancelled and waits for the initial list to be observed.
+func (t *GangTracker) Start(ctx context.Context) error {
+	go t.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), t.informer.HasSynced) {
+		return fmt.Errorf("failed to sync gang pod informer: %w", ctx.Err())
+	}
+	return nil
+}
+
+// observe records the creation of the gang of the member pod and the binding of the pod.
+func (t *GangTracker) observe(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	gang := pod.Labels[t.gangLabel]
+	if gang == "" {
+		return
+	}
+	now := t.now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := pod.Namespace + "/" + gang
+	placement, ok := t.gangs[key]
+	if !ok {
+		size, _ := strconv.Atoi(pod.Labels[t.sizeLabel])
+		placement = &GangPlacement{Size: size, Created: pod.CreationTimestamp.Time, Placed: make(map[string]time.Time)}
+		t.gangs[key] = placement
+	}
+	if pod.CreationTimestamp.Time.Before(placement.Created) {
+		placement.Created = pod.CreationTimestamp.Time
+	}
+	if _, placed := placement.Placed[pod.Name]; placed || pod.Spec.NodeName == "" {
+		return
+	}
+	boundAt := scheduledAt(pod)
+	if boundAt.IsZero() {
+		boundAt = now
+	}
+	placement.Placed[pod.Name] = boundAt
+	if placement.Size > 0 && len(placement.Placed) >= placement.Size {
+		for _, placedAt := range placement.Placed {
+			if placedAt.After(placement.FullyPlaced) {
+				placement.FullyPlaced = placedAt
+			}
+		}
+	}
+}
+
+// Report returns the placement of all tracked gangs and of the gangs of every size.
+func (t *GangTracker) Report() GangReport {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	type gangData struct {
+		report GangReport
+		times  []time.Duration
+	}
+	var all gangData
+	sizes := make(map[int]*gangData)
+	for _, placement := range t.gangs {
+		data, ok := sizes[placement.Size]
+		if !ok {
+			data = &gangData{}
+			sizes[placement.Size] = data
+		}
+		for _, d := range []*gangData{&all, data} {
+			d.report.Gangs++
+			switch {
+			case !placement.FullyPlaced.IsZero():
+				d.report.FullyPlaced++
+				if placementTime, ok := since(placement.Created, placement.FullyPlaced); ok {
+					d.times = append(d.times, placementTime)
+				}
+			case len(placement.Placed) > 0:
+				d.report.PartiallyPlaced++
+			default:
+				d.report.Unplaced++
+			}
+		}
+	}
+
+	report := all.report
+	report.TimeToFullPlacement = stats.SummarizeDurations(all.times)
+	if len(sizes) > 0 {
+		report.Sizes = make(map[int]GangReport, len(sizes))
+	}
+	for size, data := range sizes {
+		data.report.TimeToFullPlacement = stats.SummarizeDurations(data.times)
+		report.Sizes[size] = data.report
+	}
+	return report
+}
+
+// WaitForSettled blocks until no tracked gang is partially placed and no member pod was bound during the last interval,
+// or the context is cancelled. Gangs which are partially placed when the context is cancelled stayed partial.
+func (t *GangTracker) WaitForSettled(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	previousPlaced := -1
+	for {
+		partial, placed := t.placement()
+		if partial == 0 && placed == previousPlaced {
+			return nil
+		}
+		previousPlaced = placed
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// placement returns the number of partially placed gangs and the number of bound member pods of all gangs.
+func (t *GangTracker) placement() (partial, placed int) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, gang := range t.gangs {
+		placed += len(gang.Placed)
+		if gang.FullyPlaced.IsZero() && len(gang.Placed) > 0 {
+			partial++
+		}
+	}
+	return partial, placed
+}
+
+// GangSizes returns the sorted gang sizes in the report.
+func GangSizes(report GangReport) []int {
+	sizes := make([]int, 0, len(report.Sizes))
+	for size := range report.Sizes {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	return sizes
+}
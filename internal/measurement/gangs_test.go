@@ -0,0 +1,119 @@
+package measurement
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGangTracker_Report(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := created
+	tracker := NewGangTracker(fake.NewSimpleClientset(), "default", "app=fake-pod", "gang", "gang-size")
+	tracker.now = func() time.Time { return now }
+
+	// gang-a is fully placed, its last pod is bound after 3s
+	tracker.observe(newGangPod("gang-a", 0, 2, created, ""))
+	tracker.observe(newGangPod("gang-a", 1, 2, created.Add(1*time.Second), ""))
+	tracker.observe(newGangPod("gang-a", 0, 2, created, "node-1", created.Add(2*time.Second)))
+	now = created.Add(3 * time.Second)
+	// the binding time is the observation time if the pod has no PodScheduled condition
+	tracker.observe(newGangPod("gang-a", 1, 2, created.Add(1*time.Second), "node-2"))
+	// repeated observations of bound pods do not change the placement
+	now = created.Add(10 * time.Second)
+	tracker.observe(newGangPod("gang-a", 1, 2, created.Add(1*time.Second), "node-2"))
+
+	// gang-b is partially placed
+	for i := 0; i < 4; i++ {
+		tracker.observe(newGangPod("gang-b", i, 4, created, ""))
+	}
+	tracker.observe(newGangPod("gang-b", 0, 4, created, "node-1", created.Add(1*time.Second)))
+
+	// gang-c is not placed
+	tracker.observe(newGangPod("gang-c", 0, 4, created, ""))
+	// pods without the gang label are ignored
+	tracker.observe(newPod("standalone", created))
+
+	report := tracker.Report()
+	assert.Equal(t, 3, report.Gangs)
+	assert.Equal(t, 1, report.FullyPlaced)
+	assert.Equal(t, 1, report.PartiallyPlaced)
+	assert.Equal(t, 1, report.Unplaced)
+	assert.Equal(t, 1, report.TimeToFullPlacement.Count)
+	assert.Equal(t, 3.0, report.TimeToFullPlacement.Max)
+	assert.Equal(t, []int{2, 4}, GangSizes(report))
+	assert.Equal(t, 1, report.Sizes[2].FullyPlaced)
+	assert.Equal(t, GangReport{Gangs: 2, PartiallyPlaced: 1, Unplaced: 1}, report.Sizes[4])
+}
+
+func TestGangTracker_Start(t *testing.T) {
+	t.Parallel()
+
+	created := time.Now()
+	client := fake.NewSimpleClientset(
+		newGangPod("gang-a", 0, 1, created, "node-1", created.Add(time.Second)),
+	)
+	tracker := NewGangTracker(client, "default", "app=fake-pod", "gang", "gang-size")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx))
+
+	report := tracker.Report()
+	assert.Equal(t, 1, report.Gangs)
+	assert.Equal(t, 1, report.FullyPlaced)
+}
+
+func TestGangTracker_WaitForSettled(t *testing.T) {
+	t.Parallel()
+
+	created := time.Now()
+	client := fake.NewSimpleClientset(
+		newGangPod("gang-a", 0, 2, created, "node-1", created),
+		newGangPod("gang-a", 1, 2, created, ""),
+	)
+	tracker := NewGangTracker(client, "default", "app=fake-pod", "gang", "gang-size")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx))
+
+	t.Run("partially placed gangs are not settled", func(t *testing.T) {
+		waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer waitCancel()
+		assert.ErrorIs(t, tracker.WaitForSettled(waitCtx, 10*time.Millisecond), context.DeadlineExceeded)
+		assert.Equal(t, 1, tracker.Report().PartiallyPlaced)
+	})
+
+	t.Run("gangs which finished binding are settled", func(t *testing.T) {
+		_, err := client.CoreV1().Pods("default").Update(ctx, newGangPod("gang-a", 1, 2, created, "node-2", created), metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, tracker.WaitForSettled(ctx, 10*time.Millisecond))
+		report := tracker.Report()
+		assert.Equal(t, 1, report.FullyPlaced)
+		assert.Equal(t, 0, report.PartiallyPlaced)
+	})
+}
+
+func newGangPod(gang string, index, size int, created time.Time, nodeName string, scheduled ...time.Time) *corev1.Pod {
+	pod := newPod(gang+"-"+strconv.Itoa(index), created)
+	pod.Labels["gang"] = gang
+	pod.Labels["gang-size"] = strconv.Itoa(size)
+	pod.Spec.NodeName = nodeName
+	if len(scheduled) > 0 {
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(scheduled[0]),
+		}}
+	}
+	return pod
+}
@@ -0,0 +1,337 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+// JobLifecycle holds the time at which a job went through each lifecycle transition.
+// Zero values mean the transition was not observed.
+type JobLifecycle struct {
+	// Class is the value of the job class label.
+	Class string `json:"class"`
+	// Queue is the value of the queue label, it is empty if the job was not submitted to a queue.
+	Queue string `json:"queue,omitempty"`
+	// Created is the creation timestamp of the job.
+	Created time.Time `json:"created"`
+	// Suspended is true if the job was created suspended, e.g. to be admitted by Kueue.
+	Suspended bool `json:"suspended,omitempty"`
+	// Admitted is the time at which the suspended job was unsuspended.
+	Admitted time.Time `json:"admitted,omitempty"`
+	// FirstRunning is the time at which the first pod of the job was observed in the Running phase.
+	FirstRunning time.Time `json:"firstRunning,omitempty"`
+	// Finished is the time at which the job completed or failed.
+	Finished time.Time `json:"finished,omitempty"`
+	// Failed is true if the job failed.
+	Failed bool `json:"failed"`
+}
+
+// JobClassReport summarizes the jobs of a single job class.
+type JobClassReport struct {
+	// Jobs is the number of tracked jobs.
+	Jobs int `json:"jobs"`
+	// Succeeded is the number of jobs which completed successfully.
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of jobs which failed.
+	Failed int `json:"failed"`
+	// QueueWait is the distribution of the time between job creation and its first pod running, in seconds.
+	QueueWait stats.Summary `json:"queueWait"`
+	// CompletionTime is the distribution of the time between job creation and completion, in seconds.
+	CompletionTime stats.Summary `json:"completionTime"`
+	// Makespan is the time between the creation of the first job and the completion of the last one, in seconds.
+	// It is zero until at least one job finished.
+	Makespan float64 `json:"makespan"`
+}
+
+// QueueReport summarizes the admission of the suspended jobs submitted to a single queue.
+type QueueReport struct {
+	// Jobs is the number of tracked jobs which were created suspended.
+	Jobs int `json:"jobs"`
+	// Admitted is the number of jobs which were unsuspended.
+	Admitted int `json:"admitted"`
+	// AdmissionLatency is the distribution of the time between job creation and its unsuspension, in seconds.
+	AdmissionLatency stats.Summary `json:"admissionLatency"`
+	// Throughput is the number of admitted jobs per second between the creation of the first job and the last admission.
+	Throughput float64 `json:"throughput"`
+}
+
+// JobTrackerOption configures a JobTracker.
+type JobTrackerOption func(*JobTracker)
+
+// WithQueueLabel groups suspended jobs into queues by the value of the queueLabel, e.g. the Kueue queue name label,
+// jobs without the label are not reported per queue.
+func WithQueueLabel(queueLabel string) JobTrackerOption {
+	return func(t *JobTracker) {
+		t.queueLabel = queueLabel
+	}
+}
+
+// JobTracker uses shared informers to compute job-level metrics from Job status and the pods owned by each job.
+type JobTracker struct {
+	jobInformer cache.SharedIndexInformer
+	podInformer cache.SharedIndexInformer
+	classLabel  string
+	queueLabel  string
+	jobs        map[types.UID]*JobLifecycle
+	// firstRunning holds the time at which the first pod of a job was observed running keyed by job UID,
+	// pods can be observed before the job which owns them.
+	firstRunning map[types.UID]time.Time
+	mutex        sync.RWMutex
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// NewJobTracker creates a JobTracker for jobs matching the jobSelector and pods matching the podSelector in the namespace.
+// Jobs are grouped into classes by the value of the classLabel, jobs without the label belong to the empty class.
+func NewJobTracker(client kubernetes.Interface, namespace, jobSelector, podSelector, classLabel string, opts ...JobTrackerOption) *JobTracker {
+	newFactory := func(selector string) informers.SharedInformerFactory {
+		return informers.NewSharedInformerFactoryWithOptions(
+			client,
+			0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.LabelSelector = selector
+			}),
+		)
+	}
+	t := &JobTracker{
+		jobInformer:  newFactory(jobSelector).Batch().V1().Jobs().Informer(),
+		podInformer:  newFactory(podSelector).Core().V1().Pods().Informer(),
+		classLabel:   classLabel,
+		jobs:         make(map[types.UID]*JobLifecycle),
+		firstRunning: make(map[types.UID]time.Time),
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Start runs the informers until the context is cancelled and waits for the initial lists to be observed.
+func (t *JobTracker) Start(ctx context.Context) error {
+	jobRegistration, err := t.jobInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observeJob(obj) },
+		UpdateFunc: func(_, obj any) { t.observeJob(obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register job event handler: %w", err)
+	}
+	podRegistration, err := t.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observePod(obj) },
+		UpdateFunc: func(_, obj any) { t.observePod(obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register pod event handler: %w", err)
+	}
+	go t.jobInformer.Run(ctx.Done())
+	go t.podInformer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), jobRegistration.HasSynced, podRegistration.HasSynced) {
+		return fmt.Errorf("failed to sync job informers: %w", ctx.Err())
+	}
+	return nil
+}
+
+// observeJob records the creation, admission and completion of the job.
+// Deleted jobs are kept, as finished fake jobs are garbage collected shortly after completion.
+func (t *JobTracker) observeJob(obj any) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	lifecycle, ok := t.jobs[job.UID]
+	if !ok {
+		lifecycle = &JobLifecycle{
+			Class:     job.Labels[t.classLabel],
+			Created:   job.CreationTimestamp.Time,
+			Suspended: isSuspended(job),
+		}
+		if t.queueLabel != "" {
+			lifecycle.Queue = job.Labels[t.queueLabel]
+		}
+		t.jobs[job.UID] = lifecycle
+	}
+	if lifecycle.Suspended && lifecycle.Admitted.IsZero() && !isSuspended(job) {
+		lifecycle.Admitted = t.resumedAt(job)
+	}
+	if lifecycle.Finished.IsZero() {
+		lifecycle.Finished, lifecycle.Failed = finishedAt(job)
+	}
+}
+
+// isSuspended returns true if the job is suspended.
+func isSuspended(job *batchv1.Job) bool {
+	return job.Spec.Suspend != nil && *job.Spec.Suspend
+}
+
+// resumedAt returns the time at which the job was unsuspended, which is the transition of its Suspended condition to false,
+// or the current time if the job controller did not update the condition yet.
+func (t *JobTracker) resumedAt(job *batchv1.Job) time.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobSuspended && condition.Status == corev1.ConditionFalse && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return t.now()
+}
+
+// finishedAt returns the time at which the job completed or failed, and whether it failed.
+func finishedAt(job *batchv1.Job) (time.Time, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return condition.LastTransitionTime.Time, false
+		case batchv1.JobFailed:
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time, false
+	}
+	return time.Time{}, false
+}
+
+// observePod records the first time a pod owned by a job is observed running.
+func (t *JobTracker) observePod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Status.Phase != corev1.PodRunning {
+		return
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "Job" {
+		return
+	}
+	now := t.now()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.firstRunning[owner.UID]; !ok {
+		t.firstRunning[owner.UID] = now
+	}
+}
+
+// Report returns the job metrics keyed by job class.
+func (t *JobTracker) Report() map[string]JobClassReport {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	type classData struct {
+		report                    JobClassReport
+		queueWait, completionTime []time.Duration
+		firstCreated, lastDone    time.Time
+	}
+	classes := make(map[string]*classData)
+	for uid, lifecycle := range t.jobs {
+		data, ok := classes[lifecycle.Class]
+		if !ok {
+			data = &classData{}
+			classes[lifecycle.Class] = data
+		}
+		data.report.Jobs++
+		if data.firstCreated.IsZero() || lifecycle.Created.Before(data.firstCreated) {
+			data.firstCreated = lifecycle.Created
+		}
+		if d, ok := since(lifecycle.Created, t.firstRunning[uid]); ok {
+			data.queueWait = append(data.queueWait, d)
+		}
+		if lifecycle.Finished.IsZero() {
+			continue
+		}
+		if lifecycle.Failed {
+			data.report.Failed++
+		} else {
+			data.report.Succeeded++
+		}
+		if d, ok := since(lifecycle.Created, lifecycle.Finished); ok {
+			data.completionTime = append(data.completionTime, d)
+		}
+		if lifecycle.Finished.After(data.lastDone) {
+			data.lastDone = lifecycle.Finished
+		}
+	}
+
+	reports := make(map[string]JobClassReport, len(classes))
+	for class, data := range classes {
+		data.report.QueueWait = stats.SummarizeDurations(data.queueWait)
+		data.report.CompletionTime = stats.SummarizeDurations(data.completionTime)
+		if makespan, ok := since(data.firstCreated, data.lastDone); ok {
+			data.report.Makespan = makespan.Seconds()
+		}
+		reports[class] = data.report
+	}
+	return reports
+}
+
+// QueueReport returns the admission metrics of suspended jobs keyed by queue, jobs without a queue are not reported.
+func (t *JobTracker) QueueReport() map[string]QueueReport {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	type queueData struct {
+		report                  QueueReport
+		latencies               []time.Duration
+		firstCreated, lastAdmit time.Time
+	}
+	queues := make(map[string]*queueData)
+	for _, lifecycle := range t.jobs {
+		if lifecycle.Queue == "" || !lifecycle.Suspended {
+			continue
+		}
+		data, ok := queues[lifecycle.Queue]
+		if !ok {
+			data = &queueData{}
+			queues[lifecycle.Queue] = data
+		}
+		data.report.Jobs++
+		if data.firstCreated.IsZero() || lifecycle.Created.Before(data.firstCreated) {
+			data.firstCreated = lifecycle.Created
+		}
+		if lifecycle.Admitted.IsZero() {
+			continue
+		}
+		data.report.Admitted++
+		if d, ok := since(lifecycle.Created, lifecycle.Admitted); ok {
+			data.latencies = append(data.latencies, d)
+		}
+		if lifecycle.Admitted.After(data.lastAdmit) {
+			data.lastAdmit = lifecycle.Admitted
+		}
+	}
+
+	reports := make(map[string]QueueReport, len(queues))
+	for queue, data := range queues {
+		data.report.AdmissionLatency = stats.SummarizeDurations(data.latencies)
+		if d, ok := since(data.firstCreated, data.lastAdmit); ok && d > 0 {
+			data.report.Throughput = float64(data.report.Admitted) / d.Seconds()
+		}
+		reports[queue] = data.report
+	}
+	return reports
+}
+
+// Classes returns the sorted job classes in the report.
+func Classes(report map[string]JobClassReport) []string {
+	classes := make([]string, 0, len(report))
+	for class := range report {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
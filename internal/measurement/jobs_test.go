@@ -0,0 +1,150 @@
+package measurement
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"testing"
+	"time"
+)
+
+func TestJobTracker_Report(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := created
+	tracker := NewJobTracker(fake.NewSimpleClientset(), "default", "app=fake-job", "app=fake-pod", "job-class")
+	tracker.now = func() time.Time { return now }
+
+	// pods can be observed before the job which owns them
+	now = created.Add(2 * time.Second)
+	tracker.observePod(newJobPod("job-1", corev1.PodRunning))
+	tracker.observeJob(newJob("job-1", "small", created, ""))
+	tracker.observeJob(newJob("job-1", "small", created, batchv1.JobComplete, created.Add(5*time.Second)))
+
+	tracker.observeJob(newJob("job-2", "small", created.Add(1*time.Second), ""))
+	now = created.Add(5 * time.Second)
+	tracker.observePod(newJobPod("job-2", corev1.PodPending))
+	tracker.observePod(newJobPod("job-2", corev1.PodRunning))
+	now = created.Add(6 * time.Second)
+	tracker.observePod(newJobPod("job-2", corev1.PodRunning))
+	tracker.observeJob(newJob("job-2", "small", created.Add(1*time.Second), batchv1.JobFailed, created.Add(9*time.Second)))
+
+	tracker.observeJob(newJob("job-3", "large", created, ""))
+
+	report := tracker.Report()
+	assert.Equal(t, []string{"large", "small"}, Classes(report))
+
+	small := report["small"]
+	assert.Equal(t, 2, small.Jobs)
+	assert.Equal(t, 1, small.Succeeded)
+	assert.Equal(t, 1, small.Failed)
+	assert.Equal(t, 2, small.QueueWait.Count)
+	assert.Equal(t, 2.0, small.QueueWait.Min)
+	assert.Equal(t, 4.0, small.QueueWait.Max)
+	assert.Equal(t, 5.0, small.CompletionTime.Min)
+	assert.Equal(t, 8.0, small.CompletionTime.Max)
+	assert.Equal(t, 9.0, small.Makespan)
+
+	assert.Equal(t, JobClassReport{Jobs: 1}, report["large"])
+}
+
+func TestJobTracker_QueueReport(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := created
+	tracker := NewJobTracker(fake.NewSimpleClientset(), "default", "app=fake-job", "app=fake-pod", "job-class", WithQueueLabel("queue"))
+	tracker.now = func() time.Time { return now }
+
+	queued := func(name, queue string, suspend bool) *batchv1.Job {
+		job := newJob(name, "default", created, "")
+		job.Labels["queue"] = queue
+		job.Spec.Suspend = ptr.To(suspend)
+		return job
+	}
+	tracker.observeJob(queued("job-1", "simulator", true))
+	tracker.observeJob(queued("job-2", "simulator", true))
+	tracker.observeJob(queued("job-3", "other", true))
+	// jobs which were not created suspended are not admitted by the queue
+	tracker.observeJob(queued("job-4", "simulator", false))
+
+	// the admission time is taken from the Suspended condition
+	admitted := queued("job-1", "simulator", false)
+	admitted.Status.Conditions = []batchv1.JobCondition{{
+		Type:               batchv1.JobSuspended,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(created.Add(2 * time.Second)),
+	}}
+	tracker.observeJob(admitted)
+	// or the observation time if the condition was not updated yet
+	now = created.Add(4 * time.Second)
+	tracker.observeJob(queued("job-2", "simulator", false))
+
+	report := tracker.QueueReport()
+	assert.Len(t, report, 2)
+	simulator := report["simulator"]
+	assert.Equal(t, 2, simulator.Jobs)
+	assert.Equal(t, 2, simulator.Admitted)
+	assert.Equal(t, 2.0, simulator.AdmissionLatency.Min)
+	assert.Equal(t, 4.0, simulator.AdmissionLatency.Max)
+	assert.Equal(t, 0.5, simulator.Throughput)
+	assert.Equal(t, QueueReport{Jobs: 1}, report["other"])
+}
+
+func TestJobTracker_Start(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset(
+		newJob("job-1", "default", time.Now(), batchv1.JobComplete, time.Now()),
+		newJobPod("job-1", corev1.PodRunning),
+	)
+	tracker := NewJobTracker(client, "default", "app=fake-job", "app=fake-pod", "job-class")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx))
+
+	report := tracker.Report()["default"]
+	assert.Equal(t, 1, report.Jobs)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, 1, report.QueueWait.Count)
+}
+
+func newJob(name, class string, created time.Time, condition batchv1.JobConditionType, finished ...time.Time) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name),
+			Labels:            map[string]string{"app": "fake-job", "job-class": class},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:               condition,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(finished[0]),
+		}}
+	}
+	return job
+}
+
+func newJobPod(jobName string, phase corev1.PodPhase) *corev1.Pod {
+	pod := newPod(jobName+"-pod", time.Now())
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       jobName,
+		UID:        types.UID(jobName),
+		Controller: ptr.To(true),
+	}}
+	pod.Status.Phase = phase
+	return pod
+}
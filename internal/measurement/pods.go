@@ -0,0 +1,372 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+// PodLifecycle holds the time at which a pod went through each lifecycle transition.
+// Zero values mean the transition was not observed.
+type PodLifecycle struct {
+	// Created is the creation timestamp of the pod.
+	Created time.Time `json:"created"`
+	// Scheduled is the time at which the PodScheduled condition became true.
+	Scheduled time.Time `json:"scheduled,omitempty"`
+	// Running is the time at which the first container of the pod started, or the Ready condition became true if the
+	// container statuses carry no start time. It is the time the Running phase was observed if neither is set.
+	Running time.Time `json:"running,omitempty"`
+	// Finished is the time at which the last container of the pod terminated, or the time the Succeeded or Failed phase
+	// was observed if the container statuses carry no finish time.
+	Finished time.Time `json:"finished,omitempty"`
+	// Deleted is the time at which the pod deletion was observed.
+	Deleted time.Time `json:"deleted,omitempty"`
+	// Phase is the last observed phase of the pod.
+	Phase corev1.PodPhase `json:"phase"`
+}
+
+// PhaseCounts holds the number of tracked pods in each phase.
+type PhaseCounts struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Unknown   int `json:"unknown"`
+	// Deleted is the number of tracked pods which were deleted, they are not counted in any phase.
+	Deleted int `json:"deleted"`
+}
+
+// Active returns the number of pods which have not finished yet.
+func (c PhaseCounts) Active() int {
+	return c.Pending + c.Running + c.Unknown
+}
+
+func (c PhaseCounts) String() string {
+	return fmt.Sprintf(
+		"(pending: %d, running: %d, succeeded: %d, failed: %d, unknown: %d, deleted: %d)",
+		c.Pending, c.Running, c.Succeeded, c.Failed, c.Unknown, c.Deleted,
+	)
+}
+
+// PhaseSample holds the number of tracked pods in each phase at a point in time.
+type PhaseSample struct {
+	// Time is the time at which the pods were counted.
+	Time time.Time `json:"time"`
+	PhaseCounts
+}
+
+// PodReport summarizes the lifecycle latencies of all tracked pods.
+type PodReport struct {
+	// Counts is the number of tracked pods in each phase.
+	Counts PhaseCounts `json:"counts"`
+	// SchedulingLatency is the distribution of the time between creation and scheduling, in seconds.
+	SchedulingLatency stats.Summary `json:"schedulingLatency"`
+	// StartupLatency is the distribution of the time between creation and running, in seconds.
+	StartupLatency stats.Summary `json:"startupLatency"`
+	// EndToEndLatency is the distribution of the time between creation and completion, in seconds.
+	EndToEndLatency stats.Summary `json:"endToEndLatency"`
+	// SchedulingHistogram counts the scheduling latencies in buckets.
+	SchedulingHistogram stats.Histogram `json:"schedulingHistogram"`
+	// StartupHistogram counts the startup latencies in buckets.
+	StartupHistogram stats.Histogram `json:"startupHistogram"`
+}
+
+// PodTracker uses a shared informer to timestamp the lifecycle transitions of pods.
+type PodTracker struct {
+	informer cache.SharedIndexInformer
+	pods     map[string]*PodLifecycle
+	// timeSeries holds the phase counts recorded by Record.
+	timeSeries []PhaseSample
+	mutex      sync.RWMutex
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// NewPodTracker creates a PodTracker which tracks pods matching the labelSelector in the namespace.
+// An empty namespace tracks pods in all namespaces.
+func NewPodTracker(client kubernetes.Interface, namespace, labelSelector string) *PodTracker {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = labelSelector
+		}),
+	)
+	t := &PodTracker{
+		informer: factory.Core().V1().Pods().Informer(),
+		pods:     make(map[string]*PodLifecycle),
+		now:      time.Now,
+	}
+	_, _ = t.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observe(obj) },
+		UpdateFunc: func(_, obj any) { t.observe(obj) },
+		DeleteFunc: t.observeDeleted,
+	})
+	return t
+}
+
+// Start runs the informer until the context is cancelled and waits for the initial list to be observed.
+func (t *PodTracker) Start(ctx context.Context) error {
+	go t.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), t.informer.HasSynced) {
+		return fmt.Errorf("failed to sync pod informer: %w", ctx.Err())
+	}
+	return nil
+}
+
+// observe records the transitions visible in the current state of the pod.
+func (t *PodTracker) observe(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	now := t.now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	lifecycle, ok := t.pods[podKey(pod)]
+	if !ok {
+		lifecycle = &PodLifecycle{Created: pod.CreationTimestamp.Time}
+		t.pods[podKey(pod)] = lifecycle
+	}
+	lifecycle.Phase = pod.Status.Phase
+	if lifecycle.Scheduled.IsZero() {
+		lifecycle.Scheduled = scheduledAt(pod)
+	}
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		if lifecycle.Running.IsZero() {
+			lifecycle.Running = orNow(podRunningAt(pod), now)
+		}
+	case corev1.PodSucceeded, corev1.PodFailed:
+		if lifecycle.Running.IsZero() {
+			lifecycle.Running = podRunningAt(pod)
+		}
+		if lifecycle.Finished.IsZero() {
+			lifecycle.Finished = orNow(podFinishedAt(pod), now)
+		}
+	}
+}
+
+// observeDeleted records the deletion of the pod.
+func (t *PodTracker) observeDeleted(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	t.observe(pod)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.pods[podKey(pod)].Deleted = t.now()
+}
+
+// scheduledAt returns the time at which the PodScheduled condition became true, or zero if the pod is not scheduled.
+func scheduledAt(pod *corev1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// podRunningAt returns the time at which the first container of the pod started, falling back to the time at which
+// the Ready condition became true, or zero if neither is set.
+func podRunningAt(pod *corev1.Pod) time.Time {
+	var started time.Time
+	for i := range pod.Status.ContainerStatuses {
+		state := &pod.Status.ContainerStatuses[i].State
+		var at time.Time
+		switch {
+		case state.Running != nil:
+			at = state.Running.StartedAt.Time
+		case state.Terminated != nil:
+			at = state.Terminated.StartedAt.Time
+		}
+		if !at.IsZero() && (started.IsZero() || at.Before(started)) {
+			started = at
+		}
+	}
+	if !started.IsZero() {
+		return started
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// podFinishedAt returns the time at which the last container of the pod terminated, or zero if no container has a finish time.
+func podFinishedAt(pod *corev1.Pod) time.Time {
+	var finished time.Time
+	for i := range pod.Status.ContainerStatuses {
+		if terminated := pod.Status.ContainerStatuses[i].State.Terminated; terminated != nil && terminated.FinishedAt.After(finished) {
+			finished = terminated.FinishedAt.Time
+		}
+	}
+	return finished
+}
+
+// orNow returns the time if it is set and now otherwise.
+func orNow(t, now time.Time) time.Time {
+	if t.IsZero() {
+		return now
+	}
+	return t
+}
+
+func podKey(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+// Counts returns the number of tracked pods in each phase.
+func (t *PodTracker) Counts() PhaseCounts {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var counts PhaseCounts
+	for _, lifecycle := range t.pods {
+		if !lifecycle.Deleted.IsZero() {
+			counts.Deleted++
+			continue
+		}
+		switch lifecycle.Phase {
+		case corev1.PodPending, "":
+			counts.Pending++
+		case corev1.PodRunning:
+			counts.Running++
+		case corev1.PodSucceeded:
+			counts.Succeeded++
+		case corev1.PodFailed:
+			counts.Failed++
+		default:
+			counts.Unknown++
+		}
+	}
+	return counts
+}
+
+// Report returns the phase counts and the latency distributions of all tracked pods.
+func (t *PodTracker) Report() PodReport {
+	counts := t.Counts()
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var scheduling, startup, endToEnd []time.Duration
+	for _, lifecycle := range t.pods {
+		if d, ok := since(lifecycle.Created, lifecycle.Scheduled); ok {
+			scheduling = append(scheduling, d)
+		}
+		if d, ok := since(lifecycle.Created, lifecycle.Running); ok {
+			startup = append(startup, d)
+		}
+		if d, ok := since(lifecycle.Created, lifecycle.Finished); ok {
+			endToEnd = append(endToEnd, d)
+		}
+	}
+	return PodReport{
+		Counts:              counts,
+		SchedulingLatency:   stats.SummarizeDurations(scheduling),
+		StartupLatency:      stats.SummarizeDurations(startup),
+		EndToEndLatency:     stats.SummarizeDurations(endToEnd),
+		SchedulingHistogram: stats.NewDurationHistogram(scheduling),
+		StartupHistogram:    stats.NewDurationHistogram(startup),
+	}
+}
+
+// Record records the phase counts every interval until the context is cancelled.
+func (t *PodTracker) Record(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.record()
+		}
+	}
+}
+
+func (t *PodTracker) record() {
+	sample := PhaseSample{Time: t.now(), PhaseCounts: t.Counts()}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.timeSeries = append(t.timeSeries, sample)
+}
+
+// TimeSeries returns a copy of the phase counts recorded by Record.
+func (t *PodTracker) TimeSeries() []PhaseSample {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	timeSeries := make([]PhaseSample, len(t.timeSeries))
+	copy(timeSeries, t.timeSeries)
+	return timeSeries
+}
+
+// since returns the non-negative duration between start and end, and false if either of them was not observed.
+func since(start, end time.Time) (time.Duration, bool) {
+	if start.IsZero() || end.IsZero() {
+		return 0, false
+	}
+	return max(end.Sub(start), 0), true
+}
+
+// WaitForCompletion blocks until none of the tracked pods are pending or running, or the context is cancelled.
+// The onTick function, if provided, is called with the current phase counts every interval.
+func (t *PodTracker) WaitForCompletion(ctx context.Context, interval time.Duration, onTick func(PhaseCounts)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		counts := t.Counts()
+		if onTick != nil {
+			onTick(counts)
+		}
+		if counts.Active() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForSettled blocks until none of the tracked pods are pending and the phase counts did not change during the last
+// interval, or the context is cancelled. Unlike WaitForCompletion it does not wait for running pods to finish.
+// The onTick function, if provided, is called with the current phase counts every interval.
+func (t *PodTracker) WaitForSettled(ctx context.Context, interval time.Duration, onTick func(PhaseCounts)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var previous *PhaseCounts
+	for {
+		counts := t.Counts()
+		if onTick != nil {
+			onTick(counts)
+		}
+		if counts.Pending == 0 && previous != nil && *previous == counts {
+			return nil
+		}
+		previous = &counts
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package measurement
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"testing"
+	"time"
+)
+
+func TestPodTracker_Report(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := created
+	tracker := NewPodTracker(fake.NewSimpleClientset(), "default", "app=fake-pod")
+	tracker.now = func() time.Time { return now }
+
+	pod := newPod("pod-1", created)
+	tracker.observe(pod.DeepCopy())
+	assert.Equal(t, PhaseCounts{Pending: 1}, tracker.Counts())
+
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(created.Add(1 * time.Second)),
+	}}
+	now = created.Add(2 * time.Second)
+	tracker.observe(pod.DeepCopy())
+
+	pod.Status.Phase = corev1.PodRunning
+	now = created.Add(3 * time.Second)
+	tracker.observe(pod.DeepCopy())
+	assert.Equal(t, PhaseCounts{Running: 1}, tracker.Counts())
+
+	pod.Status.Phase = corev1.PodSucceeded
+	now = created.Add(10 * time.Second)
+	tracker.observe(pod.DeepCopy())
+	// repeated observations must not move recorded transitions
+	now = created.Add(20 * time.Second)
+	tracker.observe(pod.DeepCopy())
+
+	other := newPod("pod-2", created)
+	tracker.observe(other)
+	tracker.observeDeleted(cache.DeletedFinalStateUnknown{Key: "default/pod-2", Obj: other})
+
+	report := tracker.Report()
+	assert.Equal(t, PhaseCounts{Succeeded: 1, Deleted: 1}, report.Counts)
+	assert.Equal(t, 1, report.SchedulingLatency.Count)
+	assert.Equal(t, 1.0, report.SchedulingLatency.P50)
+	assert.Equal(t, 3.0, report.StartupLatency.P50)
+	assert.Equal(t, 10.0, report.EndToEndLatency.P50)
+	assert.Equal(t, 1, report.SchedulingHistogram.Counts[7])
+	assert.Equal(t, 1, report.StartupHistogram.Counts[9])
+
+	tracker.record()
+	assert.Equal(t, []PhaseSample{{Time: now, PhaseCounts: PhaseCounts{Succeeded: 1, Deleted: 1}}}, tracker.TimeSeries())
+}
+
+func TestPodTracker_StatusTimestamps(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewPodTracker(fake.NewSimpleClientset(), "default", "app=fake-pod")
+	// observations are late, the transitions must be taken from the pod status
+	tracker.now = func() time.Time { return created.Add(time.Minute) }
+
+	running := newPod("running", created)
+	running.Status.Phase = corev1.PodRunning
+	running.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(3 * time.Second))}}},
+		{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(2 * time.Second))}}},
+	}
+	tracker.observe(running)
+
+	ready := newPod("ready", created)
+	ready.Status.Phase = corev1.PodRunning
+	ready.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(created.Add(4 * time.Second)),
+	}}
+	tracker.observe(ready)
+
+	// a pod which is first observed after it finished
+	finished := newPod("finished", created)
+	finished.Status.Phase = corev1.PodSucceeded
+	finished.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+		StartedAt:  metav1.NewTime(created.Add(5 * time.Second)),
+		FinishedAt: metav1.NewTime(created.Add(10 * time.Second)),
+	}}}}
+	tracker.observe(finished)
+
+	assert.Equal(t, created.Add(2*time.Second), tracker.pods["default/running"].Running)
+	assert.Equal(t, created.Add(4*time.Second), tracker.pods["default/ready"].Running)
+	assert.Equal(t, created.Add(5*time.Second), tracker.pods["default/finished"].Running)
+	assert.Equal(t, created.Add(10*time.Second), tracker.pods["default/finished"].Finished)
+}
+
+func TestPodTracker_WaitForCompletion(t *testing.T) {
+	t.Parallel()
+
+	pod := newPod("pod-1", time.Now())
+	client := fake.NewSimpleClientset(pod)
+	tracker := NewPodTracker(client, "default", "app=fake-pod")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx))
+	assert.Equal(t, 1, tracker.Counts().Pending)
+
+	go func() {
+		completed := pod.DeepCopy()
+		completed.Status.Phase = corev1.PodSucceeded
+		_, _ = client.CoreV1().Pods("default").UpdateStatus(ctx, completed, metav1.UpdateOptions{})
+	}()
+
+	ticks := 0
+	err := tracker.WaitForCompletion(ctx, 10*time.Millisecond, func(PhaseCounts) { ticks++ })
+	assert.NoError(t, err)
+	assert.Greater(t, ticks, 0)
+	assert.Equal(t, 1, tracker.Counts().Succeeded)
+	assert.Equal(t, 1, tracker.Report().EndToEndLatency.Count)
+}
+
+func TestPodTracker_WaitForSettled(t *testing.T) {
+	t.Parallel()
+
+	pod := newPod("pod-1", time.Now())
+	client := fake.NewSimpleClientset(pod)
+	tracker := NewPodTracker(client, "default", "app=fake-pod")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx))
+
+	t.Run("pending pods are not settled", func(t *testing.T) {
+		waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer waitCancel()
+		assert.ErrorIs(t, tracker.WaitForSettled(waitCtx, 10*time.Millisecond, nil), context.DeadlineExceeded)
+	})
+
+	t.Run("running pods are settled", func(t *testing.T) {
+		running := pod.DeepCopy()
+		running.Status.Phase = corev1.PodRunning
+		_, err := client.CoreV1().Pods("default").UpdateStatus(ctx, running, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, tracker.WaitForSettled(ctx, 10*time.Millisecond, nil))
+		assert.Equal(t, 1, tracker.Counts().Running)
+	})
+}
+
+func newPod(name string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "fake-pod"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
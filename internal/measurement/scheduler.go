@@ -0,0 +1,284 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+const (
+	// ReasonFailedScheduling is the reason of events emitted by the scheduler when a pod cannot be scheduled.
+	ReasonFailedScheduling = "FailedScheduling"
+	// DefaultSaturationWindow is the number of consecutive samples in which the pending queue has to grow
+	// for the scheduler to be considered saturated.
+	DefaultSaturationWindow = 10
+)
+
+// SchedulerSample is the scheduler throughput observed in a single interval.
+type SchedulerSample struct {
+	// Time is the time at which the interval ended.
+	Time time.Time `json:"time"`
+	// Bound is the number of pods which were bound to a node in the interval.
+	Bound int `json:"bound"`
+	// BoundPerSecond is the number of pods bound per second in the interval.
+	BoundPerSecond float64 `json:"boundPerSecond"`
+	// FailedScheduling is the number of FailedScheduling events observed in the interval.
+	FailedScheduling int `json:"failedScheduling"`
+	// Pending is the number of pods waiting to be bound at the end of the interval.
+	Pending int `json:"pending"`
+}
+
+// SchedulerReport summarizes the scheduler throughput over all recorded samples.
+type SchedulerReport struct {
+	// Bound is the total number of pods bound while the tracker was running.
+	Bound int `json:"bound"`
+	// FailedScheduling is the total number of FailedScheduling events observed while the tracker was running.
+	FailedScheduling int `json:"failedScheduling"`
+	// Throughput is the distribution of pods bound per second across samples.
+	Throughput stats.Summary `json:"throughput"`
+	// PeakPending is the largest number of pods waiting to be bound.
+	PeakPending int `json:"peakPending"`
+	// Saturated is true if the pending queue kept growing for the whole saturation window.
+	Saturated bool `json:"saturated"`
+	// SaturatedAt is the time at which saturation was first detected.
+	SaturatedAt *time.Time `json:"saturatedAt,omitempty"`
+}
+
+// SchedulerTrackerOption configures a SchedulerTracker.
+type SchedulerTrackerOption func(*SchedulerTracker)
+
+// WithSaturationWindow sets the number of consecutive growing samples after which the scheduler is considered saturated.
+func WithSaturationWindow(window int) SchedulerTrackerOption {
+	return func(t *SchedulerTracker) {
+		t.saturationWindow = window
+	}
+}
+
+// SchedulerTracker derives scheduler throughput from pod spec.nodeName updates and FailedScheduling events.
+type SchedulerTracker struct {
+	podInformer   cache.SharedIndexInformer
+	eventInformer cache.SharedIndexInformer
+	// bound holds whether each tracked pod is bound to a node.
+	bound map[string]bool
+	// synced is set once the initial pod and event lists were observed,
+	// bindings and events which happened before are not counted towards throughput.
+	synced           bool
+	boundCount       int
+	failedScheduling int
+	lastSample       time.Time
+	lastBound        int
+	lastFailed       int
+	timeSeries       []SchedulerSample
+	saturationWindow int
+	saturatedAt      *time.Time
+	mutex            sync.RWMutex
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// NewSchedulerTracker creates a SchedulerTracker for pods matching the labelSelector in the namespace.
+// An empty namespace tracks pods in all namespaces.
+func NewSchedulerTracker(client kubernetes.Interface, namespace, labelSelector string, opts ...SchedulerTrackerOption) *SchedulerTracker {
+	podFactory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = labelSelector
+		}),
+	)
+	eventFactory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("reason", ReasonFailedScheduling).String()
+		}),
+	)
+	t := &SchedulerTracker{
+		podInformer:      podFactory.Core().V1().Pods().Informer(),
+		eventInformer:    eventFactory.Core().V1().Events().Informer(),
+		bound:            make(map[string]bool),
+		saturationWindow: DefaultSaturationWindow,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Start runs the informers until the context is cancelled and records a SchedulerSample every interval.
+// It returns once the initial pod and event lists were observed.
+func (t *SchedulerTracker) Start(ctx context.Context, interval time.Duration) error {
+	podRegistration, err := t.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observePod(obj) },
+		UpdateFunc: func(_, obj any) { t.observePod(obj) },
+		DeleteFunc: t.observePodDeleted,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register pod event handler: %w", err)
+	}
+	eventRegistration, err := t.eventInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { t.observeEvent(nil, obj) },
+		UpdateFunc: t.observeEvent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register event handler: %w", err)
+	}
+	go t.podInformer.Run(ctx.Done())
+	go t.eventInformer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), podRegistration.HasSynced, eventRegistration.HasSynced) {
+		return fmt.Errorf("failed to sync scheduler informers: %w", ctx.Err())
+	}
+
+	t.mutex.Lock()
+	t.synced = true
+	t.lastSample = t.now()
+	t.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.sample()
+			}
+		}
+	}()
+	return nil
+}
+
+// observePod counts the pod as bound when its spec.nodeName is set for the first time.
+func (t *SchedulerTracker) observePod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := podKey(pod)
+	if t.bound[key] {
+		return
+	}
+	t.bound[key] = pod.Spec.NodeName != ""
+	if t.bound[key] && t.synced {
+		t.boundCount++
+	}
+}
+
+// observePodDeleted stops tracking the pod.
+func (t *SchedulerTracker) observePodDeleted(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.bound, podKey(pod))
+}
+
+// observeEvent counts new occurrences of FailedScheduling events, the scheduler aggregates repeated failures
+// by incrementing the count of an existing event.
+func (t *SchedulerTracker) observeEvent(oldObj, obj any) {
+	event, ok := obj.(*corev1.Event)
+	if !ok || event.Reason != ReasonFailedScheduling || event.InvolvedObject.Kind != "Pod" {
+		return
+	}
+	occurrences := max(event.Count, 1)
+	if old, ok := oldObj.(*corev1.Event); ok {
+		occurrences -= max(old.Count, 1)
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.synced && occurrences > 0 {
+		t.failedScheduling += int(occurrences)
+	}
+}
+
+// sample records the throughput since the previous sample and checks whether the scheduler is saturated.
+func (t *SchedulerTracker) sample() {
+	now := t.now()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	pending := 0
+	for _, bound := range t.bound {
+		if !bound {
+			pending++
+		}
+	}
+	sample := SchedulerSample{
+		Time:             now,
+		Bound:            t.boundCount - t.lastBound,
+		FailedScheduling: t.failedScheduling - t.lastFailed,
+		Pending:          pending,
+	}
+	if elapsed := now.Sub(t.lastSample).Seconds(); elapsed > 0 {
+		sample.BoundPerSecond = float64(sample.Bound) / elapsed
+	}
+	t.lastSample, t.lastBound, t.lastFailed = now, t.boundCount, t.failedScheduling
+	t.timeSeries = append(t.timeSeries, sample)
+
+	if t.saturatedAt == nil && t.pendingGrowing() {
+		t.saturatedAt = &now
+	}
+}
+
+// pendingGrowing returns true if the pending queue grew in each of the last saturationWindow samples.
+func (t *SchedulerTracker) pendingGrowing() bool {
+	if t.saturationWindow <= 0 || len(t.timeSeries) <= t.saturationWindow {
+		return false
+	}
+	recent := t.timeSeries[len(t.timeSeries)-t.saturationWindow-1:]
+	for i := 1; i < len(recent); i++ {
+		if recent[i].Pending <= recent[i-1].Pending {
+			return false
+		}
+	}
+	return true
+}
+
+// TimeSeries returns a copy of the recorded samples.
+func (t *SchedulerTracker) TimeSeries() []SchedulerSample {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	timeSeries := make([]SchedulerSample, len(t.timeSeries))
+	copy(timeSeries, t.timeSeries)
+	return timeSeries
+}
+
+// Report returns the totals, throughput distribution and saturation status over all recorded samples.
+func (t *SchedulerTracker) Report() SchedulerReport {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	report := SchedulerReport{
+		Bound:            t.boundCount,
+		FailedScheduling: t.failedScheduling,
+		Saturated:        t.saturatedAt != nil,
+		SaturatedAt:      t.saturatedAt,
+	}
+	throughput := make([]float64, 0, len(t.timeSeries))
+	for _, sample := range t.timeSeries {
+		throughput = append(throughput, sample.BoundPerSecond)
+		report.PeakPending = max(report.PeakPending, sample.Pending)
+	}
+	report.Throughput = stats.Summarize(throughput)
+	return report
+}
@@ -0,0 +1,95 @@
+package measurement
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestSchedulerTracker_Sample(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	tracker := NewSchedulerTracker(fake.NewSimpleClientset(), "default", "app=fake-pod", WithSaturationWindow(2))
+	tracker.now = func() time.Time { return now }
+
+	// bindings observed before the informers synced do not count towards throughput
+	tracker.observePod(newBoundPod("pod-0", "node-1"))
+	tracker.synced = true
+	tracker.lastSample = now
+
+	tracker.observePod(newBoundPod("pod-1", ""))
+	tracker.observePod(newBoundPod("pod-2", ""))
+	tracker.observePod(newBoundPod("pod-1", "node-1"))
+	// repeated updates of a bound pod are counted once
+	tracker.observePod(newBoundPod("pod-1", "node-1"))
+	tracker.observeEvent(nil, newFailedSchedulingEvent(1))
+	tracker.observeEvent(newFailedSchedulingEvent(1), newFailedSchedulingEvent(3))
+	now = start.Add(2 * time.Second)
+	tracker.sample()
+
+	tracker.observePod(newBoundPod("pod-3", ""))
+	now = start.Add(3 * time.Second)
+	tracker.sample()
+	assert.False(t, tracker.Report().Saturated)
+
+	tracker.observePod(newBoundPod("pod-4", ""))
+	now = start.Add(4 * time.Second)
+	tracker.sample()
+
+	timeSeries := tracker.TimeSeries()
+	assert.Len(t, timeSeries, 3)
+	assert.Equal(t, SchedulerSample{Time: start.Add(2 * time.Second), Bound: 1, BoundPerSecond: 0.5, FailedScheduling: 3, Pending: 1}, timeSeries[0])
+	assert.Equal(t, SchedulerSample{Time: start.Add(3 * time.Second), Pending: 2}, timeSeries[1])
+
+	report := tracker.Report()
+	assert.Equal(t, 1, report.Bound)
+	assert.Equal(t, 3, report.FailedScheduling)
+	assert.Equal(t, 3, report.PeakPending)
+	assert.Equal(t, 0.5, report.Throughput.Max)
+	assert.True(t, report.Saturated)
+	assert.Equal(t, start.Add(4*time.Second), *report.SaturatedAt)
+
+	tracker.observePodDeleted(newBoundPod("pod-4", ""))
+	now = start.Add(5 * time.Second)
+	tracker.sample()
+	assert.Equal(t, 2, tracker.TimeSeries()[3].Pending)
+}
+
+func TestSchedulerTracker_Start(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset(newBoundPod("pod-1", ""))
+	tracker := NewSchedulerTracker(client, "default", "app=fake-pod")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tracker.Start(ctx, 10*time.Millisecond))
+
+	_, err := client.CoreV1().Pods("default").Update(ctx, newBoundPod("pod-1", "node-1"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return tracker.Report().Bound == 1 && len(tracker.TimeSeries()) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func newBoundPod(name, nodeName string) *corev1.Pod {
+	pod := newPod(name, time.Now())
+	pod.Spec.NodeName = nodeName
+	return pod
+}
+
+func newFailedSchedulingEvent(count int32) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "pod-2.failed", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod-2", Namespace: "default"},
+		Reason:         ReasonFailedScheduling,
+		Count:          count,
+	}
+}
@@ -0,0 +1,426 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/rest"
+)
+
+// API server metric families used in the delta report.
+const (
+	metricRequestDuration     = "apiserver_request_duration_seconds"
+	metricStorageObjects      = "apiserver_storage_objects"
+	metricEtcdRequestDuration = "etcd_request_duration_seconds"
+	metricRejectedRequests    = "apiserver_flowcontrol_rejected_requests_total"
+	// the watch cache capacity metric was renamed in Kubernetes 1.28.
+	metricWatchCacheCapacity       = "apiserver_watch_cache_capacity"
+	metricWatchCacheCapacityLegacy = "watch_cache_capacity"
+)
+
+// Snapshot is a single scrape of the API server metrics.
+type Snapshot struct {
+	// Time is the time at which the metrics were scraped.
+	Time     time.Time
+	families map[string]*dto.MetricFamily
+}
+
+// ParseSnapshot parses metrics in the Prometheus text exposition format.
+func ParseSnapshot(data []byte, scrapedAt time.Time) (*Snapshot, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return &Snapshot{Time: scrapedAt, families: families}, nil
+}
+
+// APIServerSample holds the values of selected API server metrics at the time of a scrape.
+type APIServerSample struct {
+	// Time is the time at which the metrics were scraped.
+	Time time.Time `json:"time"`
+	// Requests is the total number of requests served by the API server.
+	Requests float64 `json:"requests"`
+	// Rejected is the total number of requests rejected by API priority and fairness.
+	Rejected float64 `json:"rejected"`
+	// StorageObjects is the number of stored objects of each touched resource.
+	StorageObjects map[string]float64 `json:"storageObjects"`
+}
+
+// LatencyDelta describes the requests observed by a histogram between two scrapes.
+type LatencyDelta struct {
+	// Labels identify the requests (e.g. verb and resource, or etcd operation and type).
+	Labels map[string]string `json:"labels"`
+	// Count is the number of requests.
+	Count float64 `json:"count"`
+	// Mean is the mean request duration in seconds.
+	Mean float64 `json:"mean"`
+	// P50 is the 50th percentile request duration in seconds, estimated from histogram buckets.
+	P50 float64 `json:"p50"`
+	// P99 is the 99th percentile request duration in seconds, estimated from histogram buckets.
+	P99 float64 `json:"p99"`
+}
+
+// GaugeDelta describes the change of a gauge between two scrapes.
+type GaugeDelta struct {
+	// Labels identify the gauge (e.g. resource).
+	Labels map[string]string `json:"labels"`
+	// Before is the value at the first scrape.
+	Before float64 `json:"before"`
+	// After is the value at the last scrape.
+	After float64 `json:"after"`
+	// Delta is the difference between After and Before.
+	Delta float64 `json:"delta"`
+}
+
+// CounterDelta describes the increase of a counter between two scrapes.
+type CounterDelta struct {
+	// Labels identify the counter (e.g. priority level and reason).
+	Labels map[string]string `json:"labels"`
+	// Delta is the increase of the counter.
+	Delta float64 `json:"delta"`
+}
+
+// APIServerReport describes the change of API server metrics between the first and the last scrape,
+// limited to the resources touched by the simulation.
+type APIServerReport struct {
+	// Start is the time of the first scrape.
+	Start time.Time `json:"start"`
+	// End is the time of the last scrape.
+	End time.Time `json:"end"`
+	// RequestDuration holds the request latencies by verb and resource.
+	RequestDuration []LatencyDelta `json:"requestDuration"`
+	// EtcdRequestDuration holds the etcd request latencies by operation and type.
+	EtcdRequestDuration []LatencyDelta `json:"etcdRequestDuration"`
+	// StorageObjects holds the number of stored objects by resource.
+	StorageObjects []GaugeDelta `json:"storageObjects"`
+	// WatchCacheCapacity holds the watch cache capacity by resource.
+	WatchCacheCapacity []GaugeDelta `json:"watchCacheCapacity"`
+	// Rejections holds the requests rejected by API priority and fairness by priority level, flow schema and reason.
+	Rejections []CounterDelta `json:"rejections"`
+	// Samples holds selected metrics of every scrape.
+	Samples []APIServerSample `json:"samples"`
+}
+
+// APIServerScraper scrapes the API server /metrics endpoint through the REST client of a clientset.
+type APIServerScraper struct {
+	client    rest.Interface
+	resources map[string]bool
+	first     *Snapshot
+	last      *Snapshot
+	samples   []APIServerSample
+	mutex     sync.RWMutex
+}
+
+// NewAPIServerScraper creates a scraper which reports metrics of the provided resources (e.g. pods, jobs).
+func NewAPIServerScraper(client rest.Interface, resources ...string) *APIServerScraper {
+	touched := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		touched[resource] = true
+	}
+	return &APIServerScraper{client: client, resources: touched}
+}
+
+// Scrape fetches the API server metrics and records them as the last snapshot,
+// the first successful scrape is used as the baseline of the delta report.
+func (s *APIServerScraper) Scrape(ctx context.Context) error {
+	data, err := s.client.Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to scrape api server metrics: %w", err)
+	}
+	snapshot, err := ParseSnapshot(data, time.Now())
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.first == nil {
+		s.first = snapshot
+	}
+	s.last = snapshot
+	s.samples = append(s.samples, s.sample(snapshot))
+	return nil
+}
+
+// Run scrapes the API server metrics every interval until the context is cancelled.
+// Failed scrapes are logged and skipped.
+func (s *APIServerScraper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Scrape(ctx); err != nil {
+				slog.Warn("failed to scrape api server metrics", "error", err)
+			}
+		}
+	}
+}
+
+func (s *APIServerScraper) sample(snapshot *Snapshot) APIServerSample {
+	sample := APIServerSample{Time: snapshot.Time, StorageObjects: make(map[string]float64)}
+	for _, m := range snapshot.metrics(metricRequestDuration) {
+		sample.Requests += float64(m.GetHistogram().GetSampleCount())
+	}
+	for _, m := range snapshot.metrics(metricRejectedRequests) {
+		sample.Rejected += m.GetCounter().GetValue()
+	}
+	for _, m := range snapshot.metrics(metricStorageObjects) {
+		if resource := label(m, "resource"); s.touched(resource) {
+			sample.StorageObjects[resource] = m.GetGauge().GetValue()
+		}
+	}
+	return sample
+}
+
+// Report returns the delta between the first and the last scrape.
+// It returns an error if the metrics were not scraped at least twice.
+func (s *APIServerScraper) Report() (*APIServerReport, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.samples) < 2 {
+		return nil, fmt.Errorf("api server metrics were scraped %d times, at least 2 scrapes are needed", len(s.samples))
+	}
+	before, after := s.first, s.last
+	samples := make([]APIServerSample, len(s.samples))
+	copy(samples, s.samples)
+	return &APIServerReport{
+		Start:               before.Time,
+		End:                 after.Time,
+		RequestDuration:     histogramDeltas(before, after, metricRequestDuration, requestLabels, s.touchedResource),
+		EtcdRequestDuration: histogramDeltas(before, after, metricEtcdRequestDuration, etcdLabels, s.touchedEtcdType),
+		StorageObjects:      gaugeDeltas(before, after, []string{metricStorageObjects}, s.touchedResource),
+		WatchCacheCapacity:  gaugeDeltas(before, after, []string{metricWatchCacheCapacity, metricWatchCacheCapacityLegacy}, s.touchedResource),
+		Rejections:          counterDeltas(before, after, metricRejectedRequests, rejectionLabels),
+		Samples:             samples,
+	}, nil
+}
+
+// touched returns true if the resource, with or without its group suffix, was touched by the simulation.
+func (s *APIServerScraper) touched(resource string) bool {
+	resource, _, _ = strings.Cut(resource, ".")
+	return s.resources[resource]
+}
+
+func (s *APIServerScraper) touchedResource(m *dto.Metric) bool {
+	return s.touched(label(m, "resource"))
+}
+
+// touchedEtcdType matches etcd request types which are either storage prefixes (/registry/pods)
+// or object types (*core.Pod) of touched resources.
+func (s *APIServerScraper) touchedEtcdType(m *dto.Metric) bool {
+	t := strings.ToLower(label(m, "type"))
+	for resource := range s.resources {
+		if strings.Contains(t, "/"+resource) || strings.HasSuffix(t, "."+strings.TrimSuffix(resource, "s")) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	requestLabels   = []string{"verb", "resource", "subresource"}
+	etcdLabels      = []string{"operation", "type"}
+	rejectionLabels = []string{"priority_level", "flow_schema", "reason"}
+)
+
+func (s *Snapshot) metrics(names ...string) []*dto.Metric {
+	for _, name := range names {
+		if family, ok := s.families[name]; ok {
+			return family.GetMetric()
+		}
+	}
+	return nil
+}
+
+func label(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// groupKey returns the values of the labels which are kept when aggregating metrics.
+func groupKey(m *dto.Metric, labels []string) (string, map[string]string) {
+	values := make(map[string]string, len(labels))
+	parts := make([]string, 0, len(labels))
+	for _, name := range labels {
+		value := label(m, name)
+		if value != "" {
+			values[name] = value
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, "\x00"), values
+}
+
+type histogram struct {
+	labels  map[string]string
+	count   float64
+	sum     float64
+	buckets map[float64]float64
+}
+
+// aggregateHistograms sums the histograms of matching metrics grouped by labels.
+func aggregateHistograms(snapshot *Snapshot, name string, labels []string, match func(*dto.Metric) bool) map[string]*histogram {
+	histograms := make(map[string]*histogram)
+	for _, m := range snapshot.metrics(name) {
+		if !match(m) {
+			continue
+		}
+		key, values := groupKey(m, labels)
+		h, ok := histograms[key]
+		if !ok {
+			h = &histogram{labels: values, buckets: make(map[float64]float64)}
+			histograms[key] = h
+		}
+		h.count += float64(m.GetHistogram().GetSampleCount())
+		h.sum += m.GetHistogram().GetSampleSum()
+		for _, b := range m.GetHistogram().GetBucket() {
+			h.buckets[b.GetUpperBound()] += float64(b.GetCumulativeCount())
+		}
+	}
+	return histograms
+}
+
+func histogramDeltas(before, after *Snapshot, name string, labels []string, match func(*dto.Metric) bool) []LatencyDelta {
+	previous := aggregateHistograms(before, name, labels, match)
+	var deltas []LatencyDelta
+	for key, h := range aggregateHistograms(after, name, labels, match) {
+		count, sum := h.count, h.sum
+		buckets := h.buckets
+		if p, ok := previous[key]; ok {
+			count -= p.count
+			sum -= p.sum
+			buckets = make(map[float64]float64, len(h.buckets))
+			for bound, c := range h.buckets {
+				buckets[bound] = c - p.buckets[bound]
+			}
+		}
+		if count <= 0 {
+			continue
+		}
+		deltas = append(deltas, LatencyDelta{
+			Labels: h.labels,
+			Count:  count,
+			Mean:   sum / count,
+			P50:    histogramQuantile(0.5, buckets),
+			P99:    histogramQuantile(0.99, buckets),
+		})
+	}
+	sortByLabels(deltas, func(d LatencyDelta) map[string]string { return d.Labels }, labels)
+	return deltas
+}
+
+// histogramQuantile estimates the quantile from cumulative bucket counts by linear interpolation within the bucket,
+// the same way as the PromQL histogram_quantile function.
+func histogramQuantile(q float64, buckets map[float64]float64) float64 {
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	if len(bounds) == 0 {
+		return 0
+	}
+	total := buckets[bounds[len(bounds)-1]]
+	if total <= 0 {
+		return 0
+	}
+	rank := q * total
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, bound := range bounds {
+		count := buckets[bound]
+		if count >= rank {
+			if math.IsInf(bound, 1) {
+				// the quantile falls into the +Inf bucket, the best estimate is the largest finite bound
+				return lowerBound
+			}
+			if count == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lowerBound, lowerCount = bound, count
+	}
+	return lowerBound
+}
+
+func gaugeDeltas(before, after *Snapshot, names []string, match func(*dto.Metric) bool) []GaugeDelta {
+	values := func(snapshot *Snapshot) map[string]float64 {
+		result := make(map[string]float64)
+		for _, m := range snapshot.metrics(names...) {
+			if match(m) {
+				result[label(m, "resource")] += m.GetGauge().GetValue()
+			}
+		}
+		return result
+	}
+	previous := values(before)
+	current := values(after)
+	for resource := range previous {
+		if _, ok := current[resource]; !ok {
+			current[resource] = 0
+		}
+	}
+	deltas := make([]GaugeDelta, 0, len(current))
+	for resource, value := range current {
+		deltas = append(deltas, GaugeDelta{
+			Labels: map[string]string{"resource": resource},
+			Before: previous[resource],
+			After:  value,
+			Delta:  value - previous[resource],
+		})
+	}
+	sortByLabels(deltas, func(d GaugeDelta) map[string]string { return d.Labels }, []string{"resource"})
+	return deltas
+}
+
+func counterDeltas(before, after *Snapshot, name string, labels []string) []CounterDelta {
+	values := func(snapshot *Snapshot) (map[string]float64, map[string]map[string]string) {
+		result := make(map[string]float64)
+		keyLabels := make(map[string]map[string]string)
+		for _, m := range snapshot.metrics(name) {
+			key, values := groupKey(m, labels)
+			result[key] += m.GetCounter().GetValue()
+			keyLabels[key] = values
+		}
+		return result, keyLabels
+	}
+	previous, _ := values(before)
+	current, keyLabels := values(after)
+	var deltas []CounterDelta
+	for key, value := range current {
+		if delta := value - previous[key]; delta > 0 {
+			deltas = append(deltas, CounterDelta{Labels: keyLabels[key], Delta: delta})
+		}
+	}
+	sortByLabels(deltas, func(d CounterDelta) map[string]string { return d.Labels }, labels)
+	return deltas
+}
+
+// sortByLabels sorts the items by the values of the labels in order.
+func sortByLabels[T any](items []T, labelsOf func(T) map[string]string, labels []string) {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := labelsOf(items[i]), labelsOf(items[j])
+		for _, name := range labels {
+			if a[name] != b[name] {
+				return a[name] < b[name]
+			}
+		}
+		return false
+	})
+}
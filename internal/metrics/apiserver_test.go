@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+const metricsBefore = `# TYPE apiserver_request_duration_seconds histogram
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="0.1"} 10
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="1"} 10
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="+Inf"} 10
+apiserver_request_duration_seconds_sum{verb="POST",resource="pods",subresource="",scope="resource"} 0.5
+apiserver_request_duration_seconds_count{verb="POST",resource="pods",subresource="",scope="resource"} 10
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="0.1"} 1
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="1"} 1
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="+Inf"} 1
+apiserver_request_duration_seconds_sum{verb="GET",resource="secrets",subresource="",scope="resource"} 0.01
+apiserver_request_duration_seconds_count{verb="GET",resource="secrets",subresource="",scope="resource"} 1
+# TYPE etcd_request_duration_seconds histogram
+etcd_request_duration_seconds_bucket{operation="create",type="/registry/pods",le="0.1"} 5
+etcd_request_duration_seconds_bucket{operation="create",type="/registry/pods",le="+Inf"} 5
+etcd_request_duration_seconds_sum{operation="create",type="/registry/pods"} 0.1
+etcd_request_duration_seconds_count{operation="create",type="/registry/pods"} 5
+# TYPE apiserver_storage_objects gauge
+apiserver_storage_objects{resource="pods"} 100
+apiserver_storage_objects{resource="jobs.batch"} 5
+apiserver_storage_objects{resource="secrets"} 7
+# TYPE apiserver_watch_cache_capacity gauge
+apiserver_watch_cache_capacity{resource="pods"} 100
+# TYPE apiserver_flowcontrol_rejected_requests_total counter
+apiserver_flowcontrol_rejected_requests_total{flow_schema="global-default",priority_level="global-default",reason="queue-full"} 2
+`
+
+const metricsAfter = `# TYPE apiserver_request_duration_seconds histogram
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="0.1"} 60
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="1"} 110
+apiserver_request_duration_seconds_bucket{verb="POST",resource="pods",subresource="",scope="resource",le="+Inf"} 110
+apiserver_request_duration_seconds_sum{verb="POST",resource="pods",subresource="",scope="resource"} 30.5
+apiserver_request_duration_seconds_count{verb="POST",resource="pods",subresource="",scope="resource"} 110
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="0.1"} 2
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="1"} 2
+apiserver_request_duration_seconds_bucket{verb="GET",resource="secrets",subresource="",scope="resource",le="+Inf"} 2
+apiserver_request_duration_seconds_sum{verb="GET",resource="secrets",subresource="",scope="resource"} 0.02
+apiserver_request_duration_seconds_count{verb="GET",resource="secrets",subresource="",scope="resource"} 2
+# TYPE etcd_request_duration_seconds histogram
+etcd_request_duration_seconds_bucket{operation="create",type="/registry/pods",le="0.1"} 105
+etcd_request_duration_seconds_bucket{operation="create",type="/registry/pods",le="+Inf"} 105
+etcd_request_duration_seconds_sum{operation="create",type="/registry/pods"} 2.1
+etcd_request_duration_seconds_count{operation="create",type="/registry/pods"} 105
+# TYPE apiserver_storage_objects gauge
+apiserver_storage_objects{resource="pods"} 200
+apiserver_storage_objects{resource="jobs.batch"} 5
+apiserver_storage_objects{resource="secrets"} 7
+# TYPE apiserver_watch_cache_capacity gauge
+apiserver_watch_cache_capacity{resource="pods"} 400
+# TYPE apiserver_flowcontrol_rejected_requests_total counter
+apiserver_flowcontrol_rejected_requests_total{flow_schema="global-default",priority_level="global-default",reason="queue-full"} 5
+`
+
+func TestAPIServerScraper(t *testing.T) {
+	t.Parallel()
+
+	var scrapes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if scrapes.Add(1) == 1 {
+			_, _ = w.Write([]byte(metricsBefore))
+			return
+		}
+		_, _ = w.Write([]byte(metricsAfter))
+	}))
+	defer server.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	scraper := NewAPIServerScraper(client.Discovery().RESTClient(), "pods", "jobs")
+
+	ctx := context.Background()
+	assert.NoError(t, scraper.Scrape(ctx))
+	_, err = scraper.Report()
+	assert.Error(t, err)
+	assert.NoError(t, scraper.Scrape(ctx))
+
+	report, err := scraper.Report()
+	assert.NoError(t, err)
+
+	assert.Len(t, report.RequestDuration, 1)
+	pods := report.RequestDuration[0]
+	assert.Equal(t, map[string]string{"verb": "POST", "resource": "pods"}, pods.Labels)
+	assert.Equal(t, 100.0, pods.Count)
+	assert.InDelta(t, 0.3, pods.Mean, 1e-9)
+	assert.InDelta(t, 0.1, pods.P50, 1e-9)
+	assert.InDelta(t, 0.982, pods.P99, 1e-9)
+
+	assert.Len(t, report.EtcdRequestDuration, 1)
+	assert.Equal(t, 100.0, report.EtcdRequestDuration[0].Count)
+
+	assert.Equal(t, []GaugeDelta{
+		{Labels: map[string]string{"resource": "jobs.batch"}, Before: 5, After: 5},
+		{Labels: map[string]string{"resource": "pods"}, Before: 100, After: 200, Delta: 100},
+	}, report.StorageObjects)
+	assert.Equal(t, []GaugeDelta{
+		{Labels: map[string]string{"resource": "pods"}, Before: 100, After: 400, Delta: 300},
+	}, report.WatchCacheCapacity)
+	assert.Equal(t, []CounterDelta{
+		{Labels: map[string]string{"flow_schema": "global-default", "priority_level": "global-default", "reason": "queue-full"}, Delta: 3},
+	}, report.Rejections)
+
+	assert.Len(t, report.Samples, 2)
+	assert.Equal(t, 112.0, report.Samples[1].Requests)
+	assert.Equal(t, 5.0, report.Samples[1].Rejected)
+	assert.Equal(t, map[string]float64{"pods": 200, "jobs.batch": 5}, report.Samples[1].StorageObjects)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	t.Parallel()
+
+	buckets := map[float64]float64{0.1: 50, 1: 100}
+	assert.Equal(t, 0.0, histogramQuantile(0.5, map[float64]float64{}))
+	assert.InDelta(t, 0.05, histogramQuantile(0.25, buckets), 1e-9)
+	assert.InDelta(t, 0.1, histogramQuantile(0.5, buckets), 1e-9)
+	assert.InDelta(t, 0.55, histogramQuantile(0.75, buckets), 1e-9)
+}
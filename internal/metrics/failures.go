@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+)
+
+// FailureRecorder counts failed work items by executor and Kubernetes API status reason.
+type FailureRecorder struct {
+	failures map[string]map[string]int
+	mutex    sync.Mutex
+}
+
+// NewFailureRecorder creates a new FailureRecorder.
+func NewFailureRecorder() *FailureRecorder {
+	return &FailureRecorder{failures: make(map[string]map[string]int)}
+}
+
+// ObserveStarted implements ratelimiter.Observer.
+func (r *FailureRecorder) ObserveStarted(string) {}
+
+// ObserveFinished records the failure reason of failed work items.
+func (r *FailureRecorder) ObserveFinished(identifier string, _ time.Duration, err error) {
+	if err == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.failures[identifier] == nil {
+		r.failures[identifier] = make(map[string]int)
+	}
+	r.failures[identifier][FailureReason(err)]++
+}
+
+// Failures returns the number of failures by reason keyed by executor identifier.
+func (r *FailureRecorder) Failures() map[string]map[string]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	failures := make(map[string]map[string]int, len(r.failures))
+	for identifier, reasons := range r.failures {
+		failures[identifier] = make(map[string]int, len(reasons))
+		for reason, count := range reasons {
+			failures[identifier][reason] = count
+		}
+	}
+	return failures
+}
+
+var _ ratelimiter.Observer = &FailureRecorder{}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func TestFailureRecorder(t *testing.T) {
+	t.Parallel()
+
+	recorder := NewFailureRecorder()
+	recorder.ObserveStarted("pods")
+	recorder.ObserveFinished("pods", time.Millisecond, nil)
+	recorder.ObserveFinished("pods", time.Millisecond, k8serrors.NewAlreadyExists(schema.GroupResource{Resource: "pods"}, "pod-1"))
+	recorder.ObserveFinished("pods", time.Millisecond, k8serrors.NewAlreadyExists(schema.GroupResource{Resource: "pods"}, "pod-2"))
+	recorder.ObserveFinished("jobs", time.Millisecond, errors.New("connection refused"))
+
+	failures := recorder.Failures()
+	assert.Equal(t, map[string]map[string]int{
+		"pods": {"AlreadyExists": 2},
+		"jobs": {"Unknown": 1},
+	}, failures)
+
+	failures["pods"]["AlreadyExists"] = 0
+	assert.Equal(t, 2, recorder.Failures()["pods"]["AlreadyExists"])
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+)
+
+const (
+	namespace = "batchsim"
+	subsystem = "executor"
+	// reasonUnknown is used for failures which are not Kubernetes API status errors.
+	reasonUnknown = "Unknown"
+	// shutdownTimeout is the maximum time to wait for in-flight scrapes when the server is stopped.
+	shutdownTimeout = 5 * time.Second
+)
+
+// RateSource returns the configured and achieved rates keyed by executor identifier.
+type RateSource func() map[string]ratelimiter.Rate
+
+// Exporter exposes executor metrics in the Prometheus exposition format.
+type Exporter struct {
+	registry   *prometheus.Registry
+	executions *prometheus.CounterVec
+	failures   *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+}
+
+// NewExporter creates a new Exporter.
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "executions_total",
+			Help:      "Total number of executed work items by executor and result.",
+		}, []string{"executor", "result"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "failures_total",
+			Help:      "Total number of failed work items by executor and Kubernetes API status reason.",
+		}, []string{"executor", "reason"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "duration_seconds",
+			Help:      "Duration of work item executions by executor.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"executor"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "in_flight_requests",
+			Help:      "Number of work items currently being executed by executor.",
+		}, []string{"executor"}),
+	}
+	e.registry.MustRegister(e.executions, e.failures, e.duration, e.inFlight)
+	return e
+}
+
+// RegisterRates exports the configured and achieved rates read from the source on every scrape.
+func (e *Exporter) RegisterRates(source RateSource) {
+	e.registry.MustRegister(newRateCollector(source))
+}
+
+// ObserveStarted marks a work item as in flight.
+func (e *Exporter) ObserveStarted(identifier string) {
+	e.inFlight.WithLabelValues(identifier).Inc()
+}
+
+// ObserveFinished records the result, duration and failure reason of a work item.
+func (e *Exporter) ObserveFinished(identifier string, duration time.Duration, err error) {
+	e.inFlight.WithLabelValues(identifier).Dec()
+	e.duration.WithLabelValues(identifier).Observe(duration.Seconds())
+	if err != nil {
+		e.executions.WithLabelValues(identifier, "failed").Inc()
+		e.failures.WithLabelValues(identifier, FailureReason(err)).Inc()
+		return
+	}
+	e.executions.WithLabelValues(identifier, "succeeded").Inc()
+}
+
+// Handler returns the HTTP handler which serves the metrics.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{Registry: e.registry})
+}
+
+// Serve serves the metrics on /metrics at the provided address until the context is cancelled.
+func (e *Exporter) Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving prometheus metrics", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// FailureReason returns the Kubernetes API status reason of the error, or Unknown if it is not a status error.
+func FailureReason(err error) string {
+	reason := k8serrors.ReasonForError(err)
+	if reason == "" {
+		return reasonUnknown
+	}
+	return string(reason)
+}
+
+var _ ratelimiter.Observer = &Exporter{}
+
+// rateCollector collects the configured and achieved rates on every scrape.
+type rateCollector struct {
+	source     RateSource
+	configured *prometheus.Desc
+	achieved   *prometheus.Desc
+}
+
+func newRateCollector(source RateSource) *rateCollector {
+	return &rateCollector{
+		source: source,
+		configured: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "configured_rate"),
+			"Number of work items per second the executor is configured to process.",
+			[]string{"executor"}, nil,
+		),
+		achieved: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "achieved_rate"),
+			"Number of work items per second the executor processed since it was started.",
+			[]string{"executor"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *rateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.configured
+	ch <- c.achieved
+}
+
+// Collect implements prometheus.Collector.
+func (c *rateCollector) Collect(ch chan<- prometheus.Metric) {
+	for identifier, rate := range c.source() {
+		ch <- prometheus.MustNewConstMetric(c.configured, prometheus.GaugeValue, rate.Configured, identifier)
+		ch <- prometheus.MustNewConstMetric(c.achieved, prometheus.GaugeValue, rate.Achieved, identifier)
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/stretchr/testify/assert"
+	"io"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExporter(t *testing.T) {
+	t.Parallel()
+
+	exporter := NewExporter()
+	exporter.RegisterRates(func() map[string]ratelimiter.Rate {
+		return map[string]ratelimiter.Rate{"kubernetes-pod-creator": {Configured: 10, Achieved: 7.5}}
+	})
+
+	exporter.ObserveStarted("kubernetes-pod-creator")
+	exporter.ObserveFinished("kubernetes-pod-creator", 20*time.Millisecond, nil)
+	exporter.ObserveStarted("kubernetes-pod-creator")
+	exporter.ObserveFinished("kubernetes-pod-creator", 30*time.Millisecond, k8serrors.NewTooManyRequests("slow down", 1))
+	exporter.ObserveStarted("kubernetes-pod-creator")
+	exporter.ObserveFinished("kubernetes-pod-creator", 40*time.Millisecond, errors.New("connection reset"))
+	exporter.ObserveStarted("kubernetes-pod-creator")
+
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+
+	response, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+
+	text := string(body)
+	assert.Contains(t, text, `batchsim_executor_executions_total{executor="kubernetes-pod-creator",result="succeeded"} 1`)
+	assert.Contains(t, text, `batchsim_executor_executions_total{executor="kubernetes-pod-creator",result="failed"} 2`)
+	assert.Contains(t, text, `batchsim_executor_failures_total{executor="kubernetes-pod-creator",reason="TooManyRequests"} 1`)
+	assert.Contains(t, text, `batchsim_executor_failures_total{executor="kubernetes-pod-creator",reason="Unknown"} 1`)
+	assert.Contains(t, text, `batchsim_executor_duration_seconds_count{executor="kubernetes-pod-creator"} 3`)
+	assert.Contains(t, text, `batchsim_executor_in_flight_requests{executor="kubernetes-pod-creator"} 1`)
+	assert.Contains(t, text, `batchsim_executor_configured_rate{executor="kubernetes-pod-creator"} 10`)
+	assert.Contains(t, text, `batchsim_executor_achieved_rate{executor="kubernetes-pod-creator"} 7.5`)
+}
+
+func TestFailureReason(t *testing.T) {
+	t.Parallel()
+
+	conflict := k8serrors.NewConflict(schema.GroupResource{Resource: "pods"}, "fake-pod", errors.New("conflict"))
+	createError := ratelimiter.NewCreateError(conflict, "v1", "Pod", nil)
+
+	assert.Equal(t, "Conflict", FailureReason(createError))
+	assert.Equal(t, "Unknown", FailureReason(errors.New("boom")))
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Format is the output format of the CLI.
+type Format string
+
+const (
+	// FormatHuman renders output using spinners, sections and tables.
+	FormatHuman Format = "human"
+	// FormatJSON prints a single JSON document containing all events once the command exits.
+	FormatJSON Format = "json"
+	// FormatNDJSON prints every event as a JSON object on its own line as soon as it happens.
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat validates and returns the provided output format.
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case "", FormatHuman:
+		return FormatHuman, nil
+	case FormatJSON, FormatNDJSON:
+		return Format(format), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q, supported formats are human, json and ndjson", format)
+	}
+}
+
+// EventType is the type of structured event.
+type EventType string
+
+const (
+	// EventStepStarted is emitted when a step starts.
+	EventStepStarted EventType = "step.started"
+	// EventStepSucceeded is emitted when a step finishes successfully.
+	EventStepSucceeded EventType = "step.succeeded"
+	// EventStepWarning is emitted when a step finishes with a non-fatal problem.
+	EventStepWarning EventType = "step.warning"
+	// EventStepFailed is emitted when a step fails.
+	EventStepFailed EventType = "step.failed"
+	// EventMetrics is emitted periodically with a snapshot of the metrics.
+	EventMetrics EventType = "metrics"
+	// EventSummary is the final event emitted before the command exits.
+	EventSummary EventType = "summary"
+)
+
+// Event is a single structured event.
+type Event struct {
+	// Time is the time at which the event happened.
+	Time time.Time `json:"time"`
+	// Command is the name of the command which emitted the event.
+	Command string `json:"command"`
+	// Type is the type of the event.
+	Type EventType `json:"type"`
+	// Step is the name of the step for step events.
+	Step string `json:"step,omitempty"`
+	// Message is a human-readable description of the event.
+	Message string `json:"message,omitempty"`
+	// Error is the error which caused the step to fail.
+	Error string `json:"error,omitempty"`
+	// ExitCode is the exit code of the command, set only on the summary event.
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Data holds event specific payload like metrics or check results.
+	Data any `json:"data,omitempty"`
+}
+
+// document is the single JSON document printed in FormatJSON.
+type document struct {
+	Command  string  `json:"command"`
+	ExitCode int     `json:"exitCode"`
+	Events   []Event `json:"events"`
+}
+
+// Emitter writes structured events in the configured format.
+// In FormatHuman, the Emitter discards all events.
+type Emitter struct {
+	format  Format
+	command string
+	writer  io.Writer
+	events  []Event
+	summary any
+	closed  bool
+	mutex   sync.Mutex
+}
+
+// New creates a new Emitter which writes events of the provided command to w.
+func New(w io.Writer, format Format, command string) *Emitter {
+	return &Emitter{format: format, command: command, writer: w}
+}
+
+// Enabled returns true if the Emitter writes structured events.
+func (e *Emitter) Enabled() bool {
+	return e != nil && e.format != FormatHuman
+}
+
+// Emit writes the event, or buffers it until Close in FormatJSON.
+func (e *Emitter) Emit(event Event) {
+	if !e.Enabled() {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Command = e.command
+	if e.format == FormatNDJSON {
+		_ = json.NewEncoder(e.writer).Encode(event)
+		return
+	}
+	e.events = append(e.events, event)
+}
+
+// Step emits a step event.
+func (e *Emitter) Step(eventType EventType, step, message string, err error, data any) {
+	event := Event{Type: eventType, Step: step, Message: message, Data: data}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	e.Emit(event)
+}
+
+// Metrics emits a metrics snapshot.
+func (e *Emitter) Metrics(data any) {
+	e.Emit(Event{Type: EventMetrics, Data: data})
+}
+
+// SetSummary sets the payload of the summary event emitted by Close.
+func (e *Emitter) SetSummary(data any) {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.summary = data
+}
+
+// Close emits the summary event with the provided exit code and, in FormatJSON, writes the buffered document.
+// Events emitted after Close are discarded.
+func (e *Emitter) Close(exitCode int) error {
+	if !e.Enabled() {
+		return nil
+	}
+	e.Emit(Event{Type: EventSummary, ExitCode: &exitCode, Data: e.summaryData()})
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.closed = true
+	if e.format != FormatJSON {
+		return nil
+	}
+	encoder := json.NewEncoder(e.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document{Command: e.command, ExitCode: exitCode, Events: e.events})
+}
+
+func (e *Emitter) summaryData() any {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.summary
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := ParseFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatHuman, format)
+
+	format, err = ParseFormat("ndjson")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, format)
+
+	_, err = ParseFormat("yaml")
+	assert.Error(t, err)
+}
+
+func TestEmitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("human format discards events", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		emitter := New(buf, FormatHuman, "check")
+		emitter.Step(EventStepStarted, "kubectl", "is kubectl installed?", nil, nil)
+		assert.NoError(t, emitter.Close(0))
+		assert.False(t, emitter.Enabled())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("ndjson format writes one event per line", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		emitter := New(buf, FormatNDJSON, "check")
+		emitter.Step(EventStepStarted, "kubectl", "is kubectl installed?", nil, nil)
+		emitter.Step(EventStepFailed, "kubectl", "kubectl is not installed", errors.New("not found"), nil)
+		emitter.SetSummary(map[string]int{"failed": 1})
+		assert.NoError(t, emitter.Close(2))
+		emitter.Metrics(map[string]int{"ignored": 1})
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 3)
+		var events []Event
+		for _, line := range lines {
+			var event Event
+			assert.NoError(t, json.Unmarshal([]byte(line), &event))
+			events = append(events, event)
+		}
+		assert.Equal(t, EventStepStarted, events[0].Type)
+		assert.Equal(t, "check", events[0].Command)
+		assert.Equal(t, "not found", events[1].Error)
+		assert.Equal(t, EventSummary, events[2].Type)
+		assert.Equal(t, 2, *events[2].ExitCode)
+		assert.Equal(t, map[string]any{"failed": float64(1)}, events[2].Data)
+	})
+
+	t.Run("json format writes a single document on close", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		emitter := New(buf, FormatJSON, "run")
+		emitter.Metrics(map[string]int{"executed": 10})
+		assert.Empty(t, buf.String())
+		assert.NoError(t, emitter.Close(0))
+
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "run", doc.Command)
+		assert.Equal(t, 0, doc.ExitCode)
+		assert.Len(t, doc.Events, 2)
+		assert.Equal(t, EventMetrics, doc.Events[0].Type)
+		assert.Equal(t, EventSummary, doc.Events[1].Type)
+	})
+}
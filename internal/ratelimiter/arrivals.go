@@ -0,0 +1,42 @@
+package ratelimiter
+
+import (
+	"math"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// poissonChunk is the largest mean drawn with a single Knuth sample, larger means are split into chunks
+// as the sum of Poisson samples is Poisson distributed and exp(-mean) underflows for large means.
+const poissonChunk = 30
+
+// Arrivals returns the number of work items which arrive in an interval given the configured requests per interval.
+type Arrivals func(requests int) int
+
+// ConstantArrivals processes exactly the configured number of requests in every interval.
+func ConstantArrivals(requests int) int {
+	return requests
+}
+
+// PoissonArrivals draws the number of work items of every interval from rnd with a Poisson distribution
+// whose mean is the configured number of requests, so work items arrive independently of each other
+// like jobs submitted by many users.
+func PoissonArrivals(rnd *util.Rand) Arrivals {
+	return func(requests int) int {
+		arrivals := 0
+		for mean := float64(requests); mean > 0; mean -= poissonChunk {
+			arrivals += poisson(rnd, math.Min(mean, poissonChunk))
+		}
+		return arrivals
+	}
+}
+
+// poisson draws a Poisson distributed number with the mean using Knuth's algorithm.
+func poisson(rnd *util.Rand, mean float64) int {
+	limit := math.Exp(-mean)
+	k := 0
+	for p := rnd.Float64(); p > limit; p *= rnd.Float64() {
+		k++
+	}
+	return k
+}
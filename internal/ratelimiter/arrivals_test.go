@@ -0,0 +1,57 @@
+package ratelimiter
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPoissonArrivals(t *testing.T) {
+	t.Parallel()
+
+	for _, requests := range []int{0, 1, 5, 100} {
+		arrivals := PoissonArrivals(util.NewRand(1))
+		samples := 2000
+		total := 0
+		distinct := make(map[int]bool)
+		for i := 0; i < samples; i++ {
+			n := arrivals(requests)
+			assert.GreaterOrEqual(t, n, 0)
+			total += n
+			distinct[n] = true
+		}
+		mean := float64(total) / float64(samples)
+		assert.InDelta(t, float64(requests), mean, 0.05*float64(requests)+0.01, "requests=%d", requests)
+		if requests > 0 {
+			assert.Greater(t, len(distinct), 1, "the number of arrivals must vary, requests=%d", requests)
+		}
+	}
+
+	same := PoissonArrivals(util.NewRand(42))
+	other := PoissonArrivals(util.NewRand(42))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, same(10), other(10), "sources with the same seed must draw the same arrivals")
+	}
+}
+
+func TestRateLimiter_Arrivals(t *testing.T) {
+	t.Parallel()
+
+	ex := newCacheExecutor()
+	intervals := 0
+	arrivals := func(requests int) int {
+		intervals++
+		return intervals % 2 * requests
+	}
+	rl := New[int](5*time.Millisecond, 3, 6, ex, WithArrivals[int](arrivals))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	assert.Eventually(t, func() bool { return !rl.IsRunning() && rl.Metrics().Executed == 6 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, rl.TimeSeries(), 2, "intervals without arrivals must not be recorded")
+	assert.Equal(t, 6, rl.Limit())
+}
@@ -31,3 +31,8 @@ func NewCreateError(err error, apiGroup, kind string, resource metav1.Object) *C
 func (e *CreateError) Error() string {
 	return fmt.Sprintf("failed to create %s %s in %s/%s: %v", e.APIGroup, e.Kind, e.Resource.GetNamespace(), e.Resource.GetName(), e.Err)
 }
+
+// Unwrap returns the underlying error.
+func (e *CreateError) Unwrap() error {
+	return e.Err
+}
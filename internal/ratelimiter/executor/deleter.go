@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/queue"
+)
+
+// DeleteFunc deletes the object with the provided name.
+type DeleteFunc func(ctx context.Context, name string) error
+
+// Deleter is used to delete a list of objects, it deletes one object per work item.
+type Deleter struct {
+	// resource is the resource of the deleted objects, e.g. pods.
+	resource string
+	// names holds the names of the objects which are not deleted yet.
+	names queue.Queue[string]
+	// namesMutex guards names, so objects can be deleted concurrently.
+	namesMutex sync.Mutex
+	// deleteFunc deletes a single object.
+	deleteFunc DeleteFunc
+}
+
+// NewDeleter creates a Deleter which deletes the objects with the provided names using deleteFunc.
+// It is safe for concurrent use, so the work items of a rate limiter interval can delete objects concurrently.
+func NewDeleter(resource string, names []string, deleteFunc DeleteFunc) *Deleter {
+	return &Deleter{
+		resource:   resource,
+		names:      queue.NewLinkedListQueue(names),
+		deleteFunc: deleteFunc,
+	}
+}
+
+// Identifier returns the executor identifier.
+func (d *Deleter) Identifier() string {
+	return "kubernetes-" + d.resource + "-deleter"
+}
+
+// Execute deletes the next object, objects which are already deleted are not reported as errors.
+func (d *Deleter) Execute(ctx context.Context) error {
+	d.namesMutex.Lock()
+	if d.names.Len() == 0 {
+		d.namesMutex.Unlock()
+		return nil
+	}
+	name, _ := d.names.Pop()
+	d.namesMutex.Unlock()
+	if err := d.deleteFunc(ctx, name); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s %s: %w", d.resource, name, err)
+	}
+	return nil
+}
+
+var _ ratelimiter.Executor[string] = &Deleter{}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sync"
+	"testing"
+)
+
+func TestDeleter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("objects are deleted one per work item", func(t *testing.T) {
+		t.Parallel()
+
+		var deleted []string
+		deleter := NewDeleter("pods", []string{"pod-1", "pod-2"}, func(ctx context.Context, name string) error {
+			deleted = append(deleted, name)
+			return nil
+		})
+		assert.Equal(t, "kubernetes-pods-deleter", deleter.Identifier())
+
+		assert.NoError(t, deleter.Execute(context.Background()))
+		assert.Equal(t, []string{"pod-1"}, deleted)
+		assert.NoError(t, deleter.Execute(context.Background()))
+		assert.NoError(t, deleter.Execute(context.Background()), "executing an empty deleter must be a no-op")
+		assert.Equal(t, []string{"pod-1", "pod-2"}, deleted)
+	})
+
+	t.Run("objects are deleted concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		var mutex sync.Mutex
+		var deleted []string
+		deleter := NewDeleter("pods", []string{"pod-1", "pod-2", "pod-3"}, func(ctx context.Context, name string) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			deleted = append(deleted, name)
+			return nil
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, deleter.Execute(context.Background()))
+			}()
+		}
+		wg.Wait()
+		assert.ElementsMatch(t, []string{"pod-1", "pod-2", "pod-3"}, deleted, "every object must be deleted exactly once")
+	})
+
+	t.Run("already deleted objects are not errors", func(t *testing.T) {
+		t.Parallel()
+
+		deleter := NewDeleter("pods", []string{"pod-1"}, func(ctx context.Context, name string) error {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+		})
+		assert.NoError(t, deleter.Execute(context.Background()))
+	})
+
+	t.Run("delete error is returned", func(t *testing.T) {
+		t.Parallel()
+
+		deleter := NewDeleter("pods", []string{"pod-1"}, func(ctx context.Context, name string) error {
+			return assert.AnError
+		})
+		err := deleter.Execute(context.Background())
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), "pods pod-1")
+	})
+}
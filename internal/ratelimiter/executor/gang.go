@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// GangCreator is used to create gangs, a PodGroup through the dynamic client and its member pods,
+// which a gang scheduler only places once all of them fit.
+type GangCreator struct {
+	kubernetesExecutor
+	// dynamicClient creates the PodGroups.
+	dynamicClient dynamic.Interface
+	// style is the gang scheduler whose PodGroups are created.
+	style resources.GangStyle
+	// schedulerName is the scheduler of the member pods.
+	schedulerName string
+	// sizes is the distribution of the number of member pods of a gang.
+	sizes []resources.GangSize
+	// randomEnvVars is used to determine if random environment variables should be added to the member pods.
+	randomEnvVars bool
+}
+
+// NewGangCreator creates a GangCreator which creates gangs of the style whose sizes are drawn from sizes.
+// The member pods are scheduled by schedulerName, or the default scheduler of the style if it is empty.
+// If namer is nil names are generated randomly from rnd.
+func NewGangCreator(
+	client kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	namespace string,
+	style resources.GangStyle,
+	schedulerName string,
+	sizes []resources.GangSize,
+	randomEnvVars bool,
+	rnd *util.Rand,
+	namer Namer,
+	opts ...Option,
+) *GangCreator {
+	if namer == nil {
+		namer = NewRandomNamer(GangNamePrefix, rnd)
+	}
+	if schedulerName == "" {
+		schedulerName = style.SchedulerName()
+	}
+	return &GangCreator{
+		kubernetesExecutor: newKubernetesExecutor(client, namespace, rnd, namer, opts),
+		dynamicClient:      dynamicClient,
+		style:              style,
+		schedulerName:      schedulerName,
+		sizes:              sizes,
+		randomEnvVars:      randomEnvVars,
+	}
+}
+
+// Identifier returns the executor identifier.
+func (c *GangCreator) Identifier() string {
+	return c.identifierOr("kubernetes-gang-creator")
+}
+
+// Execute creates the PodGroup of a gang and its member pods, the gang fails if any of them could not be created.
+// The PodGroup of a failed gang is deleted, which deletes the member pods created so far through their owner reference,
+// so no partial gang is left behind.
+func (c *GangCreator) Execute(ctx context.Context) error {
+	name := c.namer.Name()
+	namespace := c.namespaces.Namespace()
+	size := resources.DrawGangSize(c.sizes, c.rand)
+	item := resources.NewFakePodGroup(c.style, name, namespace, size)
+	var podGroup *unstructured.Unstructured
+	err := c.ensureNamespace(ctx, namespace)
+	if err == nil {
+		podGroup, err = c.dynamicClient.Resource(c.style.PodGroupResource()).Namespace(namespace).Create(ctx, item, metav1.CreateOptions{})
+	}
+	if err != nil {
+		c.observe(namespace, err)
+		return ratelimiter.NewCreateError(err, item.GetAPIVersion(), item.GetKind(), item)
+	}
+	for i := 0; i < size; i++ {
+		pod := resources.NewFakeGangPod(c.style, podGroup, i, size, c.schedulerName, c.randomEnvVars, c.rand)
+		if _, err := c.client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			c.observe(namespace, err)
+			return errors.Join(ratelimiter.NewCreateError(err, "v1", "Pod", pod), c.deletePodGroup(ctx, podGroup))
+		}
+	}
+	c.observe(namespace, nil)
+	return nil
+}
+
+// deletePodGroup deletes the PodGroup of a failed gang and its member pods in the background.
+func (c *GangCreator) deletePodGroup(ctx context.Context, podGroup *unstructured.Unstructured) error {
+	deletePropagationBackground := metav1.DeletePropagationBackground
+	err := c.dynamicClient.Resource(c.style.PodGroupResource()).Namespace(podGroup.GetNamespace()).Delete(
+		ctx, podGroup.GetName(), metav1.DeleteOptions{PropagationPolicy: &deletePropagationBackground},
+	)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PodGroup %s/%s of failed gang: %w", podGroup.GetNamespace(), podGroup.GetName(), err)
+	}
+	return nil
+}
+
+var _ ratelimiter.Executor[*unstructured.Unstructured] = &GangCreator{}
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+func TestGangCreator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gang creation succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewSimpleClientset()
+		dynamicClient := newFakeDynamicClient()
+		executor := NewGangCreator(
+			client, dynamicClient, "default", resources.GangStyleCoscheduling, "", []resources.GangSize{{Size: 3}}, false, util.NewRand(1), nil,
+		)
+
+		ctx := context.Background()
+		assert.NoError(t, executor.Execute(ctx))
+		list, err := dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakePodGroup})
+		assert.NoError(t, err)
+		if !assert.Len(t, list.Items, 1) {
+			t.FailNow()
+		}
+		gang := list.Items[0].GetName()
+		assert.Contains(t, gang, "fake-gang-")
+		minMember, _, _ := unstructured.NestedInt64(list.Items[0].Object, "spec", "minMember")
+		assert.Equal(t, int64(3), minMember)
+
+		pods, err := client.CoreV1().Pods("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeGangPod})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, 3)
+		for _, pod := range pods.Items {
+			assert.Equal(t, gang, pod.Labels["scheduling.x-k8s.io/pod-group"])
+			assert.Equal(t, gang, pod.Labels[resources.LabelKeyGang])
+			assert.Equal(t, "3", pod.Labels[resources.LabelKeyGangSize])
+			assert.Equal(t, "scheduler-plugins-scheduler", pod.Spec.SchedulerName)
+		}
+		assert.Equal(t, "kubernetes-gang-creator", executor.Identifier())
+		assert.Equal(t, 1, executor.NamespaceMetrics()["default"].Succeeded)
+	})
+
+	t.Run("member pod creation returns error", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("exceeded quota")
+		})
+		dynamicClient := newFakeDynamicClient()
+		executor := NewGangCreator(
+			client, dynamicClient, "default", resources.GangStyleCoscheduling, "", []resources.GangSize{{Size: 2}}, false, util.NewRand(1), nil,
+		)
+
+		ctx := context.Background()
+		err := executor.Execute(ctx)
+		var createErr *ratelimiter.CreateError
+		assert.ErrorAs(t, err, &createErr)
+		assert.Equal(t, "Pod", createErr.Kind)
+		assert.Equal(t, 1, executor.NamespaceMetrics()["default"].Failed)
+		list, err := dynamicClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, list.Items, "the PodGroup of a failed gang must be deleted")
+	})
+
+	t.Run("PodGroup deletion error of a failed gang is returned", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("exceeded quota")
+		})
+		dynamicClient := newFakeDynamicClient()
+		dynamicClient.PrependReactor("delete", "podgroups", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, assert.AnError
+		})
+		executor := NewGangCreator(
+			client, dynamicClient, "default", resources.GangStyleCoscheduling, "", []resources.GangSize{{Size: 2}}, false, util.NewRand(1), nil,
+		)
+
+		err := executor.Execute(context.Background())
+		var createErr *ratelimiter.CreateError
+		assert.ErrorAs(t, err, &createErr)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -2,7 +2,7 @@ package executor
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
 	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
 	"github.com/dejanzele/batch-simulator/internal/util"
@@ -18,8 +18,138 @@ import (
 
 // kubernetesExecutor is defines base fields for kubernetes executors.
 type kubernetesExecutor struct {
-	client    kubernetes.Interface
-	namespace string
+	client kubernetes.Interface
+	// namespaces picks the namespace of every created object.
+	namespaces NamespacePicker
+	// createNamespace creates a namespace before the first object is created in it, it is optional.
+	createNamespace CreateNamespaceFunc
+	// namespaceState holds the created namespaces and the metrics of created objects keyed by namespace.
+	namespaceState *namespaceState
+	// rand is the source of random env vars, executors use their own source so they generate
+	// the same objects on every run with the same seed.
+	rand *util.Rand
+	// namer generates the names of created objects.
+	namer Namer
+	// identifier overrides the identifier of the executor if it is not empty.
+	identifier string
+	// template customizes the created objects, it is optional.
+	template *resources.Template
+}
+
+// Option configures a kubernetes executor.
+type Option func(*kubernetesExecutor)
+
+// WithNamespaces spreads the created objects across the namespaces picked by picker instead of a single namespace.
+func WithNamespaces(picker NamespacePicker) Option {
+	return func(e *kubernetesExecutor) {
+		e.namespaces = picker
+	}
+}
+
+// CreateNamespaceFunc creates the namespace if it does not exist.
+type CreateNamespaceFunc func(ctx context.Context, namespace string) error
+
+// WithNamespaceCreator creates every namespace with createNamespace before the first object is created in it,
+// so only the namespaces which are picked are created.
+func WithNamespaceCreator(createNamespace CreateNamespaceFunc) Option {
+	return func(e *kubernetesExecutor) {
+		e.createNamespace = createNamespace
+	}
+}
+
+// WithIdentifier overrides the identifier of the executor, so several executors of the same kind can be told apart,
+// e.g. the executors of different tenants.
+func WithIdentifier(identifier string) Option {
+	return func(e *kubernetesExecutor) {
+		e.identifier = identifier
+	}
+}
+
+// WithTemplate customizes the created pods and jobs with the template.
+func WithTemplate(template *resources.Template) Option {
+	return func(e *kubernetesExecutor) {
+		e.template = template
+	}
+}
+
+// newKubernetesExecutor creates the base of a kubernetes executor which creates objects in the namespace.
+func newKubernetesExecutor(client kubernetes.Interface, namespace string, rnd *util.Rand, namer Namer, opts []Option) kubernetesExecutor {
+	e := kubernetesExecutor{
+		client:     client,
+		namespaces: NewRoundRobinPicker([]string{namespace}),
+		namespaceState: &namespaceState{
+			created: make(map[string]bool),
+			metrics: make(map[string]*ratelimiter.Metrics),
+		},
+		rand:  rnd,
+		namer: namer,
+	}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+// identifierOr returns the overridden identifier of the executor or the default identifier.
+func (e *kubernetesExecutor) identifierOr(identifier string) string {
+	if e.identifier != "" {
+		return e.identifier
+	}
+	return identifier
+}
+
+// namespaceState holds the created namespaces and the metrics of created objects keyed by namespace.
+type namespaceState struct {
+	created map[string]bool
+	metrics map[string]*ratelimiter.Metrics
+	mutex   sync.Mutex
+}
+
+// ensureNamespace creates the namespace with the namespace creator, if any, unless it was already created.
+func (e *kubernetesExecutor) ensureNamespace(ctx context.Context, namespace string) error {
+	if e.createNamespace == nil {
+		return nil
+	}
+	e.namespaceState.mutex.Lock()
+	created := e.namespaceState.created[namespace]
+	e.namespaceState.mutex.Unlock()
+	if created {
+		return nil
+	}
+	if err := e.createNamespace(ctx, namespace); err != nil {
+		return err
+	}
+	e.namespaceState.mutex.Lock()
+	defer e.namespaceState.mutex.Unlock()
+	e.namespaceState.created[namespace] = true
+	return nil
+}
+
+// observe records the result of creating an object in the namespace.
+func (e *kubernetesExecutor) observe(namespace string, err error) {
+	e.namespaceState.mutex.Lock()
+	defer e.namespaceState.mutex.Unlock()
+	metrics, ok := e.namespaceState.metrics[namespace]
+	if !ok {
+		metrics = &ratelimiter.Metrics{}
+		e.namespaceState.metrics[namespace] = metrics
+	}
+	if err != nil {
+		metrics.Add(1, 1, 0)
+	} else {
+		metrics.Add(1, 0, 1)
+	}
+}
+
+// NamespaceMetrics returns the metrics of created objects keyed by namespace.
+func (e *kubernetesExecutor) NamespaceMetrics() map[string]ratelimiter.Metrics {
+	e.namespaceState.mutex.Lock()
+	defer e.namespaceState.mutex.Unlock()
+	metrics := make(map[string]ratelimiter.Metrics, len(e.namespaceState.metrics))
+	for namespace, m := range e.namespaceState.metrics {
+		metrics[namespace] = *m
+	}
+	return metrics
 }
 
 // PodCreator is used to create Pods.
@@ -29,26 +159,35 @@ type PodCreator struct {
 	randomEnvVars bool
 }
 
-func NewPodCreator(client kubernetes.Interface, namespace string, randomEnvVars bool) *PodCreator {
+// NewPodCreator creates a PodCreator, if namer is nil names are generated randomly from rnd.
+func NewPodCreator(client kubernetes.Interface, namespace string, randomEnvVars bool, rnd *util.Rand, namer Namer, opts ...Option) *PodCreator {
+	if namer == nil {
+		namer = NewRandomNamer(PodNamePrefix, rnd)
+	}
 	return &PodCreator{
-		kubernetesExecutor: kubernetesExecutor{
-			client:    client,
-			namespace: namespace,
-		},
-		randomEnvVars: randomEnvVars,
+		kubernetesExecutor: newKubernetesExecutor(client, namespace, rnd, namer, opts),
+		randomEnvVars:      randomEnvVars,
 	}
 }
 
 // Identifier returns the executor identifier.
 func (c *PodCreator) Identifier() string {
-	return "kubernetes-pod-creator"
+	return c.identifierOr("kubernetes-pod-creator")
 }
 
 // Execute creates a Pod.
 func (c *PodCreator) Execute(ctx context.Context) error {
-	name := fmt.Sprintf("fake-pod-%s", util.RandomRFC1123Name(16))
-	item := resources.NewFakePod(name, c.namespace, c.randomEnvVars)
-	_, err := c.client.CoreV1().Pods(c.namespace).Create(ctx, item, metav1.CreateOptions{})
+	name := c.namer.Name()
+	namespace := c.namespaces.Namespace()
+	item := resources.NewFakePod(name, namespace, c.randomEnvVars, c.rand)
+	if c.template != nil {
+		c.template.ApplyToPod(item, c.rand)
+	}
+	err := c.ensureNamespace(ctx, namespace)
+	if err == nil {
+		_, err = c.client.CoreV1().Pods(namespace).Create(ctx, item, metav1.CreateOptions{})
+	}
+	c.observe(namespace, err)
 	if err != nil {
 		return ratelimiter.NewCreateError(err, "v1", "Pod", item)
 	}
@@ -61,11 +200,13 @@ type NodeCreator struct {
 	kubernetesExecutor
 }
 
-func NewNodeCreator(client kubernetes.Interface) *NodeCreator {
+// NewNodeCreator creates a NodeCreator, if namer is nil names are generated randomly from rnd.
+func NewNodeCreator(client kubernetes.Interface, rnd *util.Rand, namer Namer) *NodeCreator {
+	if namer == nil {
+		namer = NewRandomNamer(NodeNamePrefix, rnd)
+	}
 	return &NodeCreator{
-		kubernetesExecutor: kubernetesExecutor{
-			client: client,
-		},
+		kubernetesExecutor: newKubernetesExecutor(client, metav1.NamespaceNone, rnd, namer, nil),
 	}
 }
 
@@ -76,7 +217,7 @@ func (c *NodeCreator) Identifier() string {
 
 // Execute creates a Node.
 func (c *NodeCreator) Execute(ctx context.Context) error {
-	name := fmt.Sprintf("fake-node-%s", util.RandomRFC1123Name(16))
+	name := c.namer.Name()
 	item := resources.NewFakeNode(name)
 	_, err := c.client.CoreV1().Nodes().Create(ctx, item, metav1.CreateOptions{})
 	if err != nil {
@@ -93,26 +234,35 @@ type JobCreator struct {
 	randomEnvVars bool
 }
 
-func NewJobCreator(client kubernetes.Interface, namespace string, randomEnvVars bool) *JobCreator {
+// NewJobCreator creates a JobCreator, if namer is nil names are generated randomly from rnd.
+func NewJobCreator(client kubernetes.Interface, namespace string, randomEnvVars bool, rnd *util.Rand, namer Namer, opts ...Option) *JobCreator {
+	if namer == nil {
+		namer = NewRandomNamer(JobNamePrefix, rnd)
+	}
 	return &JobCreator{
-		kubernetesExecutor: kubernetesExecutor{
-			client:    client,
-			namespace: namespace,
-		},
-		randomEnvVars: randomEnvVars,
+		kubernetesExecutor: newKubernetesExecutor(client, namespace, rnd, namer, opts),
+		randomEnvVars:      randomEnvVars,
 	}
 }
 
 // Identifier returns the executor identifier.
 func (c *JobCreator) Identifier() string {
-	return "kubernetes-job-creator"
+	return c.identifierOr("kubernetes-job-creator")
 }
 
 // Execute creates a Node.
 func (c *JobCreator) Execute(ctx context.Context) error {
-	name := fmt.Sprintf("fake-job-%s", util.RandomRFC1123Name(16))
-	item := resources.NewFakeJob(name, c.namespace, c.randomEnvVars)
-	_, err := c.client.BatchV1().Jobs(c.namespace).Create(ctx, item, metav1.CreateOptions{})
+	name := c.namer.Name()
+	namespace := c.namespaces.Namespace()
+	item := resources.NewFakeJob(name, namespace, c.randomEnvVars, c.rand)
+	if c.template != nil {
+		c.template.ApplyToJob(item, c.rand)
+	}
+	err := c.ensureNamespace(ctx, namespace)
+	if err == nil {
+		_, err = c.client.BatchV1().Jobs(namespace).Create(ctx, item, metav1.CreateOptions{})
+	}
+	c.observe(namespace, err)
 	if err != nil {
 		return ratelimiter.NewCreateError(err, "batch/v1", "Job", item)
 	}
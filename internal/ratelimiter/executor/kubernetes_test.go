@@ -3,12 +3,16 @@ package executor
 import (
 	"context"
 	"errors"
+	"github.com/dejanzele/batch-simulator/internal/fault"
 	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/util"
 	"github.com/stretchr/testify/assert"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/kubernetes/fake"
 	fakebatchv1 "k8s.io/client-go/kubernetes/typed/batch/v1/fake"
 	fakecorev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
@@ -19,8 +23,8 @@ import (
 func TestNewPodCreator(t *testing.T) {
 	t.Parallel()
 
-	creator := NewPodCreator(fake.NewSimpleClientset(), "test", false)
-	assert.Equal(t, "test", creator.namespace)
+	creator := NewPodCreator(fake.NewSimpleClientset(), "test", false, util.NewRand(1), nil)
+	assert.Equal(t, "test", creator.namespaces.Namespace())
 	assert.NotNil(t, creator.client)
 }
 
@@ -31,7 +35,7 @@ func TestPodCreator(t *testing.T) {
 		t.Parallel()
 
 		fakeClient := fake.NewSimpleClientset()
-		executor := NewPodCreator(fakeClient, "default", false)
+		executor := NewPodCreator(fakeClient, "default", false, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		if err := executor.Execute(ctx); err != nil {
@@ -57,7 +61,7 @@ func TestPodCreator(t *testing.T) {
 			PrependReactor("create", "pods", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, &corev1.Pod{}, errors.New("error creating pod")
 			})
-		executor := NewPodCreator(fakeClient, "default", false)
+		executor := NewPodCreator(fakeClient, "default", false, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		err := executor.Execute(ctx)
@@ -74,8 +78,8 @@ func TestPodCreator(t *testing.T) {
 func TestNewNodeCreator(t *testing.T) {
 	t.Parallel()
 
-	creator := NewNodeCreator(fake.NewSimpleClientset())
-	assert.Empty(t, creator.namespace)
+	creator := NewNodeCreator(fake.NewSimpleClientset(), util.NewRand(1), nil)
+	assert.Empty(t, creator.namespaces.Namespace())
 	assert.NotNil(t, creator.client)
 }
 
@@ -86,7 +90,7 @@ func TestNodeCreator(t *testing.T) {
 		t.Parallel()
 
 		fakeClient := fake.NewSimpleClientset()
-		executor := NewNodeCreator(fakeClient)
+		executor := NewNodeCreator(fakeClient, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		if err := executor.Execute(ctx); err != nil {
@@ -110,7 +114,7 @@ func TestNodeCreator(t *testing.T) {
 			PrependReactor("create", "nodes", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, &corev1.Node{}, errors.New("error creating node")
 			})
-		executor := NewNodeCreator(fakeClient)
+		executor := NewNodeCreator(fakeClient, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		err := executor.Execute(ctx)
@@ -127,8 +131,8 @@ func TestNodeCreator(t *testing.T) {
 func TestNewJobCreator(t *testing.T) {
 	t.Parallel()
 
-	creator := NewJobCreator(fake.NewSimpleClientset(), "test", false)
-	assert.Equal(t, "test", creator.namespace)
+	creator := NewJobCreator(fake.NewSimpleClientset(), "test", false, util.NewRand(1), nil)
+	assert.Equal(t, "test", creator.namespaces.Namespace())
 	assert.NotNil(t, creator.client)
 }
 
@@ -139,7 +143,7 @@ func TestJobCreator(t *testing.T) {
 		t.Parallel()
 
 		fakeClient := fake.NewSimpleClientset()
-		executor := NewJobCreator(fakeClient, "default", false)
+		executor := NewJobCreator(fakeClient, "default", false, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		if err := executor.Execute(ctx); err != nil {
@@ -165,7 +169,7 @@ func TestJobCreator(t *testing.T) {
 			PrependReactor("create", "jobs", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, &batchv1.Job{}, errors.New("error creating job")
 			})
-		executor := NewJobCreator(fakeClient, "default", false)
+		executor := NewJobCreator(fakeClient, "default", false, util.NewRand(1), nil)
 
 		ctx := context.Background()
 		err := executor.Execute(ctx)
@@ -178,3 +182,156 @@ func TestJobCreator(t *testing.T) {
 		assert.Equal(t, "error creating job", createError.Err.Error())
 	})
 }
+
+func TestCreators_Seeded(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	create := func(seed int64) ([]corev1.Pod, []batchv1.Job) {
+		fakeClient := fake.NewSimpleClientset()
+		podCreator := NewPodCreator(fakeClient, "default", true, util.NewRand(seed), nil)
+		jobCreator := NewJobCreator(fakeClient, "default", true, util.NewRand(seed), nil)
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, podCreator.Execute(ctx))
+			assert.NoError(t, jobCreator.Execute(ctx))
+		}
+		pods, err := fakeClient.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		jobs, err := fakeClient.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		return pods.Items, jobs.Items
+	}
+
+	pods, jobs := create(42)
+	samePods, sameJobs := create(42)
+	assert.Equal(t, pods, samePods, "pods created with the same seed must be identical")
+	assert.Equal(t, jobs, sameJobs, "jobs created with the same seed must be identical")
+
+	otherPods, _ := create(43)
+	assert.NotEqual(t, pods[0].Name, otherPods[0].Name)
+}
+
+func TestCreators_Faults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		err   error
+		check func(error) bool
+	}{
+		{"too many requests", fault.TooManyRequests(), apierrors.IsTooManyRequests},
+		{"internal error", fault.InternalError(), apierrors.IsInternalError},
+		{"conflict", fault.Conflict("pods", "fake-pod"), apierrors.IsConflict},
+		{"connection reset", fault.ConnectionReset(), utilnet.IsConnectionReset},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			fakeClient := fake.NewSimpleClientset()
+			faults := fault.Inject(fakeClient, fault.Fault{Verb: "create", Resource: "*", Err: tt.err, Times: 3})
+			executors := []ratelimiter.Executor[any]{
+				executorOf[*corev1.Pod](NewPodCreator(fakeClient, "default", false, util.NewRand(1), nil)),
+				executorOf[*corev1.Node](NewNodeCreator(fakeClient, util.NewRand(1), nil)),
+				executorOf[*batchv1.Job](NewJobCreator(fakeClient, "default", false, util.NewRand(1), nil)),
+			}
+			for _, executor := range executors {
+				err := executor.Execute(ctx)
+				var createError *ratelimiter.CreateError
+				assert.ErrorAs(t, err, &createError, executor.Identifier())
+				assert.True(t, tt.check(err), "the error of %s must keep its cause: %v", executor.Identifier(), err)
+			}
+			for _, executor := range executors {
+				assert.NoError(t, executor.Execute(ctx), "%s must succeed once the faults are exhausted", executor.Identifier())
+			}
+			assert.Equal(t, 2, faults.Requests("create", "pods"))
+			assert.Equal(t, 2, faults.Requests("create", "nodes"))
+			assert.Equal(t, 2, faults.Requests("create", "jobs"))
+		})
+	}
+}
+
+func TestCreators_Namespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset()
+	faults := fault.Inject(fakeClient, fault.Fault{Verb: "create", Resource: "jobs", Err: fault.TooManyRequests(), Times: 1})
+	namespaces := NewRoundRobinPicker([]string{"tenant-0", "tenant-1"})
+	podCreator := NewPodCreator(fakeClient, "default", false, util.NewRand(1), nil, WithNamespaces(namespaces))
+	jobCreator := NewJobCreator(fakeClient, "default", false, util.NewRand(1), nil, WithNamespaces(NewRoundRobinPicker([]string{"tenant-0"})))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, podCreator.Execute(ctx))
+	}
+	assert.Error(t, jobCreator.Execute(ctx))
+	assert.NoError(t, jobCreator.Execute(ctx))
+
+	for namespace, count := range map[string]int{"default": 0, "tenant-0": 2, "tenant-1": 1} {
+		pods, err := fakeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, count, namespace)
+		for _, pod := range pods.Items {
+			assert.Equal(t, namespace, pod.Namespace)
+		}
+	}
+	assert.Equal(t, map[string]ratelimiter.Metrics{
+		"tenant-0": {Executed: 2, Succeeded: 2},
+		"tenant-1": {Executed: 1, Succeeded: 1},
+	}, podCreator.NamespaceMetrics())
+	assert.Equal(t, map[string]ratelimiter.Metrics{
+		"tenant-0": {Executed: 2, Failed: 1, Succeeded: 1},
+	}, jobCreator.NamespaceMetrics())
+	assert.Equal(t, 2, faults.Requests("create", "jobs"))
+}
+
+func TestCreators_NamespaceCreator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fakeClient := fake.NewSimpleClientset()
+	created := make(map[string]int)
+	fail := true
+	createNamespace := func(ctx context.Context, namespace string) error {
+		created[namespace]++
+		if fail {
+			fail = false
+			return errors.New("namespace creation failed")
+		}
+		return nil
+	}
+	podCreator := NewPodCreator(fakeClient, "default", false, util.NewRand(1), nil,
+		WithNamespaces(NewRoundRobinPicker([]string{"tenant-0", "tenant-1"})),
+		WithNamespaceCreator(createNamespace),
+	)
+	assert.Error(t, podCreator.Execute(ctx), "the pod must not be created if its namespace was not created")
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, podCreator.Execute(ctx))
+	}
+
+	assert.Equal(t, map[string]int{"tenant-0": 2, "tenant-1": 1}, created, "namespaces must be created until they are created once")
+	assert.Equal(t, map[string]ratelimiter.Metrics{
+		"tenant-0": {Executed: 3, Failed: 1, Succeeded: 2},
+		"tenant-1": {Executed: 2, Succeeded: 2},
+	}, podCreator.NamespaceMetrics())
+	assert.Equal(t, 4, len(fakeClient.Actions()))
+}
+
+// anyExecutor adapts an Executor of a concrete type so executors of different types can be tested together.
+type anyExecutor struct {
+	identifier string
+	execute    func(context.Context) error
+}
+
+func executorOf[T any](executor ratelimiter.Executor[T]) ratelimiter.Executor[any] {
+	return &anyExecutor{identifier: executor.Identifier(), execute: executor.Execute}
+}
+
+func (e *anyExecutor) Identifier() string {
+	return e.identifier
+}
+
+func (e *anyExecutor) Execute(ctx context.Context) error {
+	return e.execute(ctx)
+}
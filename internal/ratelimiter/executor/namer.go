@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// Naming is the scheme used to name created objects.
+type Naming string
+
+const (
+	// NamingRandom names objects <prefix>-<16 random characters>.
+	NamingRandom Naming = "random"
+	// NamingSequential names objects <prefix>-<run-id>-<seq>, which allows resuming an interrupted run.
+	NamingSequential Naming = "sequential"
+)
+
+const (
+	// PodNamePrefix is the name prefix of fake pods.
+	PodNamePrefix = "fake-pod"
+	// NodeNamePrefix is the name prefix of fake nodes.
+	NodeNamePrefix = "fake-node"
+	// JobNamePrefix is the name prefix of fake jobs.
+	JobNamePrefix = "fake-job"
+	// GangNamePrefix is the name prefix of the PodGroups of fake gangs, their member pods are named <gang>-<index>.
+	GangNamePrefix = "fake-gang"
+)
+
+// ObjectNamePrefix returns the name prefix of fake objects of the kind, e.g. fake-podgroup.
+func ObjectNamePrefix(kind string) string {
+	return "fake-" + strings.ToLower(kind)
+}
+
+// Namer generates the names of the objects created by an executor.
+type Namer interface {
+	// Name returns the name of the next object.
+	Name() string
+}
+
+// RandomNamer generates <prefix>-<16 random characters> names.
+type RandomNamer struct {
+	prefix string
+	rand   *util.Rand
+}
+
+// NewRandomNamer creates a namer which generates random names from rnd.
+func NewRandomNamer(prefix string, rnd *util.Rand) *RandomNamer {
+	return &RandomNamer{prefix: prefix, rand: rnd}
+}
+
+// Name implements Namer.
+func (n *RandomNamer) Name() string {
+	return fmt.Sprintf("%s-%s", n.prefix, n.rand.RFC1123Name(16))
+}
+
+// SequentialNamer generates <prefix>-<run-id>-<seq> names, the sequence starts at 0.
+type SequentialNamer struct {
+	prefix string
+	next   atomic.Int64
+}
+
+// NewSequentialNamer creates a namer which generates sequential names for the run.
+func NewSequentialNamer(prefix, runID string) *SequentialNamer {
+	return &SequentialNamer{prefix: fmt.Sprintf("%s-%s-", prefix, runID)}
+}
+
+// Name implements Namer.
+func (n *SequentialNamer) Name() string {
+	return n.prefix + strconv.FormatInt(n.next.Add(1)-1, 10)
+}
+
+// Seq returns the sequence number of a name generated by the namer, false if the name was not generated by it.
+func (n *SequentialNamer) Seq(name string) (int64, bool) {
+	suffix, ok := strings.CutPrefix(name, n.prefix)
+	if !ok {
+		return 0, false
+	}
+	seq, err := strconv.ParseInt(suffix, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// SetNext sets the sequence number of the next generated name.
+func (n *SequentialNamer) SetNext(seq int64) {
+	n.next.Store(seq)
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRandomNamer(t *testing.T) {
+	t.Parallel()
+
+	assert.Regexp(t, `^fake-pod-[a-z0-9]{16}$`, NewRandomNamer(PodNamePrefix, util.NewRand(1)).Name())
+	assert.Equal(t, NewRandomNamer(PodNamePrefix, util.NewRand(1)).Name(), NewRandomNamer(PodNamePrefix, util.NewRand(1)).Name())
+}
+
+func TestSequentialNamer(t *testing.T) {
+	t.Parallel()
+
+	namer := NewSequentialNamer(JobNamePrefix, "run-1")
+	assert.Equal(t, "fake-job-run-1-0", namer.Name())
+	assert.Equal(t, "fake-job-run-1-1", namer.Name())
+
+	namer.SetNext(42)
+	assert.Equal(t, "fake-job-run-1-42", namer.Name())
+
+	seq, ok := namer.Seq("fake-job-run-1-17")
+	assert.True(t, ok)
+	assert.Equal(t, int64(17), seq)
+	for _, name := range []string{"fake-job-run-2-17", "fake-job-run-1-x", "fake-job-run-1--1", "fake-pod-run-1-3"} {
+		_, ok := namer.Seq(name)
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync/atomic"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// Distribution is the distribution of created objects across namespaces.
+type Distribution string
+
+const (
+	// DistributionRoundRobin creates objects in every namespace in turn.
+	DistributionRoundRobin Distribution = "round-robin"
+	// DistributionWeighted picks the namespace of every object randomly, proportionally to the namespace weights.
+	DistributionWeighted Distribution = "weighted"
+	// DistributionZipf picks the namespace of every object randomly, the namespace with rank k (starting at 1)
+	// is picked proportionally to 1/k^s, which models a few large tenants and a long tail of small ones.
+	DistributionZipf Distribution = "zipf"
+)
+
+// NamespacePicker picks the namespace of every created object.
+type NamespacePicker interface {
+	// Namespace returns the namespace of the next object.
+	Namespace() string
+}
+
+// Spread configures the distribution of created objects across namespaces.
+type Spread struct {
+	// Namespaces are the namespaces across which objects are spread.
+	Namespaces []string
+	// Distribution is the distribution of objects across the namespaces, round-robin if empty.
+	Distribution Distribution
+	// Weights are the weights of the namespaces of the weighted distribution.
+	Weights []float64
+	// ZipfExponent is the exponent s of the Zipf distribution, it must be positive.
+	ZipfExponent float64
+}
+
+// Validate returns an error if the spread cannot be used to pick namespaces.
+func (s Spread) Validate() error {
+	if len(s.Namespaces) == 0 {
+		return fmt.Errorf("at least one namespace is required")
+	}
+	switch s.Distribution {
+	case "", DistributionRoundRobin:
+		return nil
+	case DistributionWeighted:
+		if len(s.Weights) != len(s.Namespaces) {
+			return fmt.Errorf("got %d weights for %d namespaces", len(s.Weights), len(s.Namespaces))
+		}
+		total := 0.0
+		for _, weight := range s.Weights {
+			if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+				return fmt.Errorf("weights must be finite and not negative, got %v", weight)
+			}
+			total += weight
+		}
+		if total == 0 {
+			return fmt.Errorf("at least one weight must be positive")
+		}
+		return nil
+	case DistributionZipf:
+		if s.ZipfExponent <= 0 || math.IsInf(s.ZipfExponent, 0) {
+			return fmt.Errorf("zipf exponent must be positive, got %v", s.ZipfExponent)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown distribution %q, must be %s, %s or %s", s.Distribution, DistributionRoundRobin, DistributionWeighted, DistributionZipf)
+	}
+}
+
+// Picker creates a picker of the spread which draws random namespaces from rnd, the spread must be valid.
+func (s Spread) Picker(rnd *util.Rand) NamespacePicker {
+	switch s.Distribution {
+	case DistributionWeighted:
+		return NewWeightedPicker(s.Namespaces, s.Weights, rnd)
+	case DistributionZipf:
+		weights := make([]float64, len(s.Namespaces))
+		for i := range weights {
+			weights[i] = 1 / math.Pow(float64(i+1), s.ZipfExponent)
+		}
+		return NewWeightedPicker(s.Namespaces, weights, rnd)
+	default:
+		return NewRoundRobinPicker(s.Namespaces)
+	}
+}
+
+// RoundRobinPicker picks every namespace in turn.
+type RoundRobinPicker struct {
+	namespaces []string
+	next       atomic.Uint64
+}
+
+// NewRoundRobinPicker creates a picker which picks the namespaces in turn, starting with the first one.
+func NewRoundRobinPicker(namespaces []string) *RoundRobinPicker {
+	return &RoundRobinPicker{namespaces: namespaces}
+}
+
+// Namespace implements NamespacePicker.
+func (p *RoundRobinPicker) Namespace() string {
+	return p.namespaces[(p.next.Add(1)-1)%uint64(len(p.namespaces))]
+}
+
+// WeightedPicker picks namespaces randomly, proportionally to their weights.
+type WeightedPicker struct {
+	namespaces []string
+	// cumulative holds the cumulative sum of the weights.
+	cumulative []float64
+	rand       *util.Rand
+}
+
+// NewWeightedPicker creates a picker which picks the namespaces randomly from rnd, proportionally to their weights.
+func NewWeightedPicker(namespaces []string, weights []float64, rnd *util.Rand) *WeightedPicker {
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, weight := range weights {
+		total += weight
+		cumulative[i] = total
+	}
+	return &WeightedPicker{namespaces: namespaces, cumulative: cumulative, rand: rnd}
+}
+
+// Namespace implements NamespacePicker.
+func (p *WeightedPicker) Namespace() string {
+	target := p.rand.Float64() * p.cumulative[len(p.cumulative)-1]
+	// the first namespace whose cumulative weight exceeds the target, namespaces with a weight of 0 are never picked
+	i := sort.Search(len(p.cumulative), func(i int) bool { return p.cumulative[i] > target })
+	return p.namespaces[min(i, len(p.namespaces)-1)]
+}
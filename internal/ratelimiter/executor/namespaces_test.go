@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSpread_Validate(t *testing.T) {
+	t.Parallel()
+
+	namespaces := []string{"ns-0", "ns-1"}
+	tests := map[string]struct {
+		spread Spread
+		valid  bool
+	}{
+		"round-robin is the default":   {spread: Spread{Namespaces: namespaces}, valid: true},
+		"namespaces are required":      {spread: Spread{Distribution: DistributionRoundRobin}},
+		"weighted":                     {spread: Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{1, 0}}, valid: true},
+		"weight per namespace":         {spread: Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{1}}},
+		"negative weight":              {spread: Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{2, -1}}},
+		"infinite weight":              {spread: Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{math.Inf(1), 1}}},
+		"all weights are zero":         {spread: Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{0, 0}}},
+		"zipf":                         {spread: Spread{Namespaces: namespaces, Distribution: DistributionZipf, ZipfExponent: 0.5}, valid: true},
+		"zipf exponent is positive":    {spread: Spread{Namespaces: namespaces, Distribution: DistributionZipf}},
+		"distribution must be defined": {spread: Spread{Namespaces: namespaces, Distribution: "uniform"}},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.spread.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRoundRobinPicker(t *testing.T) {
+	t.Parallel()
+
+	picker := NewRoundRobinPicker([]string{"ns-0", "ns-1", "ns-2"})
+	var picked []string
+	for i := 0; i < 5; i++ {
+		picked = append(picked, picker.Namespace())
+	}
+	assert.Equal(t, []string{"ns-0", "ns-1", "ns-2", "ns-0", "ns-1"}, picked)
+}
+
+func TestWeightedPicker(t *testing.T) {
+	t.Parallel()
+
+	namespaces := []string{"ns-0", "ns-1", "ns-2"}
+	picker := Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{3, 0, 1}}.Picker(util.NewRand(1))
+	counts := pick(picker, 10000)
+	assert.Zero(t, counts["ns-1"], "namespaces with a weight of 0 must never be picked")
+	assert.InDelta(t, 7500, counts["ns-0"], 300)
+	assert.InDelta(t, 2500, counts["ns-2"], 300)
+
+	other := Spread{Namespaces: namespaces, Distribution: DistributionWeighted, Weights: []float64{3, 0, 1}}.Picker(util.NewRand(1))
+	assert.Equal(t, counts, pick(other, 10000), "pickers with the same seed must pick the same namespaces")
+}
+
+func TestZipfPicker(t *testing.T) {
+	t.Parallel()
+
+	namespaces := []string{"ns-0", "ns-1", "ns-2", "ns-3"}
+	picker := Spread{Namespaces: namespaces, Distribution: DistributionZipf, ZipfExponent: 1}.Picker(util.NewRand(1))
+	counts := pick(picker, 20000)
+	// the weights are 1, 1/2, 1/3 and 1/4, so the namespaces get 48%, 24%, 16% and 12% of the objects
+	assert.InDelta(t, 9600, counts["ns-0"], 400)
+	assert.InDelta(t, 4800, counts["ns-1"], 400)
+	assert.InDelta(t, 3200, counts["ns-2"], 400)
+	assert.InDelta(t, 2400, counts["ns-3"], 400)
+}
+
+func pick(picker NamespacePicker, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[picker.Namespace()]++
+	}
+	return counts
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// UnstructuredCreator is used to create objects of an arbitrary kind, e.g. custom resources, from a template
+// through the dynamic client.
+type UnstructuredCreator struct {
+	kubernetesExecutor
+	// dynamicClient creates the objects.
+	dynamicClient dynamic.Interface
+	// resource is the resource of the created objects.
+	resource schema.GroupVersionResource
+	// namespaced is used to determine if the objects are created in a namespace or are cluster-scoped.
+	namespaced bool
+	// objectTemplate is the template of created objects.
+	objectTemplate *unstructured.Unstructured
+}
+
+// NewUnstructuredCreator creates an UnstructuredCreator which creates objects of the resource from the template
+// in the namespace, objects are cluster-scoped if namespace is empty.
+// If namer is nil names are generated randomly from rnd and prefixed with fake-<kind>.
+func NewUnstructuredCreator(
+	client dynamic.Interface,
+	resource schema.GroupVersionResource,
+	namespace string,
+	template *unstructured.Unstructured,
+	rnd *util.Rand,
+	namer Namer,
+	opts ...Option,
+) *UnstructuredCreator {
+	if namer == nil {
+		namer = NewRandomNamer(ObjectNamePrefix(template.GetKind()), rnd)
+	}
+	return &UnstructuredCreator{
+		kubernetesExecutor: newKubernetesExecutor(nil, namespace, rnd, namer, opts),
+		dynamicClient:      client,
+		resource:           resource,
+		namespaced:         namespace != metav1.NamespaceNone,
+		objectTemplate:     template,
+	}
+}
+
+// Identifier returns the executor identifier, e.g. kubernetes-podgroup-creator.
+func (c *UnstructuredCreator) Identifier() string {
+	return c.identifierOr("kubernetes-" + strings.ToLower(c.objectTemplate.GetKind()) + "-creator")
+}
+
+// Execute creates an object from the template.
+func (c *UnstructuredCreator) Execute(ctx context.Context) error {
+	name := c.namer.Name()
+	if !c.namespaced {
+		item := resources.NewFakeObject(c.objectTemplate, name, metav1.NamespaceNone)
+		_, err := c.dynamicClient.Resource(c.resource).Create(ctx, item, metav1.CreateOptions{})
+		if err != nil {
+			return ratelimiter.NewCreateError(err, item.GetAPIVersion(), item.GetKind(), item)
+		}
+		return nil
+	}
+	namespace := c.namespaces.Namespace()
+	item := resources.NewFakeObject(c.objectTemplate, name, namespace)
+	err := c.ensureNamespace(ctx, namespace)
+	if err == nil {
+		_, err = c.dynamicClient.Resource(c.resource).Namespace(namespace).Create(ctx, item, metav1.CreateOptions{})
+	}
+	c.observe(namespace, err)
+	if err != nil {
+		return ratelimiter.NewCreateError(err, item.GetAPIVersion(), item.GetKind(), item)
+	}
+	return nil
+}
+
+var _ ratelimiter.Executor[*unstructured.Unstructured] = &UnstructuredCreator{}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+var podGroups = schema.GroupVersionResource{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Resource: "podgroups"}
+
+func newPodGroupTemplate() *unstructured.Unstructured {
+	template := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"minMember": int64(2)}}}
+	template.SetAPIVersion("scheduling.x-k8s.io/v1alpha1")
+	template.SetKind("PodGroup")
+	return template
+}
+
+func newFakeDynamicClient() *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{podGroups: "PodGroupList"})
+}
+
+func TestUnstructuredCreator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("object creation succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := newFakeDynamicClient()
+		executor := NewUnstructuredCreator(fakeClient, podGroups, "default", newPodGroupTemplate(), util.NewRand(1), nil)
+
+		ctx := context.Background()
+		assert.NoError(t, executor.Execute(ctx))
+		list, err := fakeClient.Resource(podGroups).Namespace("default").List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeObject})
+		assert.NoError(t, err)
+		if !assert.Len(t, list.Items, 1) {
+			t.FailNow()
+		}
+		assert.Contains(t, list.Items[0].GetName(), "fake-podgroup-")
+		assert.Equal(t, "kubernetes-podgroup-creator", executor.Identifier())
+		assert.Equal(t, 1, executor.NamespaceMetrics()["default"].Succeeded)
+	})
+
+	t.Run("cluster-scoped objects are created without a namespace", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := newFakeDynamicClient()
+		created := 0
+		executor := NewUnstructuredCreator(
+			fakeClient, podGroups, metav1.NamespaceNone, newPodGroupTemplate(), util.NewRand(1), nil,
+			WithNamespaceCreator(func(ctx context.Context, namespace string) error {
+				created++
+				return nil
+			}),
+		)
+
+		ctx := context.Background()
+		assert.NoError(t, executor.Execute(ctx))
+		list, err := fakeClient.Resource(podGroups).List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err)
+		if !assert.Len(t, list.Items, 1) {
+			t.FailNow()
+		}
+		assert.Empty(t, list.Items[0].GetNamespace())
+		assert.Zero(t, created, "no namespace must be created for cluster-scoped objects")
+	})
+
+	t.Run("object creation returns error", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := newFakeDynamicClient()
+		fakeClient.PrependReactor("create", "podgroups", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("webhook denied the request")
+		})
+		executor := NewUnstructuredCreator(fakeClient, podGroups, "default", newPodGroupTemplate(), util.NewRand(1), nil)
+
+		err := executor.Execute(context.Background())
+		var createErr *ratelimiter.CreateError
+		assert.ErrorAs(t, err, &createErr)
+		assert.Equal(t, "PodGroup", createErr.Kind)
+		assert.Equal(t, 1, executor.NamespaceMetrics()["default"].Failed)
+	})
+}
@@ -1,15 +1,20 @@
 package ratelimiter
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
 
 // Metrics returns the metrics of the rate limiter.
 type Metrics struct {
 	// Executed is the number of work items that have been processed.
-	Executed int
+	Executed int `json:"executed"`
 	// Failed is the number of work items that have failed.
-	Failed int
+	Failed int `json:"failed"`
 	// Succeeded is the number of work items that have succeeded.
-	Succeeded int
+	Succeeded int `json:"succeeded"`
 }
 
 // Add adds the given metrics to the current metrics.
@@ -22,3 +27,28 @@ func (m *Metrics) Add(executed, failed, succeeded int) {
 func (m *Metrics) String() string {
 	return fmt.Sprintf("(executed: %d, failed: %d, succeeded: %d)", m.Executed, m.Failed, m.Succeeded)
 }
+
+// Sample is the result of a single rate limiter interval.
+type Sample struct {
+	// Time is the time at which the interval started.
+	Time time.Time `json:"time"`
+	// Executed is the number of work items processed in the interval.
+	Executed int `json:"executed"`
+	// Failed is the number of work items which failed in the interval.
+	Failed int `json:"failed"`
+	// Succeeded is the number of work items which succeeded in the interval.
+	Succeeded int `json:"succeeded"`
+	// Latency is the distribution of work item execution durations in the interval, in seconds.
+	Latency stats.Summary `json:"latency"`
+}
+
+// Summary summarizes all work items processed by a rate limiter.
+type Summary struct {
+	Metrics
+	// Resumed is the number of work items processed before the rate limiter was started, e.g. by an interrupted run.
+	Resumed int `json:"resumed,omitempty"`
+	// Rate is the configured and achieved processing rate.
+	Rate Rate `json:"rate"`
+	// Latency is the distribution of all work item execution durations, in seconds.
+	Latency stats.Summary `json:"latency"`
+}
@@ -5,13 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/stats"
 )
 
 // RateLimiter is used to limit the rate at which work items are processed.
 type RateLimiter[T any] struct {
 	// started indicates whether the rate limiter is currently running.
-	started bool
+	started atomic.Bool
+	// stopped is closed when the rate limiter is stopped, it unblocks Run and pending error sends.
+	stopped chan struct{}
+	// stopOnce closes stopped only once.
+	stopOnce sync.Once
 	// executor is the executor that should be used to process work items.
 	executor Executor[T]
 	// ticker is the ticker that should be used to trigger the rate limiter.
@@ -28,10 +35,61 @@ type RateLimiter[T any] struct {
 	metrics Metrics
 	// mutex is used to synchronize access to the metrics.
 	mutex sync.RWMutex
+	// executeMutex serializes the processing of intervals which overlap because work items are slower than the interval,
+	// it is not held by readers of the metrics.
+	executeMutex sync.Mutex
 	// limit is the maximum number of work items that the rate limiter can process.
 	// After the limit is reached, the rate limiter will stop processing work items.
-	// If limit is 0, then there is no limit.
 	limit int
+	// resumed is the number of work items processed before the rate limiter was started, they count toward the limit.
+	resumed int
+	// observer is notified about every processed work item.
+	observer Observer
+	// arrivals returns the number of work items to process in an interval.
+	arrivals Arrivals
+	// concurrency is the maximum number of work items of an interval which are executed concurrently.
+	concurrency int
+	// startedAt is the time at which the rate limiter was started.
+	startedAt time.Time
+	// stoppedAt is the time at which the rate limiter stopped running.
+	stoppedAt time.Time
+	// timeSeries holds a Sample for every interval in which work items were processed.
+	timeSeries []Sample
+	// latencies holds the execution duration of every processed work item.
+	latencies []time.Duration
+}
+
+// Observer is notified about every processed work item, e.g. to export metrics.
+type Observer interface {
+	// ObserveStarted is called before the work item is executed.
+	ObserveStarted(identifier string)
+	// ObserveFinished is called after the work item is executed with the execution duration and error, if any.
+	ObserveFinished(identifier string, duration time.Duration, err error)
+}
+
+// Observers notifies every observer in order.
+type Observers []Observer
+
+// ObserveStarted implements Observer.
+func (o Observers) ObserveStarted(identifier string) {
+	for _, observer := range o {
+		observer.ObserveStarted(identifier)
+	}
+}
+
+// ObserveFinished implements Observer.
+func (o Observers) ObserveFinished(identifier string, duration time.Duration, err error) {
+	for _, observer := range o {
+		observer.ObserveFinished(identifier, duration, err)
+	}
+}
+
+// Rate is the configured and achieved processing rate of a rate limiter.
+type Rate struct {
+	// Configured is the number of work items per second the rate limiter is configured to process.
+	Configured float64 `json:"configured"`
+	// Achieved is the number of work items per second the rate limiter processed while it was running.
+	Achieved float64 `json:"achieved"`
 }
 
 type Option[T any] func(*RateLimiter[T])
@@ -42,13 +100,75 @@ func WithLogger[T any](logger *slog.Logger) Option[T] {
 	}
 }
 
+// WithObserver configures an observer which is notified about every processed work item.
+func WithObserver[T any](observer Observer) Option[T] {
+	return func(r *RateLimiter[T]) {
+		r.observer = observer
+	}
+}
+
+// WithArrivals configures the number of work items processed in every interval, e.g. PoissonArrivals,
+// the configured number of requests is processed in every interval by default.
+func WithArrivals[T any](arrivals Arrivals) Option[T] {
+	return func(r *RateLimiter[T]) {
+		r.arrivals = arrivals
+	}
+}
+
+// WithConcurrency executes up to concurrency work items of an interval concurrently, so the configured rate is reached
+// even if work items are too slow to be executed one after another within the interval. The executor must be safe
+// for concurrent use. Work items are executed one after another by default.
+func WithConcurrency[T any](concurrency int) Option[T] {
+	return func(r *RateLimiter[T]) {
+		r.concurrency = concurrency
+	}
+}
+
+// Runner is the type independent view of a RateLimiter, so rate limiters of different types can be run and observed together.
+type Runner interface {
+	// Run starts the rate limiter and blocks until it is stopped.
+	Run(ctx context.Context)
+	// Stop stops the rate limiter.
+	Stop()
+	// IsRunning returns true if the rate limiter is currently running.
+	IsRunning() bool
+	// ErrChan returns the channel which receives the errors of work items, it must be drained while the rate limiter runs.
+	ErrChan() <-chan error
+	// Identifier returns the identifier of the executor used by the rate limiter.
+	Identifier() string
+	// Limit returns the maximum number of work items the rate limiter processes.
+	Limit() int
+	// Metrics returns the metrics of the rate limiter.
+	Metrics() Metrics
+	// Resumed returns the number of work items which were processed before the rate limiter was started.
+	Resumed() int
+	// Rate returns the configured and achieved processing rate of the rate limiter.
+	// The achieved rate is computed until the rate limiter stopped, so it does not decay when read later.
+	Rate() Rate
+	// TimeSeries returns the samples recorded for every interval in which work items were processed.
+	TimeSeries() []Sample
+	// Summary returns the metrics, rate and latency distribution of all processed work items.
+	Summary() Summary
+}
+
+var _ Runner = &RateLimiter[any]{}
+
 // New creates a new RateLimiter.
 // - frequency: the frequency of the rate limiter.
 // - requests: the number of work items to process per interval.
 // - executor: the executor to use to process the work items.
 // - opts: the options to configure the RateLimiter.
 func New[T any](frequency time.Duration, requests, limit int, executor Executor[T], opts ...Option[T]) *RateLimiter[T] {
-	rl := &RateLimiter[T]{interval: frequency, requests: requests, limit: limit, executor: executor, errChan: make(chan error)}
+	rl := &RateLimiter[T]{
+		interval:    frequency,
+		requests:    requests,
+		limit:       limit,
+		executor:    executor,
+		arrivals:    ConstantArrivals,
+		concurrency: 1,
+		errChan:     make(chan error),
+		stopped:     make(chan struct{}),
+	}
 	for _, opt := range opts {
 		opt(rl)
 	}
@@ -65,12 +185,22 @@ func (r *RateLimiter[T]) Run(ctx context.Context) {
 	defer r.ticker.Stop()
 
 	r.logger.Info("starting ratelimiter")
-	r.started = true
-	for r.started {
+	r.mutex.Lock()
+	r.startedAt = time.Now()
+	r.mutex.Unlock()
+	r.started.Store(true)
+	defer func() {
+		r.mutex.Lock()
+		r.stoppedAt = time.Now()
+		r.mutex.Unlock()
+	}()
+	for {
 		select {
 		case <-ctx.Done():
 			r.Stop()
 			return
+		case <-r.stopped:
+			return
 		case <-r.ticker.C:
 			go func() {
 				r.execute(ctx, r.errChan)
@@ -79,15 +209,19 @@ func (r *RateLimiter[T]) Run(ctx context.Context) {
 	}
 }
 
-// Stop stops the rate limiter.
+// Stop stops the rate limiter, it is safe to call Stop multiple times.
+// Work items which are already executing finish, but their errors are no longer sent if nobody receives them.
 func (r *RateLimiter[T]) Stop() {
-	r.logger.Info("stopping ratelimiter")
-	r.started = false
+	r.stopOnce.Do(func() {
+		r.logger.Info("stopping ratelimiter")
+		r.started.Store(false)
+		close(r.stopped)
+	})
 }
 
 // IsRunning returns true if the rate limiter is currently running.
 func (r *RateLimiter[T]) IsRunning() bool {
-	return r.started
+	return r.started.Load()
 }
 
 // ErrChan returns the channel that should be used to receive errors.
@@ -102,13 +236,76 @@ func (r *RateLimiter[T]) Metrics() Metrics {
 	return r.metrics
 }
 
-// execute fetches work items from queue and sends them to executor for processing.
-func (r *RateLimiter[T]) execute(ctx context.Context, errCh chan<- error) {
+// Resume counts work items which were processed before the rate limiter was started, e.g. by an interrupted run,
+// toward the limit. They are not included in the metrics, rate or latencies of this rate limiter.
+func (r *RateLimiter[T]) Resume(processed int) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	r.resumed = processed
+}
+
+// Resumed returns the number of work items which were processed before the rate limiter was started.
+func (r *RateLimiter[T]) Resumed() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.resumed
+}
+
+// Identifier returns the identifier of the executor used by the rate limiter.
+func (r *RateLimiter[T]) Identifier() string {
+	return r.executor.Identifier()
+}
+
+// Limit returns the maximum number of work items the rate limiter processes, including the resumed work items.
+func (r *RateLimiter[T]) Limit() int {
+	return r.limit
+}
+
+// Rate returns the configured and achieved processing rate of the rate limiter.
+// The achieved rate is computed until the rate limiter stopped, so it does not decay when read later.
+func (r *RateLimiter[T]) Rate() Rate {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	rate := Rate{Configured: float64(r.requests) / r.interval.Seconds()}
+	if !r.startedAt.IsZero() {
+		stoppedAt := r.stoppedAt
+		if stoppedAt.IsZero() {
+			stoppedAt = time.Now()
+		}
+		rate.Achieved = float64(r.metrics.Executed) / stoppedAt.Sub(r.startedAt).Seconds()
+	}
+	return rate
+}
+
+// TimeSeries returns a copy of the samples recorded for every interval in which work items were processed.
+func (r *RateLimiter[T]) TimeSeries() []Sample {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	timeSeries := make([]Sample, len(r.timeSeries))
+	copy(timeSeries, r.timeSeries)
+	return timeSeries
+}
+
+// Summary returns the metrics, rate and latency distribution of all processed work items.
+func (r *RateLimiter[T]) Summary() Summary {
+	rate := r.Rate()
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return Summary{
+		Metrics: r.metrics,
+		Resumed: r.resumed,
+		Rate:    rate,
+		Latency: stats.SummarizeDurations(r.latencies),
+	}
+}
+
+// execute fetches work items from queue and sends them to executor for processing.
+func (r *RateLimiter[T]) execute(ctx context.Context, errCh chan<- error) {
+	r.executeMutex.Lock()
+	defer r.executeMutex.Unlock()
 	started := time.Now()
 
-	executedSoFar := r.metrics.Executed
+	executedSoFar := r.Metrics().Executed + r.Resumed()
 	isLimitReached := executedSoFar >= r.limit
 	r.logger.Info("executing work items", "executed", executedSoFar, "limit", r.limit)
 	if isLimitReached {
@@ -116,23 +313,81 @@ func (r *RateLimiter[T]) execute(ctx context.Context, errCh chan<- error) {
 		r.Stop()
 		return
 	}
-	remaining := min(r.requests, r.limit-executedSoFar)
+	remaining := min(r.arrivals(r.requests), r.limit-executedSoFar)
 	var executed, failed, succeeded int
-	r.logger.Info("processing work items", "remaining", remaining, "requested", r.requests)
-	for i := 0; i < remaining; i++ {
-		itemProcessedAt := time.Now()
-		r.logger.Debug("executing work item", "index", i)
+	latencies := make([]time.Duration, 0, max(remaining, 0))
+	// resultsMutex guards the results of the interval when work items are executed concurrently
+	var resultsMutex sync.Mutex
+	executeItem := func(i int) {
+		duration, err := r.executeItem(ctx, errCh, i)
+		resultsMutex.Lock()
+		defer resultsMutex.Unlock()
 		executed++
-		if err := r.executor.Execute(ctx); err != nil {
+		latencies = append(latencies, duration)
+		if err != nil {
 			failed++
-			errCh <- fmt.Errorf("failed to execute work item: %w", err)
 		} else {
 			succeeded++
 		}
-		r.logger.Debug("executed work item", "index", i, "duration", time.Since(itemProcessedAt))
+	}
+	r.logger.Info("processing work items", "remaining", remaining, "requested", r.requests, "concurrency", r.concurrency)
+	if r.concurrency <= 1 {
+		for i := 0; i < remaining; i++ {
+			executeItem(i)
+		}
+	} else {
+		next := make(chan int)
+		var workers sync.WaitGroup
+		for w := 0; w < min(r.concurrency, remaining); w++ {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for i := range next {
+					executeItem(i)
+				}
+			}()
+		}
+		for i := 0; i < remaining; i++ {
+			next <- i
+		}
+		close(next)
+		workers.Wait()
 	}
 	if remaining > 0 {
+		r.mutex.Lock()
 		r.metrics.Add(executed, failed, succeeded)
+		r.latencies = append(r.latencies, latencies...)
+		r.timeSeries = append(r.timeSeries, Sample{
+			Time:      started,
+			Executed:  executed,
+			Failed:    failed,
+			Succeeded: succeeded,
+			Latency:   stats.SummarizeDurations(latencies),
+		})
+		r.mutex.Unlock()
 	}
 	r.logger.Info("processed work items", "executed", executed, "failed", failed, "succeeded", succeeded, "duration", time.Since(started))
 }
+
+// executeItem executes the work item with the index, notifies the observer and sends the error, if any, to errCh.
+func (r *RateLimiter[T]) executeItem(ctx context.Context, errCh chan<- error, i int) (time.Duration, error) {
+	itemProcessedAt := time.Now()
+	r.logger.Debug("executing work item", "index", i)
+	if r.observer != nil {
+		r.observer.ObserveStarted(r.executor.Identifier())
+	}
+	err := r.executor.Execute(ctx)
+	duration := time.Since(itemProcessedAt)
+	if r.observer != nil {
+		r.observer.ObserveFinished(r.executor.Identifier(), duration, err)
+	}
+	if err != nil {
+		select {
+		case errCh <- fmt.Errorf("failed to execute work item: %w", err):
+		case <-ctx.Done():
+		case <-r.stopped:
+		}
+	}
+	r.logger.Debug("executed work item", "index", i, "duration", duration)
+	return duration, err
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -12,7 +14,7 @@ import (
 func TestRateLimiter_Run(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("stops if started param updated to false", func(t *testing.T) {
+	t.Run("stops if Stop is called", func(t *testing.T) {
 		t.Parallel()
 
 		rl := New[int](1*time.Second, 1, 1, newNoopExecutor())
@@ -21,35 +23,36 @@ func TestRateLimiter_Run(t *testing.T) {
 
 		time.Sleep(10 * time.Millisecond)
 
-		assert.True(t, rl.started)
+		assert.True(t, rl.IsRunning())
 
-		rl.started = false
+		rl.Stop()
 
 		time.Sleep(10 * time.Millisecond)
 
-		assert.False(t, rl.started)
+		assert.False(t, rl.IsRunning())
 	})
 
 	t.Run("stops if context is cancelled", func(t *testing.T) {
 		t.Parallel()
 
-		rl := New[int](2*time.Millisecond, 1, 5, newNoopExecutor())
+		// the 5th work item blocks until the context is cancelled, so the rate limiter is running when it is cancelled
+		ex := newGatedExecutor(5)
+		rl := New[int](2*time.Millisecond, 1, 5, ex)
 		ctx, cancel := context.WithCancel(ctx)
 		go rl.Run(ctx)
 
-		time.Sleep(10 * time.Millisecond)
-
-		assert.True(t, rl.started)
+		<-ex.reached
+		assert.True(t, rl.IsRunning())
 
 		cancel()
 
-		time.Sleep(10 * time.Millisecond)
-
-		assert.False(t, rl.started)
+		assert.Eventually(t, func() bool { return !rl.IsRunning() }, time.Second, time.Millisecond)
 		assert.Equal(t, context.Canceled, ctx.Err())
-		assert.Equal(t, rl.metrics.Executed, 5)
-		assert.Equal(t, rl.metrics.Succeeded, 5)
-		assert.Equal(t, rl.metrics.Failed, 0)
+		close(ex.release)
+		assert.Eventually(t, func() bool { return rl.Metrics().Executed == 5 }, time.Second, time.Millisecond)
+		assert.Equal(t, rl.Metrics().Executed, 5)
+		assert.Equal(t, rl.Metrics().Succeeded, 5)
+		assert.Equal(t, rl.Metrics().Failed, 0)
 	})
 
 	t.Run("executes work items", func(t *testing.T) {
@@ -63,15 +66,15 @@ func TestRateLimiter_Run(t *testing.T) {
 		assert.Eventually(
 			t,
 			func() bool {
-				return len(ex.Cache) == 10
+				return len(ex.Values()) == 10
 			},
 			400*time.Millisecond,
 			20*time.Millisecond,
 		)
-		assert.ElementsMatch(t, ex.Cache, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
-		assert.Equal(t, rl.metrics.Executed, 10)
-		assert.Equal(t, rl.metrics.Succeeded, 10)
-		assert.Equal(t, rl.metrics.Failed, 0)
+		assert.ElementsMatch(t, ex.Values(), []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		assert.Equal(t, rl.Metrics().Executed, 10)
+		assert.Equal(t, rl.Metrics().Succeeded, 10)
+		assert.Equal(t, rl.Metrics().Failed, 0)
 	})
 
 	t.Run("executes work items with timeout", func(t *testing.T) {
@@ -88,15 +91,15 @@ func TestRateLimiter_Run(t *testing.T) {
 		assert.Eventually(
 			t,
 			func() bool {
-				return len(ex.Cache) == 4
+				return len(ex.Values()) == 4
 			},
 			200*time.Millisecond,
 			20*time.Millisecond,
 		)
-		assert.ElementsMatch(t, ex.Cache, []int{1, 2, 3, 4})
-		assert.Equal(t, rl.metrics.Executed, 4)
-		assert.Equal(t, rl.metrics.Succeeded, 4)
-		assert.Equal(t, rl.metrics.Failed, 0)
+		assert.ElementsMatch(t, ex.Values(), []int{1, 2, 3, 4})
+		assert.Equal(t, rl.Metrics().Executed, 4)
+		assert.Equal(t, rl.Metrics().Succeeded, 4)
+		assert.Equal(t, rl.Metrics().Failed, 0)
 	})
 
 	t.Run("executor returns error", func(t *testing.T) {
@@ -122,13 +125,208 @@ func TestRateLimiter_Run(t *testing.T) {
 		case <-ctx.Done():
 			t.Fatal("failed to receive error from errChan in given time")
 		}
-		assert.Equal(t, rl.metrics.Executed, 1)
-		assert.Equal(t, rl.metrics.Succeeded, 0)
-		assert.Equal(t, rl.metrics.Failed, 1)
+		assert.Equal(t, rl.Metrics().Executed, 1)
+		assert.Equal(t, rl.Metrics().Succeeded, 0)
+		assert.Equal(t, rl.Metrics().Failed, 1)
 	})
 
 }
 
+func TestRateLimiter_Observer(t *testing.T) {
+	t.Parallel()
+
+	observer := &recordingObserver{}
+	rl := New[int](10*time.Millisecond, 2, 3, newErrorExecutor(), WithObserver[int](observer))
+	assert.Equal(t, 200.0, rl.Rate().Configured)
+	assert.Equal(t, 0.0, rl.Rate().Achieved)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+	go func() {
+		for range rl.ErrChan() {
+		}
+	}()
+
+	assert.Eventually(t, func() bool {
+		return rl.Metrics().Executed == 3
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	observer.mutex.Lock()
+	defer observer.mutex.Unlock()
+	assert.Equal(t, 3, observer.started)
+	assert.Equal(t, 3, observer.failed)
+	assert.Equal(t, "error", observer.identifier)
+	assert.Greater(t, rl.Rate().Achieved, 0.0)
+}
+
+func TestRateLimiter_RateAfterStop(t *testing.T) {
+	t.Parallel()
+
+	rl := New[int](10*time.Millisecond, 2, 4, newNoopExecutor())
+	done := make(chan struct{})
+	go func() {
+		rl.Run(context.Background())
+		close(done)
+	}()
+	go func() {
+		for range rl.ErrChan() {
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("rate limiter did not stop")
+	}
+	achieved := rl.Rate().Achieved
+	assert.Greater(t, achieved, 0.0)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 4, rl.Metrics().Executed)
+	assert.Equal(t, achieved, rl.Rate().Achieved)
+}
+
+func TestRateLimiter_TimeSeries(t *testing.T) {
+	t.Parallel()
+
+	rl := New[int](10*time.Millisecond, 2, 3, newNoopExecutor())
+	assert.Empty(t, rl.TimeSeries())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		return rl.Metrics().Executed == 3
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	timeSeries := rl.TimeSeries()
+	assert.Len(t, timeSeries, 2)
+	assert.Equal(t, 2, timeSeries[0].Executed)
+	assert.Equal(t, 2, timeSeries[0].Succeeded)
+	assert.Equal(t, 2, timeSeries[0].Latency.Count)
+	assert.Equal(t, 1, timeSeries[1].Executed)
+	assert.True(t, timeSeries[1].Time.After(timeSeries[0].Time))
+
+	summary := rl.Summary()
+	assert.Equal(t, 3, summary.Executed)
+	assert.Equal(t, 3, summary.Succeeded)
+	assert.Equal(t, 3, summary.Latency.Count)
+	assert.Equal(t, 200.0, summary.Rate.Configured)
+}
+
+func TestRateLimiter_SlowExecutor(t *testing.T) {
+	t.Parallel()
+
+	ex := &slowExecutor{latency: 50 * time.Millisecond}
+	rl := New[int](10*time.Millisecond, 1, 3, ex)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	time.Sleep(30 * time.Millisecond)
+	start := time.Now()
+	_ = rl.Metrics()
+	_ = rl.Summary()
+	assert.Less(t, time.Since(start), 20*time.Millisecond, "reading metrics must not wait for executing work items")
+
+	assert.Eventually(t, func() bool {
+		return rl.Metrics().Executed == 3
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 3, rl.Metrics().Executed, "overlapping intervals must not exceed the limit")
+	assert.Equal(t, int64(3), ex.executed.Load())
+	assert.False(t, rl.IsRunning())
+}
+
+func TestRateLimiter_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	ex := &slowExecutor{latency: 100 * time.Millisecond}
+	rl := New[int](20*time.Millisecond, 5, 5, ex, WithConcurrency[int](5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	// executed one after another, the work items of the interval would take 500ms
+	assert.Eventually(t, func() bool {
+		return rl.Metrics().Executed == 5
+	}, 300*time.Millisecond, 5*time.Millisecond)
+	assert.Equal(t, int64(5), ex.executed.Load())
+	timeSeries := rl.TimeSeries()
+	if assert.Len(t, timeSeries, 1) {
+		assert.Equal(t, 5, timeSeries[0].Succeeded)
+		assert.Equal(t, 5, timeSeries[0].Latency.Count)
+	}
+}
+
+func TestRateLimiter_Resume(t *testing.T) {
+	t.Parallel()
+
+	ex := newCacheExecutor()
+	rl := New[int](5*time.Millisecond, 2, 10, ex)
+	rl.Resume(7)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	assert.Eventually(t, func() bool { return rl.Metrics().Executed == 3 }, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return !rl.IsRunning() }, time.Second, 5*time.Millisecond)
+	assert.Len(t, ex.Values(), 3, "only the work items remaining to the limit must be executed")
+	summary := rl.Summary()
+	assert.Equal(t, 7, summary.Resumed)
+	assert.Equal(t, 3, summary.Executed)
+}
+
+func TestRateLimiter_StopUnblocksErrors(t *testing.T) {
+	t.Parallel()
+
+	rl := New[int](10*time.Millisecond, 2, 10, newErrorExecutor())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rl.Run(ctx)
+
+	// nobody receives the errors, so the first interval blocks on sending its first error
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, rl.Metrics().Executed)
+
+	rl.Stop()
+	rl.Stop()
+	// the intervals which were already scheduled finish without anybody receiving their errors
+	assert.Eventually(t, func() bool {
+		return rl.Metrics().Executed >= 2
+	}, time.Second, 10*time.Millisecond)
+	summary := rl.Summary()
+	assert.Equal(t, summary.Executed, summary.Failed)
+	assert.False(t, rl.IsRunning())
+}
+
+type recordingObserver struct {
+	mutex      sync.Mutex
+	identifier string
+	started    int
+	failed     int
+}
+
+func (o *recordingObserver) ObserveStarted(identifier string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.identifier = identifier
+	o.started++
+}
+
+func (o *recordingObserver) ObserveFinished(identifier string, duration time.Duration, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if err != nil {
+		o.failed++
+	}
+}
+
 type noopExecutor struct{}
 
 func newNoopExecutor() *noopExecutor {
@@ -143,11 +341,43 @@ func (n *noopExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// gatedExecutor blocks the n-th work item until release is closed, reached is closed once the n-th work item started.
+type gatedExecutor struct {
+	n        int
+	executed atomic.Int64
+	reached  chan struct{}
+	release  chan struct{}
+}
+
+func newGatedExecutor(n int) *gatedExecutor {
+	return &gatedExecutor{n: n, reached: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedExecutor) Identifier() string {
+	return "gated"
+}
+
+func (g *gatedExecutor) Execute(ctx context.Context) error {
+	if g.executed.Add(1) == int64(g.n) {
+		close(g.reached)
+		<-g.release
+	}
+	return nil
+}
+
 type cacheExecutor struct {
+	mutex   sync.Mutex
 	Current int
 	Cache   []int
 }
 
+// Values returns a copy of the cached values.
+func (c *cacheExecutor) Values() []int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]int(nil), c.Cache...)
+}
+
 func newCacheExecutor() *cacheExecutor {
 	return &cacheExecutor{}
 }
@@ -157,11 +387,28 @@ func (c *cacheExecutor) Identifier() string {
 }
 
 func (c *cacheExecutor) Execute(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.Current++
 	c.Cache = append(c.Cache, c.Current)
 	return nil
 }
 
+type slowExecutor struct {
+	latency  time.Duration
+	executed atomic.Int64
+}
+
+func (s *slowExecutor) Identifier() string {
+	return "slow"
+}
+
+func (s *slowExecutor) Execute(ctx context.Context) error {
+	time.Sleep(s.latency)
+	s.executed.Add(1)
+	return nil
+}
+
 type errorExecutor struct{}
 
 func newErrorExecutor() *errorExecutor {
@@ -0,0 +1,177 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Chart dimensions in pixels.
+const (
+	chartWidth   = 760
+	chartHeight  = 280
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 40
+	yTicks       = 5
+)
+
+// palette holds the colors of chart series.
+var palette = []string{"#2563eb", "#dc2626", "#16a34a", "#d97706", "#7c3aed", "#0891b2", "#db2777", "#4b5563"}
+
+// Point is a single point of a line chart series.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Series is a named line in a line chart.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Bar is a single bar of a bar chart.
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// LineChart renders the series as an inline SVG line chart, the X axis is labeled with xLabel.
+func LineChart(series []Series, xLabel, yLabel string) template.HTML {
+	maxX, maxY := 0.0, 0.0
+	for _, s := range series {
+		for _, p := range s.Points {
+			maxX = math.Max(maxX, p.X)
+			maxY = math.Max(maxY, p.Y)
+		}
+	}
+	if maxX == 0 {
+		maxX = 1
+	}
+	maxY = niceMax(maxY)
+
+	var b strings.Builder
+	openSVG(&b)
+	drawAxes(&b, maxY, xLabel, yLabel)
+	for _, tick := range ticks(maxX) {
+		x := scaleX(tick, maxX)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, x, chartHeight-marginBottom+16, formatTick(tick))
+	}
+	for i, s := range series {
+		color := palette[i%len(palette)]
+		points := make([]string, 0, len(s.Points))
+		for _, p := range s.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", scaleX(p.X, maxX), scaleY(p.Y, maxY)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"><title>%s</title></polyline>`,
+			color, strings.Join(points, " "), template.HTMLEscapeString(s.Name))
+	}
+	b.WriteString(`</svg>`)
+	writeLegend(&b, series)
+	return template.HTML(b.String()) //nolint:gosec // all text is escaped
+}
+
+// BarChart renders the bars as an inline SVG bar chart.
+func BarChart(bars []Bar, yLabel string) template.HTML {
+	maxY := 0.0
+	for _, bar := range bars {
+		maxY = math.Max(maxY, bar.Value)
+	}
+	maxY = niceMax(maxY)
+
+	var b strings.Builder
+	openSVG(&b)
+	drawAxes(&b, maxY, "", yLabel)
+	if len(bars) > 0 {
+		slot := float64(chartWidth-marginLeft-marginRight) / float64(len(bars))
+		for i, bar := range bars {
+			x := float64(marginLeft) + float64(i)*slot
+			y := scaleY(bar.Value, maxY)
+			label := template.HTMLEscapeString(bar.Label)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`,
+				x+slot*0.1, y, slot*0.8, float64(chartHeight-marginBottom)-y, palette[0], label, formatTick(bar.Value))
+			fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`,
+				x+slot/2, chartHeight-marginBottom+16, label)
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()) //nolint:gosec // all text is escaped
+}
+
+func openSVG(b *strings.Builder) {
+	fmt.Fprintf(b, `<svg class="chart" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg" role="img">`, chartWidth, chartHeight)
+}
+
+// drawAxes draws the axes, horizontal grid lines with Y tick labels and the axis labels.
+func drawAxes(b *strings.Builder, maxY float64, xLabel, yLabel string) {
+	for i := 0; i <= yTicks; i++ {
+		value := maxY * float64(i) / yTicks
+		y := scaleY(value, maxY)
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`, marginLeft, y, chartWidth-marginRight, y)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, marginLeft-6, y+4, formatTick(value))
+	}
+	fmt.Fprintf(b, `<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`,
+		marginLeft, chartHeight-marginBottom, chartWidth-marginRight, chartHeight-marginBottom)
+	if xLabel != "" {
+		fmt.Fprintf(b, `<text x="%d" y="%d" class="label" text-anchor="middle">%s</text>`,
+			(chartWidth+marginLeft)/2, chartHeight-4, template.HTMLEscapeString(xLabel))
+	}
+	if yLabel != "" {
+		fmt.Fprintf(b, `<text x="12" y="%d" class="label" text-anchor="middle" transform="rotate(-90 12 %d)">%s</text>`,
+			(chartHeight-marginBottom)/2, (chartHeight-marginBottom)/2, template.HTMLEscapeString(yLabel))
+	}
+}
+
+func writeLegend(b *strings.Builder, series []Series) {
+	b.WriteString(`<div class="legend">`)
+	for i, s := range series {
+		fmt.Fprintf(b, `<span><i style="background:%s"></i>%s</span>`, palette[i%len(palette)], template.HTMLEscapeString(s.Name))
+	}
+	b.WriteString(`</div>`)
+}
+
+func scaleX(x, maxX float64) float64 {
+	return float64(marginLeft) + x/maxX*float64(chartWidth-marginLeft-marginRight)
+}
+
+func scaleY(y, maxY float64) float64 {
+	return float64(chartHeight-marginBottom) - y/maxY*float64(chartHeight-marginTop-marginBottom)
+}
+
+// niceMax rounds the maximum up to 1, 2 or 5 times a power of ten, so grid lines fall on round values.
+func niceMax(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// ticks returns evenly spaced X axis ticks between 0 and maxX.
+func ticks(maxX float64) []float64 {
+	step := niceMax(maxX) / yTicks
+	var result []float64
+	for v := 0.0; v <= maxX+step/1000; v += step {
+		result = append(result, v)
+	}
+	return result
+}
+
+func formatTick(v float64) string {
+	switch {
+	case v == math.Trunc(v) && math.Abs(v) < 1e9:
+		return fmt.Sprintf("%.0f", v)
+	case math.Abs(v) >= 10:
+		return fmt.Sprintf("%.1f", v)
+	default:
+		return fmt.Sprintf("%.3g", v)
+	}
+}
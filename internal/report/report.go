@@ -0,0 +1,192 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+var (
+	//go:embed template.html
+	templateHTML string
+	//go:embed style.css
+	styleCSS string
+
+	reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+		"seconds": formatSeconds,
+		"style":   func() template.CSS { return template.CSS(styleCSS) }, //nolint:gosec // embedded at build time
+	}).Parse(templateHTML))
+)
+
+// page is the data rendered by the report template.
+type page struct {
+	Title      string
+	Generated  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+	Config     [][2]string
+	Executors  []executorRow
+	Failures   []failureRow
+	Summary    results.Summary
+	JobClasses []string
+	Requests   *k8s.RequestReport
+
+	ThroughputChart      template.HTML
+	LatencyChart         template.HTML
+	FailuresChart        template.HTML
+	PodPhasesChart       template.HTML
+	SchedulerChart       template.HTML
+	SchedulingHistogram  template.HTML
+	StartupHistogram     template.HTML
+	HasPodPhases         bool
+	HasSchedulerSeries   bool
+	HasLatencyHistograms bool
+}
+
+type executorRow struct {
+	Name string
+	ratelimiter.Summary
+}
+
+type failureRow struct {
+	Executor string
+	Reason   string
+	Count    int
+}
+
+// Generate writes a self-contained HTML report of the run, which embeds all styles and charts.
+func Generate(w io.Writer, run *results.Run) error {
+	if err := reportTemplate.Execute(w, newPage(run)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+func newPage(run *results.Run) *page {
+	summary := run.Summary
+	p := &page{
+		Title:      run.Dir,
+		Generated:  time.Now(),
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		Duration:   summary.FinishedAt.Sub(summary.StartedAt).Round(time.Millisecond),
+		Summary:    summary,
+		Requests:   summary.Requests,
+		JobClasses: measurement.Classes(summary.Jobs),
+	}
+	for _, key := range sortedKeys(run.Config) {
+		p.Config = append(p.Config, [2]string{key, fmt.Sprint(run.Config[key])})
+	}
+	for _, name := range sortedKeys(summary.Executors) {
+		p.Executors = append(p.Executors, executorRow{Name: name, Summary: summary.Executors[name]})
+	}
+
+	var failureBars []Bar
+	for _, executor := range sortedKeys(summary.Failures) {
+		for _, reason := range sortedKeys(summary.Failures[executor]) {
+			count := summary.Failures[executor][reason]
+			p.Failures = append(p.Failures, failureRow{Executor: executor, Reason: reason, Count: count})
+			failureBars = append(failureBars, Bar{Label: reason, Value: float64(count)})
+		}
+	}
+	if len(failureBars) > 0 {
+		p.FailuresChart = BarChart(failureBars, "failures")
+	}
+
+	var throughput, latency []Series
+	for _, name := range sortedKeys(run.TimeSeries) {
+		samples := run.TimeSeries[name]
+		executed := Series{Name: name}
+		p99 := Series{Name: name}
+		for i, sample := range samples {
+			x := sample.Time.Sub(summary.StartedAt).Seconds()
+			executed.Points = append(executed.Points, Point{X: x, Y: perSecond(samples, i)})
+			p99.Points = append(p99.Points, Point{X: x, Y: sample.Latency.P99 * 1000})
+		}
+		throughput = append(throughput, executed)
+		latency = append(latency, p99)
+	}
+	p.ThroughputChart = LineChart(throughput, "seconds since start", "created per second")
+	p.LatencyChart = LineChart(latency, "seconds since start", "p99 latency (ms)")
+
+	if len(run.PodTimeSeries) > 0 {
+		p.HasPodPhases = true
+		phases := []Series{{Name: "pending"}, {Name: "running"}, {Name: "succeeded"}, {Name: "failed"}}
+		for _, sample := range run.PodTimeSeries {
+			x := sample.Time.Sub(summary.StartedAt).Seconds()
+			for i, v := range []int{sample.Pending, sample.Running, sample.Succeeded, sample.Failed} {
+				phases[i].Points = append(phases[i].Points, Point{X: x, Y: float64(v)})
+			}
+		}
+		p.PodPhasesChart = LineChart(phases, "seconds since start", "pods")
+	}
+
+	if len(run.SchedulerTimeSeries) > 0 {
+		p.HasSchedulerSeries = true
+		bound := Series{Name: "bound per second"}
+		pending := Series{Name: "pending"}
+		for _, sample := range run.SchedulerTimeSeries {
+			x := sample.Time.Sub(summary.StartedAt).Seconds()
+			bound.Points = append(bound.Points, Point{X: x, Y: sample.BoundPerSecond})
+			pending.Points = append(pending.Points, Point{X: x, Y: float64(sample.Pending)})
+		}
+		p.SchedulerChart = LineChart([]Series{bound, pending}, "seconds since start", "pods")
+	}
+
+	if summary.Pods != nil {
+		p.HasLatencyHistograms = true
+		p.SchedulingHistogram = BarChart(histogramBars(summary.Pods.SchedulingHistogram), "pods")
+		p.StartupHistogram = BarChart(histogramBars(summary.Pods.StartupHistogram), "pods")
+	}
+	return p
+}
+
+// perSecond returns the number of work items executed per second in the i-th sample,
+// based on the time elapsed since the previous sample.
+func perSecond(samples []ratelimiter.Sample, i int) float64 {
+	if i == 0 {
+		return float64(samples[i].Executed)
+	}
+	elapsed := samples[i].Time.Sub(samples[i-1].Time).Seconds()
+	if elapsed <= 0 {
+		return float64(samples[i].Executed)
+	}
+	return float64(samples[i].Executed) / elapsed
+}
+
+// histogramBars converts the histogram buckets into bars labeled with their upper bound.
+func histogramBars(h stats.Histogram) []Bar {
+	bars := make([]Bar, 0, len(h.Counts))
+	for i, count := range h.Counts {
+		label := "+Inf"
+		if i < len(h.Bounds) {
+			label = "≤" + formatSeconds(h.Bounds[i])
+		}
+		bars = append(bars, Bar{Label: label, Value: float64(count)})
+	}
+	return bars
+}
+
+// formatSeconds formats seconds as a duration rounded to milliseconds.
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
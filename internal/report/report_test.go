@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/results"
+	"github.com/dejanzele/batch-simulator/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	podReport := measurement.PodReport{
+		SchedulingLatency:   stats.Summary{Count: 2, P50: 0.5, P99: 1},
+		SchedulingHistogram: stats.NewHistogram([]float64{0.5, 1}, stats.DefaultLatencyBounds),
+		StartupHistogram:    stats.NewHistogram([]float64{2, 3}, stats.DefaultLatencyBounds),
+	}
+	run := &results.Run{
+		Dir:    "results/run-<1>",
+		Config: map[string]any{"namespace": "default", "podCreatorLimit": 10},
+		Summary: results.Summary{
+			StartedAt:  started,
+			FinishedAt: started.Add(time.Minute),
+			Executors: map[string]ratelimiter.Summary{
+				"kubernetes-pod-creator": {
+					Metrics: ratelimiter.Metrics{Executed: 10, Succeeded: 9, Failed: 1},
+					Rate:    ratelimiter.Rate{Configured: 5, Achieved: 4.5},
+					Latency: stats.Summary{Count: 10, P50: 0.012, P99: 0.25},
+				},
+			},
+			Failures:  map[string]map[string]int{"kubernetes-pod-creator": {"AlreadyExists": 1}},
+			Pods:      &podReport,
+			Scheduler: &measurement.SchedulerReport{Bound: 9, PeakPending: 3, Saturated: true, SaturatedAt: &started},
+			Jobs:      map[string]measurement.JobClassReport{"small": {Jobs: 2, Succeeded: 2, Makespan: 12}},
+			Requests:  &k8s.RequestReport{Requests: []k8s.RequestStats{{Verb: "create", Resource: "pods", Count: 10}}},
+		},
+		TimeSeries: map[string][]ratelimiter.Sample{
+			"kubernetes-pod-creator": {
+				{Time: started.Add(time.Second), Executed: 5, Succeeded: 5},
+				{Time: started.Add(2 * time.Second), Executed: 5, Failed: 1, Succeeded: 4},
+			},
+		},
+		SchedulerTimeSeries: []measurement.SchedulerSample{{Time: started.Add(time.Second), Bound: 4, BoundPerSecond: 4, Pending: 1}},
+		PodTimeSeries:       []measurement.PhaseSample{{Time: started.Add(time.Second), PhaseCounts: measurement.PhaseCounts{Pending: 1, Running: 4}}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Generate(&buf, run))
+	html := buf.String()
+
+	for _, section := range []string{"configuration", "throughput", "latency", "errors", "pods", "scheduler", "jobs", "requests"} {
+		assert.Contains(t, html, `<section id="`+section+`">`)
+	}
+	assert.Contains(t, html, "results/run-&lt;1&gt;")
+	assert.Contains(t, html, "<td>podCreatorLimit</td><td>10</td>")
+	assert.Contains(t, html, "<td>AlreadyExists</td>")
+	assert.Contains(t, html, "The scheduler was saturated")
+	assert.Contains(t, html, "<polyline")
+	assert.Contains(t, html, "<rect")
+	assert.Contains(t, html, "<style>")
+	assert.NotContains(t, html, "apiserver")
+
+	// the report must not load any external resources
+	external := regexp.MustCompile(`(src|href)\s*=\s*"https?://`)
+	assert.False(t, external.MatchString(html))
+	assert.False(t, strings.Contains(html, "<script src"))
+}
+
+func TestGenerate_MinimalRun(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	assert.NoError(t, Generate(&buf, &results.Run{Dir: "run"}))
+	assert.Contains(t, buf.String(), "No failures were recorded.")
+	assert.Contains(t, buf.String(), "Scheduler throughput was not measured.")
+	assert.NotContains(t, buf.String(), `<section id="jobs">`)
+}
+
+func TestLineChart(t *testing.T) {
+	t.Parallel()
+
+	chart := string(LineChart([]Series{{Name: "<pods>", Points: []Point{{X: 0, Y: 1}, {X: 10, Y: 7}}}}, "seconds", "count"))
+	assert.Contains(t, chart, "&lt;pods&gt;")
+	assert.NotContains(t, chart, "<pods>")
+	assert.Equal(t, 1, strings.Count(chart, "<polyline"))
+}
+
+func TestNiceMax(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1.0, niceMax(0))
+	assert.Equal(t, 1.0, niceMax(0.8))
+	assert.Equal(t, 2.0, niceMax(1.5))
+	assert.Equal(t, 50.0, niceMax(32))
+	assert.Equal(t, 100.0, niceMax(51))
+}
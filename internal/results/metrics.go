@@ -0,0 +1,182 @@
+package results
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/stats"
+)
+
+// Unit is the unit of a metric value.
+type Unit string
+
+const (
+	// UnitSeconds is used for latencies and durations.
+	UnitSeconds Unit = "s"
+	// UnitPerSecond is used for throughputs.
+	UnitPerSecond Unit = "/s"
+	// UnitRatio is used for rates between 0 and 1 like the error rate.
+	UnitRatio Unit = "ratio"
+	// UnitCount is used for counters.
+	UnitCount Unit = "count"
+)
+
+// Direction tells whether an increase of a metric is an improvement or a regression.
+type Direction int
+
+const (
+	// Neutral metrics are neither better nor worse when they change (e.g. the number of executed work items).
+	Neutral Direction = iota
+	// LowerIsBetter metrics regress when they increase (e.g. latencies and error rates).
+	LowerIsBetter
+	// HigherIsBetter metrics regress when they decrease (e.g. throughputs).
+	HigherIsBetter
+)
+
+// Metric is a single named value derived from a run summary.
+type Metric struct {
+	// Name is the dotted metric name (e.g. pod.create.p99).
+	Name string `json:"name"`
+	// Value is the metric value in the base unit, latencies are in seconds.
+	Value float64 `json:"value"`
+	// Unit is the unit of the value.
+	Unit Unit `json:"unit"`
+	// Direction tells whether an increase is an improvement or a regression.
+	Direction Direction `json:"direction"`
+}
+
+// Metrics flattens the summary into metrics ordered by name.
+//
+// Executor metrics are named after the created resource and verb (e.g. pod.create.p99, job.create.error_rate),
+// pod lifecycle metrics are prefixed with pod (e.g. pod.failed, pod.scheduling.p95) and the remaining metrics
+// are prefixed with the component which measured them (scheduler, jobs.<class>, queues.<queue>, gangs, requests).
+func Metrics(summary *Summary) []Metric {
+	var m metricSet
+	if !summary.StartedAt.IsZero() && !summary.FinishedAt.IsZero() {
+		m.add("run.duration", summary.FinishedAt.Sub(summary.StartedAt).Seconds(), UnitSeconds, Neutral)
+	}
+	for _, id := range sortedKeys(summary.Executors) {
+		s := summary.Executors[id]
+		prefix := ExecutorMetricPrefix(id)
+		m.add(prefix+".executed", float64(s.Executed), UnitCount, Neutral)
+		m.add(prefix+".failed", float64(s.Failed), UnitCount, LowerIsBetter)
+		m.add(prefix+".error_rate", ratio(s.Failed, s.Executed), UnitRatio, LowerIsBetter)
+		m.add(prefix+".throughput", s.Rate.Achieved, UnitPerSecond, HigherIsBetter)
+		m.latency(prefix, s.Latency)
+	}
+	if pods := summary.Pods; pods != nil {
+		m.add("pod.succeeded", float64(pods.Counts.Succeeded), UnitCount, Neutral)
+		m.add("pod.failed", float64(pods.Counts.Failed), UnitCount, LowerIsBetter)
+		m.latency("pod.scheduling", pods.SchedulingLatency)
+		m.latency("pod.startup", pods.StartupLatency)
+		m.latency("pod.end_to_end", pods.EndToEndLatency)
+	}
+	if scheduler := summary.Scheduler; scheduler != nil {
+		m.add("scheduler.bound", float64(scheduler.Bound), UnitCount, Neutral)
+		m.add("scheduler.failed_scheduling", float64(scheduler.FailedScheduling), UnitCount, LowerIsBetter)
+		m.add("scheduler.peak_pending", float64(scheduler.PeakPending), UnitCount, LowerIsBetter)
+		m.add("scheduler.throughput.mean", scheduler.Throughput.Mean, UnitPerSecond, HigherIsBetter)
+		m.add("scheduler.throughput.max", scheduler.Throughput.Max, UnitPerSecond, HigherIsBetter)
+		saturated := 0.0
+		if scheduler.Saturated {
+			saturated = 1
+		}
+		m.add("scheduler.saturated", saturated, UnitCount, LowerIsBetter)
+	}
+	for _, class := range sortedKeys(summary.Jobs) {
+		r := summary.Jobs[class]
+		prefix := "jobs." + class
+		m.add(prefix+".succeeded", float64(r.Succeeded), UnitCount, Neutral)
+		m.add(prefix+".failed", float64(r.Failed), UnitCount, LowerIsBetter)
+		m.add(prefix+".error_rate", ratio(r.Failed, r.Succeeded+r.Failed), UnitRatio, LowerIsBetter)
+		m.add(prefix+".makespan", r.Makespan, UnitSeconds, LowerIsBetter)
+		m.latency(prefix+".queue_wait", r.QueueWait)
+		m.latency(prefix+".completion_time", r.CompletionTime)
+	}
+	for _, queue := range sortedKeys(summary.Queues) {
+		r := summary.Queues[queue]
+		prefix := "queues." + queue
+		m.add(prefix+".admitted", float64(r.Admitted), UnitCount, Neutral)
+		m.add(prefix+".throughput", r.Throughput, UnitPerSecond, HigherIsBetter)
+		m.latency(prefix+".admission_latency", r.AdmissionLatency)
+	}
+	if gangs := summary.Gangs; gangs != nil {
+		m.gangs("gangs", *gangs)
+		for _, size := range measurement.GangSizes(*gangs) {
+			m.gangs("gangs.size_"+strconv.Itoa(size), gangs.Sizes[size])
+		}
+	}
+	if requests := summary.Requests; requests != nil {
+		m.add("requests.throttled", float64(requests.Throttled), UnitCount, LowerIsBetter)
+		m.latency("requests.latency", requests.Latency)
+		m.latency("requests.rate_limiter_wait", requests.RateLimiterWait)
+	}
+	sort.Slice(m, func(i, j int) bool { return m[i].Name < m[j].Name })
+	return m
+}
+
+// ExecutorMetricPrefix returns the metric name prefix of an executor,
+// kubernetes-<resource>-creator becomes <resource>.create, the executor of a tenant workload-<tenant> becomes
+// workload.<tenant>.create and other identifiers are used as is.
+func ExecutorMetricPrefix(identifier string) string {
+	if tenant, ok := strings.CutPrefix(identifier, "workload-"); ok {
+		return "workload." + tenant + ".create"
+	}
+	name := strings.TrimPrefix(identifier, "kubernetes-")
+	if resource, ok := strings.CutSuffix(name, "-creator"); ok {
+		return resource + ".create"
+	}
+	return identifier
+}
+
+// renamedMetricPrefixes maps the prefixes of renamed metrics to their current prefix,
+// pod lifecycle metrics were named pods.* before they were renamed to pod.*.
+var renamedMetricPrefixes = map[string]string{"pods.": "pod."}
+
+// CanonicalMetricName returns the current name of a metric name or pattern which uses the prefix of a renamed metric,
+// e.g. pods.startup.p99 becomes pod.startup.p99, so thresholds, ignore patterns and assertions written for the old names
+// keep matching. Other names are returned as is.
+func CanonicalMetricName(name string) string {
+	for old, current := range renamedMetricPrefixes {
+		if rest, ok := strings.CutPrefix(name, old); ok {
+			return current + rest
+		}
+	}
+	return name
+}
+
+type metricSet []Metric
+
+func (m *metricSet) add(name string, value float64, unit Unit, direction Direction) {
+	*m = append(*m, Metric{Name: name, Value: value, Unit: unit, Direction: direction})
+}
+
+// gangs adds the placement counts and the time to full placement of the gangs.
+func (m *metricSet) gangs(prefix string, r measurement.GangReport) {
+	m.add(prefix+".fully_placed", float64(r.FullyPlaced), UnitCount, Neutral)
+	m.add(prefix+".partially_placed", float64(r.PartiallyPlaced), UnitCount, LowerIsBetter)
+	m.add(prefix+".unplaced", float64(r.Unplaced), UnitCount, LowerIsBetter)
+	m.latency(prefix+".time_to_full_placement", r.TimeToFullPlacement)
+}
+
+// latency adds the mean and percentiles of the latency distribution, it is skipped if there are no samples.
+func (m *metricSet) latency(prefix string, s stats.Summary) {
+	if s.Count == 0 {
+		return
+	}
+	m.add(prefix+".mean", s.Mean, UnitSeconds, LowerIsBetter)
+	m.add(prefix+".p50", s.P50, UnitSeconds, LowerIsBetter)
+	m.add(prefix+".p90", s.P90, UnitSeconds, LowerIsBetter)
+	m.add(prefix+".p95", s.P95, UnitSeconds, LowerIsBetter)
+	m.add(prefix+".p99", s.P99, UnitSeconds, LowerIsBetter)
+	m.add(prefix+".max", s.Max, UnitSeconds, LowerIsBetter)
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,83 @@
+package results
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	summary := &Summary{
+		StartedAt:  started,
+		FinishedAt: started.Add(90 * time.Second),
+		Executors: map[string]ratelimiter.Summary{
+			"kubernetes-pod-creator": {
+				Metrics: ratelimiter.Metrics{Executed: 10, Failed: 1, Succeeded: 9},
+				Rate:    ratelimiter.Rate{Configured: 5, Achieved: 4.5},
+				Latency: stats.Summary{Count: 10, Mean: 0.1, P50: 0.08, P90: 0.2, P99: 0.3, Max: 0.4},
+			},
+			"kubernetes-node-creator": {},
+		},
+		Scheduler: &measurement.SchedulerReport{Bound: 9, Saturated: true},
+		Jobs: map[string]measurement.JobClassReport{
+			"batch": {Jobs: 4, Succeeded: 3, Failed: 1, Makespan: 60},
+		},
+		Queues: map[string]measurement.QueueReport{
+			"simulator": {Jobs: 4, Admitted: 2, AdmissionLatency: stats.Summary{Count: 2, Mean: 1.5, P99: 2}, Throughput: 0.5},
+		},
+		Gangs: &measurement.GangReport{
+			Gangs: 3, FullyPlaced: 2, PartiallyPlaced: 1, TimeToFullPlacement: stats.Summary{Count: 2, P99: 4},
+			Sizes: map[int]measurement.GangReport{8: {Gangs: 1, PartiallyPlaced: 1}},
+		},
+	}
+
+	metrics := Metrics(summary)
+	byName := make(map[string]Metric, len(metrics))
+	for _, m := range metrics {
+		byName[m.Name] = m
+	}
+
+	assert.Equal(t, Metric{Name: "run.duration", Value: 90, Unit: UnitSeconds, Direction: Neutral}, byName["run.duration"])
+	assert.Equal(t, Metric{Name: "pod.create.p99", Value: 0.3, Unit: UnitSeconds, Direction: LowerIsBetter}, byName["pod.create.p99"])
+	assert.Equal(t, Metric{Name: "pod.create.throughput", Value: 4.5, Unit: UnitPerSecond, Direction: HigherIsBetter}, byName["pod.create.throughput"])
+	assert.InDelta(t, 0.1, byName["pod.create.error_rate"].Value, 1e-9)
+	assert.Equal(t, 0.0, byName["node.create.error_rate"].Value)
+	assert.NotContains(t, byName, "node.create.p99", "latencies without samples are skipped")
+	assert.Equal(t, 1.0, byName["scheduler.saturated"].Value)
+	assert.Equal(t, 0.25, byName["jobs.batch.error_rate"].Value)
+	assert.Equal(t, 60.0, byName["jobs.batch.makespan"].Value)
+	assert.Equal(t, Metric{Name: "queues.simulator.throughput", Value: 0.5, Unit: UnitPerSecond, Direction: HigherIsBetter}, byName["queues.simulator.throughput"])
+	assert.Equal(t, 2.0, byName["queues.simulator.admission_latency.p99"].Value)
+	assert.Equal(t, Metric{Name: "gangs.partially_placed", Value: 1, Unit: UnitCount, Direction: LowerIsBetter}, byName["gangs.partially_placed"])
+	assert.Equal(t, 4.0, byName["gangs.time_to_full_placement.p99"].Value)
+	assert.Equal(t, 1.0, byName["gangs.size_8.partially_placed"].Value)
+	assert.NotContains(t, byName, "pod.startup.p99", "pod metrics are skipped if pods were not measured")
+
+	for i := 1; i < len(metrics); i++ {
+		assert.Less(t, metrics[i-1].Name, metrics[i].Name)
+	}
+}
+
+func TestCanonicalMetricName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pod.startup.p99", CanonicalMetricName("pods.startup.p99"))
+	assert.Equal(t, "pod.*", CanonicalMetricName("pods.*"))
+	assert.Equal(t, "pod.failed", CanonicalMetricName("pod.failed"))
+	assert.Equal(t, "*.p99", CanonicalMetricName("*.p99"))
+}
+
+func TestExecutorMetricPrefix(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pod.create", ExecutorMetricPrefix("kubernetes-pod-creator"))
+	assert.Equal(t, "job.create", ExecutorMetricPrefix("kubernetes-job-creator"))
+	assert.Equal(t, "workload.team-a.create", ExecutorMetricPrefix("workload-team-a"))
+	assert.Equal(t, "custom", ExecutorMetricPrefix("custom"))
+}
@@ -0,0 +1,355 @@
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/metrics"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+)
+
+const (
+	// ConfigFile is the name of the file which holds the run configuration.
+	ConfigFile = "config.json"
+	// SummaryFile is the name of the file which holds the run summary.
+	SummaryFile = "summary.json"
+	// TimeSeriesCSVFile is the name of the CSV file which holds the per-interval executor samples.
+	TimeSeriesCSVFile = "timeseries.csv"
+	// TimeSeriesJSONFile is the name of the JSON file which holds the per-interval executor samples.
+	TimeSeriesJSONFile = "timeseries.json"
+	// APIServerFile is the name of the file which holds the API server metrics delta report.
+	APIServerFile = "apiserver.json"
+	// RequestsCSVFile is the name of the CSV file which holds the traced API requests by verb and resource.
+	RequestsCSVFile = "requests.csv"
+	// JobsCSVFile is the name of the CSV file which holds the job metrics of every job class.
+	JobsCSVFile = "jobs.csv"
+	// QueuesCSVFile is the name of the CSV file which holds the admission metrics of every Kueue queue.
+	QueuesCSVFile = "queues.csv"
+	// GangsCSVFile is the name of the CSV file which holds the placement of gangs of every size.
+	GangsCSVFile = "gangs.csv"
+	// PodTimeSeriesCSVFile is the name of the CSV file which holds the pod phase counts over time.
+	PodTimeSeriesCSVFile = "pods-timeseries.csv"
+	// PodTimeSeriesJSONFile is the name of the JSON file which holds the pod phase counts over time.
+	PodTimeSeriesJSONFile = "pods-timeseries.json"
+	// SchedulerTimeSeriesCSVFile is the name of the CSV file which holds the per-interval scheduler throughput samples.
+	SchedulerTimeSeriesCSVFile = "scheduler-timeseries.csv"
+	// SchedulerTimeSeriesJSONFile is the name of the JSON file which holds the per-interval scheduler throughput samples.
+	SchedulerTimeSeriesJSONFile = "scheduler-timeseries.json"
+)
+
+// Summary is the final summary of a simulation run.
+type Summary struct {
+	// RunID is the run ID stamped on every object created by the simulation.
+	RunID string `json:"runId,omitempty"`
+	// Seed is the seed of all randomness of the simulation.
+	Seed int64 `json:"seed"`
+	// StartedAt is the time at which the simulation started.
+	StartedAt time.Time `json:"startedAt"`
+	// FinishedAt is the time at which the simulation finished.
+	FinishedAt time.Time `json:"finishedAt"`
+	// Executors holds the summary of every rate limited executor keyed by executor identifier.
+	Executors map[string]ratelimiter.Summary `json:"executors"`
+	// Namespaces holds the metrics of the created objects per namespace keyed by executor identifier.
+	Namespaces map[string]map[string]ratelimiter.Metrics `json:"namespaces,omitempty"`
+	// Failures holds the number of failed work items by reason keyed by executor identifier.
+	Failures map[string]map[string]int `json:"failures,omitempty"`
+	// Pods holds the pod phase counts and lifecycle latencies, if they were measured.
+	Pods *measurement.PodReport `json:"pods,omitempty"`
+	// Scheduler holds the scheduler throughput and saturation status, if it was measured.
+	Scheduler *measurement.SchedulerReport `json:"scheduler,omitempty"`
+	// Jobs holds the job metrics keyed by job class, if they were measured.
+	Jobs map[string]measurement.JobClassReport `json:"jobs,omitempty"`
+	// Queues holds the admission metrics keyed by Kueue queue, if jobs were submitted to queues.
+	Queues map[string]measurement.QueueReport `json:"queues,omitempty"`
+	// Gangs holds the placement of gangs, if gangs were created.
+	Gangs *measurement.GangReport `json:"gangs,omitempty"`
+	// Requests holds the client-side API request stats, if requests were traced.
+	Requests *k8s.RequestReport `json:"requests,omitempty"`
+	// APIServer holds the API server metrics delta, if API server metrics were scraped.
+	APIServer *metrics.APIServerReport `json:"apiServer,omitempty"`
+}
+
+// Writer writes the results of a simulation run into a directory.
+type Writer struct {
+	dir string
+}
+
+// NewWriter creates the results directory if it does not exist and returns a Writer for it.
+func NewWriter(dir string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create results directory %s: %w", dir, err)
+	}
+	return &Writer{dir: dir}, nil
+}
+
+// Dir returns the results directory.
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// WriteConfig writes the run configuration.
+func (w *Writer) WriteConfig(config any) error {
+	return w.WriteJSON(ConfigFile, config)
+}
+
+// WriteSummary writes the run summary.
+func (w *Writer) WriteSummary(summary *Summary) error {
+	return w.WriteJSON(SummaryFile, summary)
+}
+
+// WriteTimeSeries writes the per-interval samples of every executor as CSV and JSON.
+func (w *Writer) WriteTimeSeries(series map[string][]ratelimiter.Sample) error {
+	if err := w.WriteJSON(TimeSeriesJSONFile, series); err != nil {
+		return err
+	}
+	return w.WriteCSV(TimeSeriesCSVFile, timeSeriesRecords(series))
+}
+
+// WriteSchedulerTimeSeries writes the per-interval scheduler throughput samples as CSV and JSON.
+func (w *Writer) WriteSchedulerTimeSeries(series []measurement.SchedulerSample) error {
+	if err := w.WriteJSON(SchedulerTimeSeriesJSONFile, series); err != nil {
+		return err
+	}
+	records := [][]string{{"time", "bound", "bound_per_second", "failed_scheduling", "pending"}}
+	for _, sample := range series {
+		records = append(records, []string{
+			sample.Time.Format(time.RFC3339Nano),
+			strconv.Itoa(sample.Bound),
+			formatFloat(sample.BoundPerSecond),
+			strconv.Itoa(sample.FailedScheduling),
+			strconv.Itoa(sample.Pending),
+		})
+	}
+	return w.WriteCSV(SchedulerTimeSeriesCSVFile, records)
+}
+
+// WritePodTimeSeries writes the pod phase counts over time as CSV and JSON.
+func (w *Writer) WritePodTimeSeries(series []measurement.PhaseSample) error {
+	if err := w.WriteJSON(PodTimeSeriesJSONFile, series); err != nil {
+		return err
+	}
+	records := [][]string{{"time", "pending", "running", "succeeded", "failed", "unknown", "deleted"}}
+	for _, sample := range series {
+		records = append(records, []string{
+			sample.Time.Format(time.RFC3339Nano),
+			strconv.Itoa(sample.Pending),
+			strconv.Itoa(sample.Running),
+			strconv.Itoa(sample.Succeeded),
+			strconv.Itoa(sample.Failed),
+			strconv.Itoa(sample.Unknown),
+			strconv.Itoa(sample.Deleted),
+		})
+	}
+	return w.WriteCSV(PodTimeSeriesCSVFile, records)
+}
+
+// WriteJobs writes the job metrics of every job class as CSV.
+func (w *Writer) WriteJobs(report map[string]measurement.JobClassReport) error {
+	records := [][]string{{
+		"class", "jobs", "succeeded", "failed",
+		"queue_wait_p50_seconds", "queue_wait_p99_seconds", "completion_time_p50_seconds", "completion_time_p99_seconds",
+		"makespan_seconds",
+	}}
+	for _, class := range measurement.Classes(report) {
+		r := report[class]
+		records = append(records, []string{
+			class,
+			strconv.Itoa(r.Jobs),
+			strconv.Itoa(r.Succeeded),
+			strconv.Itoa(r.Failed),
+			formatFloat(r.QueueWait.P50),
+			formatFloat(r.QueueWait.P99),
+			formatFloat(r.CompletionTime.P50),
+			formatFloat(r.CompletionTime.P99),
+			formatFloat(r.Makespan),
+		})
+	}
+	return w.WriteCSV(JobsCSVFile, records)
+}
+
+// WriteQueues writes the admission metrics of every Kueue queue as CSV.
+func (w *Writer) WriteQueues(report map[string]measurement.QueueReport) error {
+	records := [][]string{{
+		"queue", "jobs", "admitted", "admission_latency_p50_seconds", "admission_latency_p99_seconds", "throughput",
+	}}
+	for _, queue := range sortedKeys(report) {
+		r := report[queue]
+		records = append(records, []string{
+			queue,
+			strconv.Itoa(r.Jobs),
+			strconv.Itoa(r.Admitted),
+			formatFloat(r.AdmissionLatency.P50),
+			formatFloat(r.AdmissionLatency.P99),
+			formatFloat(r.Throughput),
+		})
+	}
+	return w.WriteCSV(QueuesCSVFile, records)
+}
+
+// WriteGangs writes the placement of the gangs of every size and of all gangs as CSV.
+func (w *Writer) WriteGangs(report *measurement.GangReport) error {
+	records := [][]string{{
+		"size", "gangs", "fully_placed", "partially_placed", "unplaced",
+		"time_to_full_placement_p50_seconds", "time_to_full_placement_p99_seconds",
+	}}
+	record := func(size string, r measurement.GangReport) []string {
+		return []string{
+			size,
+			strconv.Itoa(r.Gangs),
+			strconv.Itoa(r.FullyPlaced),
+			strconv.Itoa(r.PartiallyPlaced),
+			strconv.Itoa(r.Unplaced),
+			formatFloat(r.TimeToFullPlacement.P50),
+			formatFloat(r.TimeToFullPlacement.P99),
+		}
+	}
+	for _, size := range measurement.GangSizes(*report) {
+		records = append(records, record(strconv.Itoa(size), report.Sizes[size]))
+	}
+	records = append(records, record("all", *report))
+	return w.WriteCSV(GangsCSVFile, records)
+}
+
+// WriteRequests writes the traced API requests by verb and resource as CSV.
+func (w *Writer) WriteRequests(report *k8s.RequestReport) error {
+	records := [][]string{{
+		"verb", "resource", "count", "errors", "request_bytes", "response_bytes",
+		"latency_p50_seconds", "latency_p99_seconds", "rate_limiter_wait_p50_seconds", "rate_limiter_wait_p99_seconds", "throttled",
+	}}
+	for _, r := range report.Requests {
+		records = append(records, []string{
+			r.Verb,
+			r.Resource,
+			strconv.Itoa(r.Count),
+			strconv.Itoa(r.Errors),
+			strconv.FormatInt(r.RequestBytes, 10),
+			strconv.FormatInt(r.ResponseBytes, 10),
+			formatFloat(r.Latency.P50),
+			formatFloat(r.Latency.P99),
+			formatFloat(r.RateLimiterWait.P50),
+			formatFloat(r.RateLimiterWait.P99),
+			strconv.Itoa(r.Throttled),
+		})
+	}
+	return w.WriteCSV(RequestsCSVFile, records)
+}
+
+// WriteJSON writes the value as indented JSON into the named file.
+func (w *Writer) WriteJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(w.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// WriteCSV writes the records into the named file, the first record should be the header.
+func (w *Writer) WriteCSV(name string, records [][]string) error {
+	f, err := os.Create(filepath.Join(w.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	writer := csv.NewWriter(f)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// timeSeriesRecords converts the samples into CSV records ordered by executor and time.
+func timeSeriesRecords(series map[string][]ratelimiter.Sample) [][]string {
+	records := [][]string{{
+		"executor", "time", "executed", "failed", "succeeded",
+		"latency_min_seconds", "latency_mean_seconds", "latency_p50_seconds", "latency_p99_seconds", "latency_max_seconds",
+	}}
+	for _, executor := range sortedKeys(series) {
+		for _, sample := range series[executor] {
+			records = append(records, []string{
+				executor,
+				sample.Time.Format(time.RFC3339Nano),
+				strconv.Itoa(sample.Executed),
+				strconv.Itoa(sample.Failed),
+				strconv.Itoa(sample.Succeeded),
+				formatFloat(sample.Latency.Min),
+				formatFloat(sample.Latency.Mean),
+				formatFloat(sample.Latency.P50),
+				formatFloat(sample.Latency.P99),
+				formatFloat(sample.Latency.Max),
+			})
+		}
+	}
+	return records
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Run holds the results of a simulation run loaded from a results directory.
+type Run struct {
+	// Dir is the results directory.
+	Dir string
+	// Config is the simulation configuration.
+	Config map[string]any
+	// Summary is the run summary.
+	Summary Summary
+	// TimeSeries holds the per-interval samples of every executor.
+	TimeSeries map[string][]ratelimiter.Sample
+	// SchedulerTimeSeries holds the per-interval scheduler throughput samples.
+	SchedulerTimeSeries []measurement.SchedulerSample
+	// PodTimeSeries holds the pod phase counts over time.
+	PodTimeSeries []measurement.PhaseSample
+}
+
+// Load reads the results written into the directory.
+// The configuration and summary are required, the time series are loaded only if they were written.
+func Load(dir string) (*Run, error) {
+	run := &Run{Dir: dir}
+	for _, f := range []struct {
+		name     string
+		v        any
+		optional bool
+	}{
+		{ConfigFile, &run.Config, false},
+		{SummaryFile, &run.Summary, false},
+		{TimeSeriesJSONFile, &run.TimeSeries, true},
+		{SchedulerTimeSeriesJSONFile, &run.SchedulerTimeSeries, true},
+		{PodTimeSeriesJSONFile, &run.PodTimeSeries, true},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, f.name))
+		if err != nil {
+			if f.optional && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", f.name, err)
+		}
+		if err := json.Unmarshal(data, f.v); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", f.name, err)
+		}
+	}
+	return run, nil
+}
@@ -0,0 +1,139 @@
+package results
+
+import (
+	"encoding/json"
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/measurement"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "run-1")
+	writer, err := NewWriter(dir)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	assert.Equal(t, dir, writer.Dir())
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	series := map[string][]ratelimiter.Sample{
+		"kubernetes-pod-creator": {
+			{Time: started, Executed: 2, Succeeded: 2, Latency: stats.Summary{Count: 2, Min: 0.01, Mean: 0.015, P50: 0.01, P99: 0.02, Max: 0.02}},
+			{Time: started.Add(time.Second), Executed: 1, Failed: 1, Latency: stats.Summary{Count: 1, Min: 0.5, Mean: 0.5, P50: 0.5, P99: 0.5, Max: 0.5}},
+		},
+		"kubernetes-node-creator": {},
+	}
+	assert.NoError(t, writer.WriteConfig(map[string]any{"namespace": "default"}))
+	assert.NoError(t, writer.WriteTimeSeries(series))
+	assert.NoError(t, writer.WriteSummary(&Summary{StartedAt: started, FinishedAt: started.Add(2 * time.Second)}))
+
+	csv, err := os.ReadFile(filepath.Join(dir, TimeSeriesCSVFile))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(csv)), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "executor,time,executed,failed,succeeded"))
+	assert.Equal(t, "kubernetes-pod-creator,2024-01-01T12:00:00Z,2,0,2,0.01,0.015,0.01,0.02,0.02", lines[1])
+	assert.Equal(t, "kubernetes-pod-creator,2024-01-01T12:00:01Z,1,1,0,0.5,0.5,0.5,0.5,0.5", lines[2])
+
+	data, err := os.ReadFile(filepath.Join(dir, TimeSeriesJSONFile))
+	assert.NoError(t, err)
+	var decoded map[string][]ratelimiter.Sample
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, series["kubernetes-pod-creator"], decoded["kubernetes-pod-creator"])
+
+	assert.NoError(t, writer.WriteSchedulerTimeSeries([]measurement.SchedulerSample{
+		{Time: started, Bound: 4, BoundPerSecond: 2, FailedScheduling: 1, Pending: 3},
+	}))
+	csv, err = os.ReadFile(filepath.Join(dir, SchedulerTimeSeriesCSVFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "time,bound,bound_per_second,failed_scheduling,pending\n2024-01-01T12:00:00Z,4,2,1,3\n", string(csv))
+
+	assert.NoError(t, writer.WriteJobs(map[string]measurement.JobClassReport{
+		"small": {Jobs: 2, Succeeded: 1, Failed: 1, QueueWait: stats.Summary{P50: 1, P99: 2}, CompletionTime: stats.Summary{P50: 3, P99: 4}, Makespan: 5},
+	}))
+	csv, err = os.ReadFile(filepath.Join(dir, JobsCSVFile))
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(csv)), "\n")
+	assert.Equal(t, []string{"small", "2", "1", "1", "1", "2", "3", "4", "5"}, strings.Split(lines[1], ","))
+
+	assert.NoError(t, writer.WriteQueues(map[string]measurement.QueueReport{
+		"simulator": {Jobs: 3, Admitted: 2, AdmissionLatency: stats.Summary{P50: 1, P99: 2}, Throughput: 0.5},
+	}))
+	csv, err = os.ReadFile(filepath.Join(dir, QueuesCSVFile))
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(csv)), "\n")
+	assert.Equal(t, "simulator,3,2,1,2,0.5", lines[1])
+
+	assert.NoError(t, writer.WriteGangs(&measurement.GangReport{
+		Gangs: 2, FullyPlaced: 1, PartiallyPlaced: 1, TimeToFullPlacement: stats.Summary{P50: 3, P99: 3},
+		Sizes: map[int]measurement.GangReport{
+			2: {Gangs: 1, FullyPlaced: 1, TimeToFullPlacement: stats.Summary{P50: 3, P99: 3}},
+			8: {Gangs: 1, PartiallyPlaced: 1},
+		},
+	}))
+	csv, err = os.ReadFile(filepath.Join(dir, GangsCSVFile))
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(csv)), "\n")
+	assert.Equal(t, []string{"2,1,1,0,0,3,3", "8,1,0,1,0,0,0", "all,2,1,1,0,3,3"}, lines[1:])
+
+	assert.NoError(t, writer.WriteRequests(&k8s.RequestReport{Requests: []k8s.RequestStats{
+		{Verb: "create", Resource: "pods", Count: 3, RequestBytes: 300, ResponseBytes: 600, Throttled: 2},
+	}}))
+	csv, err = os.ReadFile(filepath.Join(dir, RequestsCSVFile))
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(csv)), "\n")
+	assert.Equal(t, "create,pods,3,0,300,600,0,0,0,0,2", lines[1])
+
+	for _, name := range []string{ConfigFile, SummaryFile, SchedulerTimeSeriesJSONFile} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	_, err := Load(dir)
+	assert.Error(t, err)
+
+	writer, err := NewWriter(dir)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	summary := &Summary{
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Minute),
+		Failures:   map[string]map[string]int{"kubernetes-pod-creator": {"AlreadyExists": 1}},
+	}
+	assert.NoError(t, writer.WriteConfig(map[string]any{"namespace": "default"}))
+	assert.NoError(t, writer.WriteSummary(summary))
+
+	run, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, run.Dir)
+	assert.Equal(t, "default", run.Config["namespace"])
+	assert.Equal(t, *summary, run.Summary)
+	assert.Nil(t, run.TimeSeries)
+	assert.Nil(t, run.PodTimeSeries)
+
+	pods := []measurement.PhaseSample{{Time: started, PhaseCounts: measurement.PhaseCounts{Pending: 2, Running: 1}}}
+	assert.NoError(t, writer.WritePodTimeSeries(pods))
+	csv, err := os.ReadFile(filepath.Join(dir, PodTimeSeriesCSVFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "time,pending,running,succeeded,failed,unknown,deleted\n2024-01-01T12:00:00Z,2,1,0,0,0,0\n", string(csv))
+
+	run, err = Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, pods, run.PodTimeSeries)
+}
@@ -21,7 +21,7 @@ func NewSimulatorJob(args []string) *batchv1.Job {
 	fullArgs := make([]string, 0, len(args)+1)
 	fullArgs = append(fullArgs, "run")
 	fullArgs = append(fullArgs, args...)
-	name := fmt.Sprintf("simulator-job-%s", util.RandomRFC1123Name(5))
+	name := fmt.Sprintf("simulator-job-%s", util.UniqueRFC1123Name(5))
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
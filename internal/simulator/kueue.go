@@ -0,0 +1,101 @@
+package simulator
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// labelKeyApp and labelValueBatchSimulator mark the Kueue CRDs installed by the simulator,
+	// only they are deleted by DeleteKueueCRDs.
+	labelKeyApp              = "app"
+	labelValueBatchSimulator = "batch-simulator"
+)
+
+var (
+	//go:embed "data/kueue-crds.yaml"
+	kueueCRDs  string
+	crdsSchema = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// InstallKueueCRDs creates the embedded Kueue CRDs which are required to create ResourceFlavors, ClusterQueues
+// and LocalQueues. CRDs which already exist, e.g. the CRDs of an installed Kueue release, are skipped and left untouched.
+// Admitting the jobs requires the Kueue controller.
+func InstallKueueCRDs(ctx context.Context, client dynamic.Interface) (installed, skipped []string, err error) {
+	crds, err := parseKueueCRDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, crd := range crds {
+		_, err := client.Resource(crdsSchema).Create(ctx, crd, metav1.CreateOptions{})
+		switch {
+		case k8serrors.IsAlreadyExists(err):
+			skipped = append(skipped, crd.GetName())
+		case err != nil:
+			return installed, skipped, fmt.Errorf("failed to create CRD %s: %w", crd.GetName(), err)
+		default:
+			installed = append(installed, crd.GetName())
+		}
+	}
+	return installed, skipped, nil
+}
+
+// DeleteKueueCRDs deletes the embedded Kueue CRDs which were installed by InstallKueueCRDs, which deletes their Kueue objects
+// as well. CRDs which are not labeled as installed by the simulator, e.g. the CRDs of a Kueue release, are skipped.
+func DeleteKueueCRDs(ctx context.Context, client dynamic.Interface) (deleted, skipped []string, err error) {
+	crds, err := parseKueueCRDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, crd := range crds {
+		existing, err := client.Resource(crdsSchema).Get(ctx, crd.GetName(), metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, skipped, fmt.Errorf("failed to get CRD %s: %w", crd.GetName(), err)
+		}
+		if existing.GetLabels()[labelKeyApp] != labelValueBatchSimulator {
+			skipped = append(skipped, crd.GetName())
+			continue
+		}
+		err = client.Resource(crdsSchema).Delete(ctx, crd.GetName(), metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: ptr.To(existing.GetUID())},
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return deleted, skipped, fmt.Errorf("failed to delete CRD %s: %w", crd.GetName(), err)
+		}
+		deleted = append(deleted, crd.GetName())
+	}
+	return deleted, skipped, nil
+}
+
+// parseKueueCRDs parses the embedded multi-document YAML of the Kueue CRDs.
+func parseKueueCRDs() ([]*unstructured.Unstructured, error) {
+	var crds []*unstructured.Unstructured
+	for _, document := range strings.Split(kueueCRDs, "\n---\n") {
+		data, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kueue CRDs: %w", err)
+		}
+		if string(data) == "null" {
+			continue
+		}
+		crd := &unstructured.Unstructured{}
+		if err := crd.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to parse kueue CRDs: %w", err)
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
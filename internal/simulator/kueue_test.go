@@ -0,0 +1,47 @@
+package simulator
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+func TestKueueCRDs(t *testing.T) {
+	t.Parallel()
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdsSchema: "CustomResourceDefinitionList"},
+	)
+	ctx := context.Background()
+	// the CRD of an installed kueue release
+	release := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"group": "kueue.x-k8s.io"}}}
+	release.SetAPIVersion("apiextensions.k8s.io/v1")
+	release.SetKind("CustomResourceDefinition")
+	release.SetName("clusterqueues.kueue.x-k8s.io")
+	_, err := client.Resource(crdsSchema).Create(ctx, release, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	installed, skipped, err := InstallKueueCRDs(ctx, client)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"resourceflavors.kueue.x-k8s.io", "localqueues.kueue.x-k8s.io", "workloads.kueue.x-k8s.io"}, installed)
+	assert.Equal(t, []string{"clusterqueues.kueue.x-k8s.io"}, skipped)
+	existing, err := client.Resource(crdsSchema).Get(ctx, "clusterqueues.kueue.x-k8s.io", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, existing.GetLabels(), "existing CRDs must not be overwritten")
+
+	deleted, skipped, err := DeleteKueueCRDs(ctx, client)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, installed, deleted)
+	assert.Equal(t, []string{"clusterqueues.kueue.x-k8s.io"}, skipped)
+	list, err := client.Resource(crdsSchema).List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, list.Items, 1) {
+		assert.Equal(t, "clusterqueues.kueue.x-k8s.io", list.Items[0].GetName(), "CRDs not installed by the simulator must not be deleted")
+	}
+}
@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+)
+
+const (
+	// PurposeRun marks permissions required by the run command.
+	PurposeRun = "run"
+	// PurposeClean marks permissions required by the clean command.
+	PurposeClean = "clean"
+	// PurposeWatch marks permissions required by the watch command.
+	PurposeWatch = "watch"
+	// PurposeInstall marks permissions required by the install command.
+	PurposeInstall = "install"
+)
+
+// PermissionOptions configures the optional simulation features whose permissions are required as well.
+type PermissionOptions struct {
+	// Kueue requires the permissions to set up and delete the Kueue queues.
+	Kueue bool
+	// GangStyle requires the permissions to create and delete the PodGroups of the gang style, if set.
+	GangStyle resources.GangStyle
+	// Objects requires the permissions to create and delete objects of the resource, if set.
+	Objects *schema.GroupVersionResource
+	// ObjectsClusterScoped configures whether the objects are cluster-scoped instead of namespaced.
+	ObjectsClusterScoped bool
+}
+
+// RequiredPermissions returns the permissions needed to run a simulation and clean it up afterwards.
+// Namespaced permissions are checked in each of the provided namespaces, duplicates are ignored.
+func RequiredPermissions(options PermissionOptions, namespaces ...string) []k8s.Permission {
+	permissions := []k8s.Permission{
+		{Purpose: PurposeRun, Verb: "get", Resource: "namespaces"},
+		{Purpose: PurposeRun, Verb: "create", Resource: "namespaces"},
+		{Purpose: PurposeRun, Verb: "create", Resource: "nodes"},
+		{Purpose: PurposeClean, Verb: "list", Resource: "nodes"},
+		{Purpose: PurposeClean, Verb: "delete", Resource: "nodes"},
+		{Purpose: PurposeClean, Verb: "list", Resource: "namespaces"},
+		{Purpose: PurposeClean, Verb: "delete", Resource: "namespaces"},
+		{Purpose: PurposeInstall, Verb: "get", Group: stagesSchema.Group, Resource: stagesSchema.Resource},
+		{Purpose: PurposeInstall, Verb: "create", Group: stagesSchema.Group, Resource: stagesSchema.Resource},
+	}
+	if options.Kueue {
+		for _, queues := range []schema.GroupVersionResource{resources.ResourceFlavors, resources.ClusterQueues} {
+			permissions = append(permissions, objectPermissions(queues, "", true)...)
+		}
+	}
+	if options.Objects != nil && options.ObjectsClusterScoped {
+		permissions = append(permissions, objectPermissions(*options.Objects, "", false)...)
+	}
+	seen := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		permissions = append(permissions,
+			k8s.Permission{Purpose: PurposeRun, Verb: "create", Resource: "pods", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeRun, Verb: "create", Group: "batch", Resource: "jobs", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeRun, Verb: "create", Resource: "events", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeWatch, Verb: "watch", Resource: "pods", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeWatch, Verb: "watch", Resource: "events", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeWatch, Verb: "watch", Group: "batch", Resource: "jobs", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "list", Resource: "pods", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "delete", Resource: "pods", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "patch", Resource: "pods", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "list", Group: "batch", Resource: "jobs", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "delete", Group: "batch", Resource: "jobs", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "list", Resource: "events", Namespace: namespace},
+			k8s.Permission{Purpose: PurposeClean, Verb: "deletecollection", Resource: "events", Namespace: namespace},
+		)
+		if options.Kueue {
+			permissions = append(permissions, objectPermissions(resources.LocalQueues, namespace, true)...)
+		}
+		if options.GangStyle != "" {
+			permissions = append(permissions, objectPermissions(options.GangStyle.PodGroupResource(), namespace, false)...)
+		}
+		if options.Objects != nil && !options.ObjectsClusterScoped {
+			permissions = append(permissions, objectPermissions(*options.Objects, namespace, false)...)
+		}
+	}
+	return permissions
+}
+
+// PermissionSeverity returns the severity of the denied permissions. Denied permissions of the run command are fatal,
+// as a simulation cannot create its resources without them, while the others only break watching, cleaning up or installing.
+func PermissionSeverity(results []k8s.PermissionResult) Severity {
+	severity := SeverityOK
+	for _, result := range results {
+		if result.Allowed {
+			continue
+		}
+		if result.Purpose == PurposeRun {
+			return SeverityFatal
+		}
+		severity = SeverityWarning
+	}
+	return severity
+}
+
+// objectPermissions returns the permissions needed to create objects of the resource during a run and delete them
+// afterwards, if applied is set the objects are updated when they already exist.
+func objectPermissions(resource schema.GroupVersionResource, namespace string, applied bool) []k8s.Permission {
+	permissions := []k8s.Permission{
+		{Purpose: PurposeRun, Verb: "create", Group: resource.Group, Resource: resource.Resource, Namespace: namespace},
+	}
+	if applied {
+		permissions = append(permissions,
+			k8s.Permission{Purpose: PurposeRun, Verb: "get", Group: resource.Group, Resource: resource.Resource, Namespace: namespace},
+			k8s.Permission{Purpose: PurposeRun, Verb: "update", Group: resource.Group, Resource: resource.Resource, Namespace: namespace},
+		)
+	}
+	return append(permissions,
+		k8s.Permission{Purpose: PurposeClean, Verb: "list", Group: resource.Group, Resource: resource.Resource, Namespace: namespace},
+		k8s.Permission{Purpose: PurposeClean, Verb: "delete", Group: resource.Group, Resource: resource.Resource, Namespace: namespace},
+	)
+}
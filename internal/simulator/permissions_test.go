@@ -0,0 +1,62 @@
+package simulator
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func TestRequiredPermissions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resources are deleted one by one", func(t *testing.T) {
+		t.Parallel()
+
+		permissions := RequiredPermissions(PermissionOptions{}, "default", "default", "")
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "delete", Resource: "nodes"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "delete", Resource: "pods", Namespace: "default"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "patch", Resource: "pods", Namespace: "default"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "delete", Group: "batch", Resource: "jobs", Namespace: "default"})
+		for _, permission := range permissions {
+			if permission.Verb == "deletecollection" {
+				assert.Equal(t, "events", permission.Resource, "only events are deleted with a delete collection")
+			}
+			assert.NotEqual(t, "podgroups", permission.Resource)
+			assert.NotEqual(t, resources.LocalQueues.Group, permission.Group)
+		}
+		assert.Len(t, RequiredPermissions(PermissionOptions{}, "default"), len(permissions), "duplicate namespaces must be ignored")
+	})
+
+	t.Run("optional features require their resources", func(t *testing.T) {
+		t.Parallel()
+
+		widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+		permissions := RequiredPermissions(PermissionOptions{
+			Kueue:                true,
+			GangStyle:            resources.GangStyleVolcano,
+			Objects:              &widgets,
+			ObjectsClusterScoped: true,
+		}, "default")
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeRun, Verb: "update", Group: "kueue.x-k8s.io", Resource: "clusterqueues"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "delete", Group: "kueue.x-k8s.io", Resource: "resourceflavors"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeRun, Verb: "create", Group: "kueue.x-k8s.io", Resource: "localqueues", Namespace: "default"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeRun, Verb: "create", Group: "scheduling.volcano.sh", Resource: "podgroups", Namespace: "default"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeClean, Verb: "delete", Group: "scheduling.volcano.sh", Resource: "podgroups", Namespace: "default"})
+		assert.Contains(t, permissions, k8s.Permission{Purpose: PurposeRun, Verb: "create", Group: "example.com", Resource: "widgets"})
+		assert.NotContains(t, permissions, k8s.Permission{Purpose: PurposeRun, Verb: "create", Group: "example.com", Resource: "widgets", Namespace: "default"})
+	})
+}
+
+func TestPermissionSeverity(t *testing.T) {
+	t.Parallel()
+
+	allowed := k8s.PermissionResult{Permission: k8s.Permission{Purpose: PurposeRun, Verb: "create", Resource: "pods"}, Allowed: true}
+	deniedClean := k8s.PermissionResult{Permission: k8s.Permission{Purpose: PurposeClean, Verb: "delete", Resource: "pods"}}
+	deniedRun := k8s.PermissionResult{Permission: k8s.Permission{Purpose: PurposeRun, Verb: "create", Resource: "nodes"}}
+
+	assert.Equal(t, SeverityOK, PermissionSeverity([]k8s.PermissionResult{allowed}))
+	assert.Equal(t, SeverityWarning, PermissionSeverity([]k8s.PermissionResult{allowed, deniedClean}))
+	assert.Equal(t, SeverityFatal, PermissionSeverity([]k8s.PermissionResult{deniedClean, deniedRun}))
+}
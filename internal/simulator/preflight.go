@@ -0,0 +1,325 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// Severity describes how serious a preflight finding is.
+type Severity string
+
+const (
+	// SeverityOK means the check passed.
+	SeverityOK Severity = "ok"
+	// SeverityWarning means the check found something which could slow down or partially break a simulation.
+	SeverityWarning Severity = "warning"
+	// SeverityFatal means the check found something which will reject simulation resources.
+	SeverityFatal Severity = "fatal"
+)
+
+// Finding is the outcome of a single preflight check.
+type Finding struct {
+	// Check is the name of the check which produced the finding.
+	Check string `json:"check"`
+	// Severity is the severity of the finding.
+	Severity Severity `json:"severity"`
+	// Message describes the finding.
+	Message string `json:"message"`
+}
+
+// simulatedResources are the resources created by the simulator which admission webhooks could intercept.
+var simulatedResources = map[string]bool{"pods": true, "jobs": true, "nodes": true}
+
+// RunPreflight inspects the cluster for quotas, limit ranges, admission webhooks and API priority & fairness settings
+// which could reject or throttle simulation resources, and server-side dry-run creates one sample Pod, Job and Node.
+// Failures to inspect a setting are reported as warnings, so RunPreflight never returns an error.
+func RunPreflight(ctx context.Context, client kubernetes.Interface, namespace string) []Finding {
+	var findings []Finding
+	findings = append(findings, checkResourceQuotas(ctx, client, namespace)...)
+	findings = append(findings, checkLimitRanges(ctx, client, namespace)...)
+	findings = append(findings, checkWebhooks(ctx, client)...)
+	findings = append(findings, checkPriorityAndFairness(ctx, client)...)
+	findings = append(findings, checkDryRun(ctx, client, namespace)...)
+	return findings
+}
+
+// checkResourceQuotas reports quotas which would reject fake pods or cap the number of simulation resources.
+func checkResourceQuotas(ctx context.Context, client kubernetes.Interface, namespace string) []Finding {
+	const check = "resource quotas"
+	quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("failed to list resource quotas: %v", err)}}
+	}
+	if len(quotas.Items) == 0 {
+		return []Finding{{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("no resource quotas in namespace %s", namespace)}}
+	}
+	// limit range defaults fill in the fields fake pods do not set, a failure to list them is reported by checkLimitRanges
+	var defaulted map[corev1.ResourceName]bool
+	if limitRanges, err := client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		defaulted = defaultedComputeResources(limitRanges.Items)
+	}
+	var findings []Finding
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		for name, hard := range quota.Spec.Hard {
+			used := quota.Status.Used[name]
+			switch {
+			case isUnspecifiedComputeResource(name) && defaulted[name]:
+				findings = append(findings, Finding{
+					Check:    check,
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf(
+						"quota %s tracks %s which fake pods do not specify, it is set by limit range defaults and limited to %s (used %s)",
+						quota.Name, name, hard.String(), used.String(),
+					),
+				})
+			case isUnspecifiedComputeResource(name):
+				findings = append(findings, Finding{
+					Check:    check,
+					Severity: SeverityFatal,
+					Message:  fmt.Sprintf("quota %s tracks %s which fake pods do not specify, pods will be rejected", quota.Name, name),
+				})
+			default:
+				findings = append(findings, Finding{
+					Check:    check,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("quota %s limits %s to %s (used %s)", quota.Name, name, hard.String(), used.String()),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// isUnspecifiedComputeResource returns true if a quota on the resource requires fake pods to set a field they do not set.
+func isUnspecifiedComputeResource(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceMemory, corev1.ResourceRequestsMemory, corev1.ResourceLimitsCPU, corev1.ResourceLimitsMemory:
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultedComputeResources returns the quota resources which container defaults of the limit ranges set on fake pods.
+// The default request of a resource falls back to its default limit, like the API server defaults it.
+func defaultedComputeResources(limitRanges []corev1.LimitRange) map[corev1.ResourceName]bool {
+	defaulted := make(map[corev1.ResourceName]bool)
+	for i := range limitRanges {
+		for _, limit := range limitRanges[i].Spec.Limits {
+			if limit.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			if _, ok := limit.Default[corev1.ResourceCPU]; ok {
+				defaulted[corev1.ResourceLimitsCPU] = true
+			}
+			if _, ok := limit.Default[corev1.ResourceMemory]; ok {
+				defaulted[corev1.ResourceLimitsMemory] = true
+				defaulted[corev1.ResourceRequestsMemory] = true
+				defaulted[corev1.ResourceMemory] = true
+			}
+			if _, ok := limit.DefaultRequest[corev1.ResourceMemory]; ok {
+				defaulted[corev1.ResourceRequestsMemory] = true
+				defaulted[corev1.ResourceMemory] = true
+			}
+		}
+	}
+	return defaulted
+}
+
+// checkLimitRanges reports limit ranges which reject the CPU request of fake pods.
+func checkLimitRanges(ctx context.Context, client kubernetes.Interface, namespace string) []Finding {
+	const check = "limit ranges"
+	limitRanges, err := client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("failed to list limit ranges: %v", err)}}
+	}
+	if len(limitRanges.Items) == 0 {
+		return []Finding{{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("no limit ranges in namespace %s", namespace)}}
+	}
+	request := resources.FakePodCPURequest()
+	var findings []Finding
+	for i := range limitRanges.Items {
+		limitRange := &limitRanges.Items[i]
+		for _, limit := range limitRange.Spec.Limits {
+			if limit.Type != corev1.LimitTypeContainer && limit.Type != corev1.LimitTypePod {
+				continue
+			}
+			if quantityExceeded(limit.Max, request, 1) || quantityExceeded(limit.Min, request, -1) {
+				findings = append(findings, Finding{
+					Check:    check,
+					Severity: SeverityFatal,
+					Message:  fmt.Sprintf("limit range %s does not allow %s cpu requested by fake pods", limitRange.Name, request.String()),
+				})
+				continue
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("limit range %s applies %s defaults to fake pods", limitRange.Name, strings.ToLower(string(limit.Type))),
+			})
+		}
+	}
+	return findings
+}
+
+// quantityExceeded compares the CPU quantity in the list with the request.
+// It returns true if the request is greater than the list value (sign 1) or lower than it (sign -1).
+func quantityExceeded(list corev1.ResourceList, request resource.Quantity, sign int) bool {
+	value, ok := list[corev1.ResourceCPU]
+	if !ok {
+		return false
+	}
+	return request.Cmp(value) == sign
+}
+
+// checkWebhooks reports admission webhooks which intercept the creation of simulation resources.
+func checkWebhooks(ctx context.Context, client kubernetes.Interface) []Finding {
+	const check = "admission webhooks"
+	var matched []string
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("failed to list validating webhooks: %v", err)}}
+	}
+	for i := range validating.Items {
+		for j := range validating.Items[i].Webhooks {
+			webhook := &validating.Items[i].Webhooks[j]
+			if rulesMatchSimulatedResources(webhook.Rules) {
+				matched = append(matched, describeWebhook("validating", webhook.Name, webhook.FailurePolicy))
+			}
+		}
+	}
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("failed to list mutating webhooks: %v", err)}}
+	}
+	for i := range mutating.Items {
+		for j := range mutating.Items[i].Webhooks {
+			webhook := &mutating.Items[i].Webhooks[j]
+			if rulesMatchSimulatedResources(webhook.Rules) {
+				matched = append(matched, describeWebhook("mutating", webhook.Name, webhook.FailurePolicy))
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return []Finding{{Check: check, Severity: SeverityOK, Message: "no admission webhooks intercept simulation resources"}}
+	}
+	findings := make([]Finding, 0, len(matched))
+	for _, m := range matched {
+		findings = append(findings, Finding{Check: check, Severity: SeverityWarning, Message: m})
+	}
+	return findings
+}
+
+// rulesMatchSimulatedResources returns true if any of the rules intercepts the creation of pods, jobs or nodes.
+func rulesMatchSimulatedResources(rules []admissionregistrationv1.RuleWithOperations) bool {
+	for _, rule := range rules {
+		if !matchesOperation(rule.Operations, admissionregistrationv1.Create) {
+			continue
+		}
+		for _, r := range rule.Resources {
+			if r == "*" || simulatedResources[r] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesOperation(operations []admissionregistrationv1.OperationType, operation admissionregistrationv1.OperationType) bool {
+	for _, o := range operations {
+		if o == operation || o == admissionregistrationv1.OperationAll {
+			return true
+		}
+	}
+	return false
+}
+
+func describeWebhook(kind, name string, failurePolicy *admissionregistrationv1.FailurePolicyType) string {
+	policy := admissionregistrationv1.Fail
+	if failurePolicy != nil {
+		policy = *failurePolicy
+	}
+	return fmt.Sprintf("%s webhook %s intercepts simulation resources (failure policy %s)", kind, name, policy)
+}
+
+// defaultNominalConcurrencyShares is the nominal concurrency shares of a limited priority level which does not set them.
+const defaultNominalConcurrencyShares = 30
+
+// checkPriorityAndFairness reports API priority & fairness levels which reject requests instead of queueing them.
+func checkPriorityAndFairness(ctx context.Context, client kubernetes.Interface) []Finding {
+	const check = "priority and fairness"
+	levels, err := client.FlowcontrolV1().PriorityLevelConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("failed to list priority level configurations: %v", err)}}
+	}
+	var findings []Finding
+	for i := range levels.Items {
+		level := &levels.Items[i]
+		if level.Spec.Type != flowcontrolv1.PriorityLevelEnablementLimited || level.Spec.Limited == nil {
+			continue
+		}
+		if level.Spec.Limited.LimitResponse.Type == flowcontrolv1.LimitResponseTypeReject {
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf(
+					"priority level %s rejects requests above %d concurrency shares instead of queueing them",
+					level.Name, ptr.Deref(level.Spec.Limited.NominalConcurrencyShares, defaultNominalConcurrencyShares),
+				),
+			})
+		}
+	}
+	if len(findings) == 0 {
+		return []Finding{{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("%d priority levels queue excess requests", len(levels.Items))}}
+	}
+	return findings
+}
+
+// checkDryRun server-side dry-run creates one sample Pod, Job and Node.
+func checkDryRun(ctx context.Context, client kubernetes.Interface, namespace string) []Finding {
+	const check = "dry-run create"
+	opts := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+	suffix := util.RandomRFC1123Name(16)
+	var findings []Finding
+	record := func(kind string, err error) {
+		if err != nil {
+			findings = append(findings, Finding{Check: check, Severity: SeverityFatal, Message: fmt.Sprintf("%s was rejected: %v", kind, err)})
+			return
+		}
+		findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("%s was accepted", kind)})
+	}
+	_, err := client.CoreV1().Pods(namespace).Create(ctx, resources.NewFakePod("fake-pod-"+suffix, namespace, false, util.Default()), opts)
+	record("pod", err)
+	_, err = client.BatchV1().Jobs(namespace).Create(ctx, resources.NewFakeJob("fake-job-"+suffix, namespace, false, util.Default()), opts)
+	record("job", err)
+	_, err = client.CoreV1().Nodes().Create(ctx, resources.NewFakeNode("fake-node-"+suffix), opts)
+	record("node", err)
+	return findings
+}
+
+// MaxSeverity returns the most severe severity among the findings.
+func MaxSeverity(findings []Finding) Severity {
+	severity := SeverityOK
+	for _, finding := range findings {
+		switch finding.Severity {
+		case SeverityFatal:
+			return SeverityFatal
+		case SeverityWarning:
+			severity = SeverityWarning
+		}
+	}
+	return severity
+}
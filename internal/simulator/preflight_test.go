@@ -0,0 +1,138 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	"testing"
+)
+
+func TestRunPreflight(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes on an empty cluster", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset()
+		var dryRuns int
+		fakeClient.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			dryRuns++
+			return true, nil, nil
+		})
+
+		findings := RunPreflight(context.Background(), fakeClient, "default")
+		assert.Equal(t, SeverityOK, MaxSeverity(findings))
+		assert.Equal(t, 3, dryRuns)
+	})
+
+	t.Run("reports quotas, limit ranges, webhooks and rejected dry-runs", func(t *testing.T) {
+		t.Parallel()
+
+		failurePolicy := admissionregistrationv1.Ignore
+		fakeClient := fake.NewSimpleClientset(
+			&corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "memory-quota", Namespace: "default"},
+				Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("1Gi")}},
+			},
+			&corev1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "small-containers", Namespace: "default"},
+				Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+					{Type: corev1.LimitTypeContainer, Max: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+				}},
+			},
+			&admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+					Name:          "pods.policy.example.com",
+					FailurePolicy: &failurePolicy,
+					Rules: []admissionregistrationv1.RuleWithOperations{{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+						Rule:       admissionregistrationv1.Rule{Resources: []string{"pods"}},
+					}},
+				}},
+			},
+			&flowcontrolv1.PriorityLevelConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "strict"},
+				Spec: flowcontrolv1.PriorityLevelConfigurationSpec{
+					Type: flowcontrolv1.PriorityLevelEnablementLimited,
+					Limited: &flowcontrolv1.LimitedPriorityLevelConfiguration{
+						NominalConcurrencyShares: ptr.To[int32](5),
+						LimitResponse:            flowcontrolv1.LimitResponse{Type: flowcontrolv1.LimitResponseTypeReject},
+					},
+				},
+			},
+		)
+		fakeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("denied by policy")
+		})
+
+		findings := RunPreflight(context.Background(), fakeClient, "default")
+		assert.Equal(t, SeverityFatal, MaxSeverity(findings))
+		assert.Contains(t, findings, Finding{
+			Check:    "resource quotas",
+			Severity: SeverityFatal,
+			Message:  "quota memory-quota tracks requests.memory which fake pods do not specify, pods will be rejected",
+		})
+		assert.Contains(t, findings, Finding{
+			Check:    "limit ranges",
+			Severity: SeverityFatal,
+			Message:  "limit range small-containers does not allow 1 cpu requested by fake pods",
+		})
+		assert.Contains(t, findings, Finding{
+			Check:    "admission webhooks",
+			Severity: SeverityWarning,
+			Message:  "validating webhook pods.policy.example.com intercepts simulation resources (failure policy Ignore)",
+		})
+		assert.Contains(t, findings, Finding{
+			Check:    "priority and fairness",
+			Severity: SeverityWarning,
+			Message:  "priority level strict rejects requests above 5 concurrency shares instead of queueing them",
+		})
+		assert.Contains(t, findings, Finding{Check: "dry-run create", Severity: SeverityFatal, Message: "pod was rejected: denied by policy"})
+		assert.Contains(t, findings, Finding{Check: "dry-run create", Severity: SeverityOK, Message: "node was accepted"})
+	})
+
+	t.Run("warns about quotas on resources set by limit range defaults", func(t *testing.T) {
+		t.Parallel()
+
+		fakeClient := fake.NewSimpleClientset(
+			&corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "compute-quota", Namespace: "default"},
+				Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+					corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+					corev1.ResourceLimitsCPU:      resource.MustParse("4"),
+				}},
+			},
+			&corev1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "memory-defaults", Namespace: "default"},
+				Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+					{Type: corev1.LimitTypeContainer, Default: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}},
+				}},
+			},
+		)
+		fakeClient.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+
+		findings := RunPreflight(context.Background(), fakeClient, "default")
+		assert.Contains(t, findings, Finding{
+			Check:    "resource quotas",
+			Severity: SeverityWarning,
+			Message:  "quota compute-quota tracks requests.memory which fake pods do not specify, it is set by limit range defaults and limited to 1Gi (used 0)",
+		})
+		assert.Contains(t, findings, Finding{
+			Check:    "resource quotas",
+			Severity: SeverityFatal,
+			Message:  "quota compute-quota tracks limits.cpu which fake pods do not specify, pods will be rejected",
+		})
+	})
+}
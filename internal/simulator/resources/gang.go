@@ -0,0 +1,158 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// GangStyle is the gang scheduler whose PodGroup API is used to create gangs.
+type GangStyle string
+
+const (
+	// GangStyleCoscheduling creates the PodGroups of the coscheduling plugin of scheduler-plugins.
+	GangStyleCoscheduling GangStyle = "coscheduling"
+	// GangStyleVolcano creates the PodGroups of Volcano.
+	GangStyleVolcano GangStyle = "volcano"
+)
+
+const (
+	// LabelValueFakePodGroup marks the PodGroups of fake gangs.
+	LabelValueFakePodGroup = "fake-podgroup"
+	// LabelSelectorFakePodGroup selects the PodGroups of fake gangs.
+	LabelSelectorFakePodGroup = LabelKeyApp + "=" + LabelValueFakePodGroup
+	// LabelValueFakeGang is the part-of label value of the member pods of fake gangs.
+	LabelValueFakeGang = "fake-gang"
+	// LabelSelectorFakeGangPod selects the member pods of fake gangs.
+	LabelSelectorFakeGangPod = LabelSelectorFakePod + "," + LabelKeyPartOf + "=" + LabelValueFakeGang
+	// LabelKeyGang is the label which holds the name of the gang of a member pod.
+	LabelKeyGang = "gang"
+	// LabelKeyGangSize is the label which holds the number of member pods of the gang of a member pod.
+	LabelKeyGangSize = "gang-size"
+	// labelKeyCoschedulingPodGroup is the label which assigns a pod to a PodGroup of the coscheduling plugin.
+	labelKeyCoschedulingPodGroup = "scheduling.x-k8s.io/pod-group"
+	// annotationKeyVolcanoPodGroup is the annotation which assigns a pod to a Volcano PodGroup.
+	annotationKeyVolcanoPodGroup = "scheduling.k8s.io/group-name"
+)
+
+// Validate returns an error if the gang style is not supported.
+func (s GangStyle) Validate() error {
+	switch s {
+	case GangStyleCoscheduling, GangStyleVolcano:
+		return nil
+	default:
+		return fmt.Errorf("gang style must be %s or %s, got %q", GangStyleCoscheduling, GangStyleVolcano, s)
+	}
+}
+
+// PodGroupResource returns the resource of the PodGroups of the gang style.
+func (s GangStyle) PodGroupResource() schema.GroupVersionResource {
+	if s == GangStyleVolcano {
+		return schema.GroupVersionResource{Group: "scheduling.volcano.sh", Version: "v1beta1", Resource: "podgroups"}
+	}
+	return schema.GroupVersionResource{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Resource: "podgroups"}
+}
+
+// SchedulerName returns the default scheduler name of the gang style, which member pods are scheduled by.
+func (s GangStyle) SchedulerName() string {
+	if s == GangStyleVolcano {
+		return "volcano"
+	}
+	return "scheduler-plugins-scheduler"
+}
+
+// GangSize is a number of member pods of a gang which is drawn proportionally to its weight.
+type GangSize struct {
+	// Size is the number of member pods, all of which must be placed for the gang to run.
+	Size int `json:"size"`
+	// Weight is the relative frequency of the size, 1 if 0.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// ValidateGangSizes returns an error if the gang sizes are empty, not positive or have negative weights.
+func ValidateGangSizes(sizes []GangSize) error {
+	if len(sizes) == 0 {
+		return fmt.Errorf("at least one gang size is required")
+	}
+	for _, s := range sizes {
+		if s.Size < 1 {
+			return fmt.Errorf("gang size must be at least 1, got %d", s.Size)
+		}
+		if s.Weight < 0 {
+			return fmt.Errorf("weight of gang size %d must not be negative, got %v", s.Size, s.Weight)
+		}
+	}
+	return nil
+}
+
+// DrawGangSize draws one of the gang sizes proportionally to their weights.
+func DrawGangSize(sizes []GangSize, rnd *util.Rand) int {
+	total := 0.0
+	for _, s := range sizes {
+		total += weight(s.Weight)
+	}
+	target := rnd.Float64() * total
+	for _, s := range sizes {
+		target -= weight(s.Weight)
+		if target < 0 {
+			return s.Size
+		}
+	}
+	return sizes[len(sizes)-1].Size
+}
+
+// NewFakePodGroup creates a PodGroup of the gang style with the specified name in the namespace,
+// which is only scheduled once all size member pods can be placed.
+func NewFakePodGroup(style GangStyle, name, namespace string, size int) *unstructured.Unstructured {
+	resource := style.PodGroupResource()
+	podGroup := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"minMember": int64(size)},
+	}}
+	podGroup.SetAPIVersion(resource.GroupVersion().String())
+	podGroup.SetKind("PodGroup")
+	podGroup.SetName(name)
+	podGroup.SetNamespace(namespace)
+	podGroup.SetLabels(withRunID(map[string]string{
+		LabelKeyApp:  LabelValueFakePodGroup,
+		"created-by": getHostname(),
+	}))
+	return podGroup
+}
+
+// NewFakeGangPod creates the member pod with the index of the gang, named <gang>-<index>, which is assigned to the PodGroup
+// of the gang style and scheduled by the scheduler. The pod is owned by the PodGroup if it is set, so it is deleted with it.
+func NewFakeGangPod(
+	style GangStyle,
+	podGroup *unstructured.Unstructured,
+	index, size int,
+	schedulerName string,
+	randomEnvVars bool,
+	rnd *util.Rand,
+) *corev1.Pod {
+	gang := podGroup.GetName()
+	pod := NewFakePod(fmt.Sprintf("%s-%d", gang, index), podGroup.GetNamespace(), randomEnvVars, rnd)
+	pod.Labels[LabelKeyPartOf] = LabelValueFakeGang
+	pod.Labels[LabelKeyGang] = gang
+	pod.Labels[LabelKeyGangSize] = strconv.Itoa(size)
+	if style == GangStyleVolcano {
+		pod.Annotations = map[string]string{annotationKeyVolcanoPodGroup: gang}
+	} else {
+		pod.Labels[labelKeyCoschedulingPodGroup] = gang
+	}
+	pod.Spec.SchedulerName = schedulerName
+	if podGroup.GetUID() != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: podGroup.GetAPIVersion(),
+			Kind:       podGroup.GetKind(),
+			Name:       gang,
+			UID:        podGroup.GetUID(),
+		}}
+	}
+	return pod
+}
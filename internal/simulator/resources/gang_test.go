@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"testing"
+)
+
+func TestDrawGangSize(t *testing.T) {
+	t.Parallel()
+
+	sizes := []GangSize{{Size: 2, Weight: 3}, {Size: 8}}
+	assert.NoError(t, ValidateGangSizes(sizes))
+	assert.Error(t, ValidateGangSizes(nil))
+	assert.Error(t, ValidateGangSizes([]GangSize{{Size: 0}}))
+	assert.Error(t, ValidateGangSizes([]GangSize{{Size: 2, Weight: -1}}))
+
+	rnd := util.NewRand(1)
+	counts := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		counts[DrawGangSize(sizes, rnd)]++
+	}
+	assert.InDelta(t, 750, counts[2], 60, "sizes must be drawn proportionally to their weights")
+	assert.InDelta(t, 250, counts[8], 60)
+}
+
+func TestNewFakeGangPod(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, GangStyleVolcano.Validate())
+	assert.Error(t, GangStyle("yunikorn").Validate())
+
+	podGroup := NewFakePodGroup(GangStyleVolcano, "fake-gang-a", "default", 4)
+	assert.Equal(t, "scheduling.volcano.sh/v1beta1", podGroup.GetAPIVersion())
+	assert.Equal(t, LabelValueFakePodGroup, podGroup.GetLabels()[LabelKeyApp])
+	minMember, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	assert.Equal(t, int64(4), minMember)
+
+	pod := NewFakeGangPod(GangStyleVolcano, podGroup, 1, 4, "volcano", false, util.NewRand(1))
+	assert.Equal(t, "fake-gang-a-1", pod.Name)
+	assert.Equal(t, "fake-gang-a", pod.Annotations["scheduling.k8s.io/group-name"])
+	assert.Equal(t, LabelValueFakeGang, pod.Labels[LabelKeyPartOf])
+	assert.Equal(t, "4", pod.Labels[LabelKeyGangSize])
+	assert.Equal(t, "volcano", pod.Spec.SchedulerName)
+	assert.Empty(t, pod.OwnerReferences, "pods are only owned by created PodGroups")
+
+	podGroup.SetUID(types.UID("uid"))
+	pod = NewFakeGangPod(GangStyleCoscheduling, podGroup, 0, 4, "scheduler-plugins-scheduler", false, util.NewRand(1))
+	assert.Equal(t, "fake-gang-a", pod.Labels["scheduling.x-k8s.io/pod-group"])
+	if assert.Len(t, pod.OwnerReferences, 1) {
+		assert.Equal(t, types.UID("uid"), pod.OwnerReferences[0].UID)
+	}
+}
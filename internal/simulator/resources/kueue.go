@@ -0,0 +1,102 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// LabelKeyQueueName is the label which submits a job to a Kueue LocalQueue.
+	LabelKeyQueueName = "kueue.x-k8s.io/queue-name"
+	// LabelValueFakeQueue marks the Kueue ResourceFlavors, ClusterQueues and LocalQueues created by the simulator.
+	LabelValueFakeQueue = "fake-queue"
+	// LabelSelectorFakeQueue selects the Kueue objects created by the simulator.
+	LabelSelectorFakeQueue = LabelKeyApp + "=" + LabelValueFakeQueue
+	// kueueAPIVersion is the API version of the Kueue objects.
+	kueueAPIVersion = "kueue.x-k8s.io/v1beta1"
+)
+
+var (
+	// ResourceFlavors is the resource of Kueue ResourceFlavors, they are cluster-scoped.
+	ResourceFlavors = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "resourceflavors"}
+	// ClusterQueues is the resource of Kueue ClusterQueues, they are cluster-scoped.
+	ClusterQueues = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "clusterqueues"}
+	// LocalQueues is the resource of Kueue LocalQueues, they are namespaced.
+	LocalQueues = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: "v1beta1", Resource: "localqueues"}
+)
+
+// NodePoolQuota returns the resources of a pool of fake nodes, which is the nominal quota of the Kueue ClusterQueue
+// of the pool.
+func NodePoolQuota(nodes int) corev1.ResourceList {
+	quota := make(corev1.ResourceList)
+	for name, quantity := range FakeNodeAllocatable() {
+		total := quantity.DeepCopy()
+		total.Mul(int64(nodes))
+		quota[name] = total
+	}
+	return quota
+}
+
+// NewFakeResourceFlavor creates a Kueue ResourceFlavor with the specified name which matches the fake nodes.
+func NewFakeResourceFlavor(name string) *unstructured.Unstructured {
+	flavor := newFakeQueueObject("ResourceFlavor", name, "")
+	flavor.Object["spec"] = map[string]any{
+		"nodeLabels": map[string]any{"type": "kwok"},
+	}
+	return flavor
+}
+
+// NewFakeClusterQueue creates a Kueue ClusterQueue with the specified name which admits the workloads of all namespaces
+// up to the nominal quota of the flavor. The quota covers the requested resources of fake pods.
+func NewFakeClusterQueue(name, flavor string, quota corev1.ResourceList) *unstructured.Unstructured {
+	coveredResources := make([]any, 0, len(quota))
+	resources := make([]any, 0, len(quota))
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourcePods} {
+		quantity, ok := quota[resourceName]
+		if !ok {
+			continue
+		}
+		coveredResources = append(coveredResources, string(resourceName))
+		resources = append(resources, map[string]any{
+			"name":         string(resourceName),
+			"nominalQuota": quantity.String(),
+		})
+	}
+	clusterQueue := newFakeQueueObject("ClusterQueue", name, "")
+	clusterQueue.Object["spec"] = map[string]any{
+		"namespaceSelector": map[string]any{},
+		"resourceGroups": []any{
+			map[string]any{
+				"coveredResources": coveredResources,
+				"flavors": []any{
+					map[string]any{"name": flavor, "resources": resources},
+				},
+			},
+		},
+	}
+	return clusterQueue
+}
+
+// NewFakeLocalQueue creates a Kueue LocalQueue with the specified name in the namespace which submits to the ClusterQueue.
+func NewFakeLocalQueue(name, namespace, clusterQueue string) *unstructured.Unstructured {
+	localQueue := newFakeQueueObject("LocalQueue", name, namespace)
+	localQueue.Object["spec"] = map[string]any{
+		"clusterQueue": clusterQueue,
+	}
+	return localQueue
+}
+
+// newFakeQueueObject creates a Kueue object of the kind labeled as a fake queue of the run.
+func newFakeQueueObject(kind, name, namespace string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]any{}}
+	object.SetAPIVersion(kueueAPIVersion)
+	object.SetKind(kind)
+	object.SetName(name)
+	object.SetNamespace(namespace)
+	object.SetLabels(withRunID(map[string]string{
+		LabelKeyApp:  LabelValueFakeQueue,
+		"created-by": getHostname(),
+	}))
+	return object
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+func TestNodePoolQuota(t *testing.T) {
+	t.Parallel()
+
+	quota := NodePoolQuota(10)
+	assert.True(t, resource.MustParse("200").Equal(quota["cpu"]))
+	assert.True(t, resource.MustParse("2560Gi").Equal(quota["memory"]))
+	assert.True(t, resource.MustParse("1100").Equal(quota["pods"]))
+	assert.True(t, resource.MustParse("20").Equal(FakeNodeAllocatable()["cpu"]), "the allocatable resources of a node are not modified")
+}
+
+func TestNewFakeClusterQueue(t *testing.T) {
+	t.Parallel()
+
+	clusterQueue := NewFakeClusterQueue("simulator", "kwok", NodePoolQuota(2))
+	assert.Equal(t, "kueue.x-k8s.io/v1beta1", clusterQueue.GetAPIVersion())
+	assert.Equal(t, "ClusterQueue", clusterQueue.GetKind())
+	assert.Empty(t, clusterQueue.GetNamespace())
+	assert.Equal(t, LabelValueFakeQueue, clusterQueue.GetLabels()[LabelKeyApp])
+
+	groups, found, err := unstructured.NestedSlice(clusterQueue.Object, "spec", "resourceGroups")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	if !assert.Len(t, groups, 1) {
+		t.FailNow()
+	}
+	group := groups[0].(map[string]any)
+	assert.Equal(t, []any{"cpu", "memory", "pods"}, group["coveredResources"])
+	flavor := group["flavors"].([]any)[0].(map[string]any)
+	assert.Equal(t, "kwok", flavor["name"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "cpu", "nominalQuota": "40"},
+		map[string]any{"name": "memory", "nominalQuota": "512Gi"},
+		map[string]any{"name": "pods", "nominalQuota": "220"},
+	}, flavor["resources"])
+}
+
+func TestNewFakeLocalQueue(t *testing.T) {
+	t.Parallel()
+
+	localQueue := NewFakeLocalQueue("simulator", "team-a", "cluster")
+	assert.Equal(t, "LocalQueue", localQueue.GetKind())
+	assert.Equal(t, "team-a", localQueue.GetNamespace())
+	clusterQueue, _, _ := unstructured.NestedString(localQueue.Object, "spec", "clusterQueue")
+	assert.Equal(t, "cluster", clusterQueue)
+
+	flavor := NewFakeResourceFlavor("kwok")
+	nodeLabels, _, _ := unstructured.NestedStringMap(flavor.Object, "spec", "nodeLabels")
+	assert.Equal(t, map[string]string{"type": "kwok"}, nodeLabels)
+}
@@ -2,13 +2,15 @@ package resources
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
+	"strings"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 
 	"github.com/dejanzele/batch-simulator/internal/util"
@@ -19,6 +21,25 @@ const (
 	LabelValueFakeJob    = "fake-job"
 	LabelValueFakePod    = "fake-pod"
 	LabelSelectorFakePod = LabelKeyApp + "=" + LabelValueFakePod
+	LabelSelectorFakeJob = LabelKeyApp + "=" + LabelValueFakeJob
+	// LabelValueFakeNamespace marks the namespaces which were generated to spread fake pods and jobs across.
+	LabelValueFakeNamespace = "fake-namespace"
+	// LabelSelectorFakeNamespace selects the generated namespaces.
+	LabelSelectorFakeNamespace = LabelKeyApp + "=" + LabelValueFakeNamespace
+	// LabelSelectorFakeNode selects fake nodes.
+	LabelSelectorFakeNode = "type=kwok"
+	// LabelKeyPartOf is the label which marks the pods of fake jobs.
+	LabelKeyPartOf = "part-of"
+	// LabelSelectorStandaloneFakePod selects fake pods which were not created by fake jobs.
+	LabelSelectorStandaloneFakePod = LabelSelectorFakePod + ",!" + LabelKeyPartOf
+	// LabelKeyJobClass is the label which groups fake jobs into classes for job-level metrics.
+	LabelKeyJobClass = "job-class"
+	// DefaultJobClass is the job class of fake jobs if none is configured.
+	DefaultJobClass = "default"
+	// LabelKeyRunID is the label which identifies the run which created a fake node, pod or job.
+	LabelKeyRunID = "run-id"
+	// fakePodCPURequest is the CPU request of every fake container.
+	fakePodCPURequest = "1"
 )
 
 var (
@@ -27,58 +48,131 @@ var (
 	// MaxEnvVarSize is the maximum size of an env var in bytes.
 	MaxEnvVarSize = 10 * 1024
 	// EnvVarsType is the type of env vars that should be used when creating fake pods (nano, micro, xsmall...).
-	EnvVarsType = newEnvVars(EnvVarCount, 2*1024, "SOME_ENV_VAR_MEDIUM")
+	EnvVarsType = GetEnvVars("medium")
+	// JobClass is the value of the job class label set on fake jobs and their pods.
+	JobClass = DefaultJobClass
+	// RunID is the value of the run ID label set on fake nodes, pods and jobs, the label is not set if it is empty.
+	RunID string
 )
 
+// NewRunID returns a new run ID made of the current UTC time and a random suffix, e.g. 20240102-150405-x7k2p.
+func NewRunID() string {
+	return time.Now().UTC().Format("20060102-150405") + "-" + util.UniqueRFC1123Name(5)
+}
+
+// NewSeededRunID returns a run ID drawn from a source forked from rnd, e.g. run-x7k2p9q4m1, so runs with the same seed
+// get the same run ID and label their objects identically. Runs with the same seed share the run ID, so they cannot be
+// cleaned up separately.
+func NewSeededRunID(rnd *util.Rand) string {
+	return "run-" + rnd.Fork("run-id").RFC1123Name(10)
+}
+
+// ValidateRunID returns an error if the run ID cannot be used as a label value.
+func ValidateRunID(runID string) error {
+	if errs := validation.IsValidLabelValue(runID); len(errs) > 0 {
+		return fmt.Errorf("invalid run ID %q: %s", runID, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// RunSelector narrows the label selector down to the objects of the run, it returns the selector as is if runID is empty.
+func RunSelector(selector, runID string) string {
+	if runID == "" {
+		return selector
+	}
+	if selector == "" {
+		return LabelKeyRunID + "=" + runID
+	}
+	return selector + "," + LabelKeyRunID + "=" + runID
+}
+
+// withRunID sets the run ID label, if a run ID is configured, and returns the labels.
+func withRunID(labels map[string]string) map[string]string {
+	if RunID != "" {
+		labels[LabelKeyRunID] = RunID
+	}
+	return labels
+}
+
 func SetDefaultEnvVarsType(envVarType string) {
 	EnvVarsType = GetEnvVars(envVarType)
 }
 
+// GetEnvVars creates the env vars of the env var type, their values are generated from a source forked from util.Default(),
+// so they are the same on every run with the same seed.
 func GetEnvVars(envVarType string) []corev1.EnvVar {
+	rnd := util.Default().Fork("env-vars")
 	switch envVarType {
 	case "nano":
-		return newEnvVars(EnvVarCount, 100, "SOME_ENV_VAR_NANO")
+		return newEnvVars(rnd, EnvVarCount, 100, "SOME_ENV_VAR_NANO")
 	case "micro":
-		return newEnvVars(EnvVarCount, 200, "SOME_ENV_VAR_MICRO")
+		return newEnvVars(rnd, EnvVarCount, 200, "SOME_ENV_VAR_MICRO")
 	case "xsmall":
-		return newEnvVars(EnvVarCount, 500, "SOME_ENV_VAR_XSMALL")
+		return newEnvVars(rnd, EnvVarCount, 500, "SOME_ENV_VAR_XSMALL")
 	case "small":
-		return newEnvVars(EnvVarCount, 1024, "SOME_ENV_VAR_SMALL")
+		return newEnvVars(rnd, EnvVarCount, 1024, "SOME_ENV_VAR_SMALL")
 	case "medium":
-		return newEnvVars(EnvVarCount, 2*1024, "SOME_ENV_VAR_MEDIUM")
+		return newEnvVars(rnd, EnvVarCount, 2*1024, "SOME_ENV_VAR_MEDIUM")
 	case "large":
-		return newEnvVars(EnvVarCount, 4*1024, "SOME_ENV_VAR_LARGE")
+		return newEnvVars(rnd, EnvVarCount, 4*1024, "SOME_ENV_VAR_LARGE")
 	case "xlarge":
-		return newEnvVars(EnvVarCount, 8*1024, "SOME_ENV_VAR_XLARGE")
+		return newEnvVars(rnd, EnvVarCount, 8*1024, "SOME_ENV_VAR_XLARGE")
 	case "xlarge2":
-		return newEnvVars(EnvVarCount, 10*1024, "SOME_ENV_VAR_XLARGE2")
+		return newEnvVars(rnd, EnvVarCount, 10*1024, "SOME_ENV_VAR_XLARGE2")
 	case "xlarge8":
-		return newEnvVars(EnvVarCount, 40*1024, "SOME_ENV_VAR_XLARGE8")
+		return newEnvVars(rnd, EnvVarCount, 40*1024, "SOME_ENV_VAR_XLARGE8")
 	default:
-		return newEnvVars(EnvVarCount, 2*1024, "SOME_ENV_VAR_MEDIUM")
+		return newEnvVars(rnd, EnvVarCount, 2*1024, "SOME_ENV_VAR_MEDIUM")
 	}
 }
 
-func GetRandomEnvVarType() []corev1.EnvVar {
-	size := 1 + rand.Intn(MaxEnvVarSize)
-	return newEnvVars(EnvVarCount, size, "SOME_ENV_VAR_RANDOM")
+// GetRandomEnvVarType creates env vars of a random size up to MaxEnvVarSize.
+func GetRandomEnvVarType(rnd *util.Rand) []corev1.EnvVar {
+	size := 1 + rnd.Intn(MaxEnvVarSize)
+	return newEnvVars(rnd, EnvVarCount, size, "SOME_ENV_VAR_RANDOM")
 }
 
 // newEnvVars creates a slice of envvars with the specified count and size.
-func newEnvVars(count, size int, prefix string) []corev1.EnvVar {
+func newEnvVars(rnd *util.Rand, count, size int, prefix string) []corev1.EnvVar {
 	envVars := make([]corev1.EnvVar, 0, count)
 	for i := 0; i < count; i++ {
-		envVars = append(envVars, newEnvVar(fmt.Sprintf("%s_%d", prefix, i), size))
+		envVars = append(envVars, newEnvVar(rnd, fmt.Sprintf("%s_%d", prefix, i), size))
 
 	}
 	return envVars
 }
 
 // newEnvVar creates a new envvar with the specified name and size.
-func newEnvVar(name string, size int) corev1.EnvVar {
+func newEnvVar(rnd *util.Rand, name string, size int) corev1.EnvVar {
 	return corev1.EnvVar{
 		Name:  name,
-		Value: util.RandomText(size),
+		Value: rnd.Text(size),
+	}
+}
+
+// NewFakeNamespace creates a Kubernetes Namespace resource which fake pods and jobs are spread across.
+func NewFakeNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: withRunID(map[string]string{
+				LabelKeyApp:  LabelValueFakeNamespace,
+				"created-by": getHostname(),
+			}),
+		},
+	}
+}
+
+// FakeNodeAllocatable returns the allocatable resources of a fake node.
+func FakeNodeAllocatable() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("20"),
+		corev1.ResourceMemory: resource.MustParse("256Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
 	}
 }
 
@@ -95,7 +189,7 @@ func NewFakeNode(nodeName string) *corev1.Node {
 				"node.alpha.kubernetes.io/ttl": "0",
 				"kwok.x-k8s.io/node":           "fake",
 			},
-			Labels: map[string]string{
+			Labels: withRunID(map[string]string{
 				"beta.kubernetes.io/arch":       "amd64",
 				"beta.kubernetes.io/os":         "linux",
 				"kubernetes.io/arch":            "amd64",
@@ -104,7 +198,7 @@ func NewFakeNode(nodeName string) *corev1.Node {
 				"kubernetes.io/role":            "agent",
 				"node-role.kubernetes.io/agent": "",
 				"type":                          "kwok",
-			},
+			}),
 		},
 		Spec: corev1.NodeSpec{
 			PodCIDR:  "10.233.1.0/24",
@@ -118,16 +212,8 @@ func NewFakeNode(nodeName string) *corev1.Node {
 			},
 		},
 		Status: corev1.NodeStatus{
-			Allocatable: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("20"),
-				corev1.ResourceMemory: resource.MustParse("256Gi"),
-				corev1.ResourcePods:   resource.MustParse("110"),
-			},
-			Capacity: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("20"),
-				corev1.ResourceMemory: resource.MustParse("256Gi"),
-				corev1.ResourcePods:   resource.MustParse("110"),
-			},
+			Allocatable: FakeNodeAllocatable(),
+			Capacity:    FakeNodeAllocatable(),
 			NodeInfo: corev1.NodeSystemInfo{
 				Architecture:            "amd64",
 				BootID:                  "",
@@ -146,7 +232,8 @@ func NewFakeNode(nodeName string) *corev1.Node {
 }
 
 // NewFakeJob creates a fake Kubernetes Job resource, managed by KWOK, with the specified name and namespace.
-func NewFakeJob(name, namespace string, randomEnvVars bool) *batchv1.Job {
+// Random env vars are generated from rnd.
+func NewFakeJob(name, namespace string, randomEnvVars bool, rnd *util.Rand) *batchv1.Job {
 	return &batchv1.Job{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -155,30 +242,33 @@ func NewFakeJob(name, namespace string, randomEnvVars bool) *batchv1.Job {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels: map[string]string{
-				LabelKeyApp:  LabelValueFakeJob,
-				"type":       "kwok",
-				"created-by": getHostname(),
-			},
+			Labels: withRunID(map[string]string{
+				LabelKeyApp:      LabelValueFakeJob,
+				LabelKeyJobClass: JobClass,
+				"type":           "kwok",
+				"created-by":     getHostname(),
+			}),
 		},
 		Spec: batchv1.JobSpec{
 			TTLSecondsAfterFinished: ptr.To[int32](30),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						LabelKeyApp:  LabelValueFakePod,
-						"part-of":    LabelValueFakeJob,
-						"created-by": getHostname(),
-					},
+					Labels: withRunID(map[string]string{
+						LabelKeyApp:      LabelValueFakePod,
+						LabelKeyJobClass: JobClass,
+						LabelKeyPartOf:   LabelValueFakeJob,
+						"created-by":     getHostname(),
+					}),
 				},
-				Spec: newPodSpec(randomEnvVars),
+				Spec: newPodSpec(randomEnvVars, rnd),
 			},
 		},
 	}
 }
 
 // NewFakePod creates a fake Kubernetes Pod resource, managed by KWOK, with the specified name and namespace.
-func NewFakePod(name, namespace string, randomEnvVars bool) *corev1.Pod {
+// Random env vars are generated from rnd.
+func NewFakePod(name, namespace string, randomEnvVars bool, rnd *util.Rand) *corev1.Pod {
 	return &corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -187,22 +277,22 @@ func NewFakePod(name, namespace string, randomEnvVars bool) *corev1.Pod {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels: map[string]string{
+			Labels: withRunID(map[string]string{
 				LabelKeyApp:  LabelValueFakePod,
 				"type":       "kwok",
 				"created-by": getHostname(),
-			},
+			}),
 		},
-		Spec: newPodSpec(randomEnvVars),
+		Spec: newPodSpec(randomEnvVars, rnd),
 	}
 }
 
 // newPodSpec creates a new pod spec.
 // If randomEnvVars is true, a random envvar slice will be used, otherwise the default (large) envvar slice will be used.
-func newPodSpec(randomEnvVars bool) corev1.PodSpec {
+func newPodSpec(randomEnvVars bool, rnd *util.Rand) corev1.PodSpec {
 	envVars := EnvVarsType
 	if randomEnvVars {
-		envVars = GetRandomEnvVarType()
+		envVars = GetRandomEnvVarType(rnd)
 	}
 	podSpec := corev1.PodSpec{
 		RestartPolicy: corev1.RestartPolicyNever,
@@ -221,7 +311,7 @@ func newPodSpec(randomEnvVars bool) corev1.PodSpec {
 				Env:   envVars,
 				Resources: corev1.ResourceRequirements{
 					Requests: corev1.ResourceList{
-						corev1.ResourceCPU: resource.MustParse("1"),
+						corev1.ResourceCPU: FakePodCPURequest(),
 					},
 				},
 			},
@@ -230,6 +320,11 @@ func newPodSpec(randomEnvVars bool) corev1.PodSpec {
 	return podSpec
 }
 
+// FakePodCPURequest returns the CPU request of every fake container.
+func FakePodCPURequest() resource.Quantity {
+	return resource.MustParse(fakePodCPURequest)
+}
+
 // newAffinity creates a new affinity which matches nodes with the type kwok.
 func newAffinity() *corev1.Affinity {
 	return &corev1.Affinity{
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRunID(t *testing.T) {
+	RunID = "20240102-150405-abcde"
+	defer func() { RunID = "" }()
+
+	node := NewFakeNode("node")
+	pod := NewFakePod("pod", "default", false, util.Default())
+	job := NewFakeJob("job", "default", false, util.Default())
+	assert.Equal(t, RunID, node.Labels[LabelKeyRunID])
+	assert.Equal(t, RunID, pod.Labels[LabelKeyRunID])
+	assert.Equal(t, RunID, job.Labels[LabelKeyRunID])
+	assert.Equal(t, RunID, job.Spec.Template.Labels[LabelKeyRunID], "pods of fake jobs must carry the run ID")
+
+	RunID = ""
+	assert.NotContains(t, NewFakePod("pod", "default", false, util.Default()).Labels, LabelKeyRunID)
+}
+
+func TestNewRunID(t *testing.T) {
+	t.Parallel()
+
+	runID := NewRunID()
+	assert.Regexp(t, `^\d{8}-\d{6}-[a-z0-9]{5}$`, runID)
+	assert.NoError(t, ValidateRunID(runID))
+	assert.NotEqual(t, runID, NewRunID())
+}
+
+func TestNewSeededRunID(t *testing.T) {
+	t.Parallel()
+
+	runID := NewSeededRunID(util.NewRand(42))
+	assert.Regexp(t, `^run-[a-z0-9]{10}$`, runID)
+	assert.NoError(t, ValidateRunID(runID))
+	assert.Equal(t, runID, NewSeededRunID(util.NewRand(42)))
+	assert.NotEqual(t, runID, NewSeededRunID(util.NewRand(43)))
+}
+
+func TestValidateRunID(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidateRunID("nightly-1"))
+	assert.Error(t, ValidateRunID("nightly 1"))
+	assert.Error(t, ValidateRunID("-nightly"))
+}
+
+func TestRunSelector(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "type=kwok", RunSelector("type=kwok", ""))
+	assert.Equal(t, "type=kwok,run-id=abc", RunSelector("type=kwok", "abc"))
+	assert.Equal(t, "run-id=abc", RunSelector("", "abc"))
+}
@@ -0,0 +1,136 @@
+package resources
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+// Template customizes the fake pods and jobs of a workload, e.g. the jobs of a tenant.
+type Template struct {
+	// JobClass is the job class label of the jobs and their pods, JobClass is used if empty.
+	JobClass string `json:"jobClass,omitempty"`
+	// Parallelism is the number of pods of a job which run in parallel, 1 if 0.
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// Completions is the number of pods of a job which must succeed, 1 if 0.
+	Completions int32 `json:"completions,omitempty"`
+	// Requests is the distribution of the container resource requests, every pod spec draws one of them
+	// proportionally to its weight. The default CPU request is used if it is empty.
+	Requests []WeightedRequests `json:"requests,omitempty"`
+	// Labels are added to the pods and jobs, e.g. to route them to a scheduler queue.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Suspend creates suspended jobs, which are unsuspended once a queueing system such as Kueue admits them.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// WeightedRequests are container resource requests which are drawn proportionally to their weight.
+type WeightedRequests struct {
+	// Requests are the resource requests of the container, e.g. cpu: 500m and memory: 1Gi.
+	Requests corev1.ResourceList `json:"requests"`
+	// Weight is the relative frequency of the requests, 1 if 0.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// Validate returns an error if the template would generate invalid pods or jobs.
+func (t *Template) Validate() error {
+	if t.JobClass != "" {
+		if errs := validation.IsValidLabelValue(t.JobClass); len(errs) > 0 {
+			return fmt.Errorf("invalid job class %q: %v", t.JobClass, errs)
+		}
+	}
+	if t.Parallelism < 0 || t.Completions < 0 {
+		return fmt.Errorf("parallelism and completions must not be negative")
+	}
+	for i, r := range t.Requests {
+		if len(r.Requests) == 0 {
+			return fmt.Errorf("requests %d are empty", i)
+		}
+		if r.Weight < 0 {
+			return fmt.Errorf("weight of requests %d must not be negative, got %v", i, r.Weight)
+		}
+	}
+	for key, value := range t.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %v", key, errs)
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value of label %s: %v", key, errs)
+		}
+	}
+	return nil
+}
+
+// ApplyToJob sets the job class, parallelism, completions, suspension and labels of the job and draws the requests of its pods from rnd.
+func (t *Template) ApplyToJob(job *batchv1.Job, rnd *util.Rand) {
+	if t.JobClass != "" {
+		job.Labels[LabelKeyJobClass] = t.JobClass
+		job.Spec.Template.Labels[LabelKeyJobClass] = t.JobClass
+	}
+	if t.Parallelism > 0 {
+		job.Spec.Parallelism = ptr.To(t.Parallelism)
+	}
+	if t.Completions > 0 {
+		job.Spec.Completions = ptr.To(t.Completions)
+	}
+	if t.Suspend {
+		job.Spec.Suspend = ptr.To(true)
+	}
+	addLabels(job.Labels, t.Labels)
+	addLabels(job.Spec.Template.Labels, t.Labels)
+	t.applyToPodSpec(&job.Spec.Template.Spec, rnd)
+}
+
+// ApplyToPod sets the labels of the pod and draws its requests from rnd.
+func (t *Template) ApplyToPod(pod *corev1.Pod, rnd *util.Rand) {
+	addLabels(pod.Labels, t.Labels)
+	t.applyToPodSpec(&pod.Spec, rnd)
+}
+
+// applyToPodSpec draws the requests of the containers from rnd.
+func (t *Template) applyToPodSpec(spec *corev1.PodSpec, rnd *util.Rand) {
+	if len(t.Requests) == 0 {
+		return
+	}
+	requests := t.drawRequests(rnd)
+	for i := range spec.Containers {
+		spec.Containers[i].Resources.Requests = requests.DeepCopy()
+	}
+}
+
+// drawRequests draws one of the requests proportionally to their weights.
+func (t *Template) drawRequests(rnd *util.Rand) corev1.ResourceList {
+	total := 0.0
+	for _, r := range t.Requests {
+		total += weight(r.Weight)
+	}
+	target := rnd.Float64() * total
+	for _, r := range t.Requests {
+		target -= weight(r.Weight)
+		if target < 0 {
+			return r.Requests
+		}
+	}
+	return t.Requests[len(t.Requests)-1].Requests
+}
+
+// weight returns the weight of requests, 1 if it is not set.
+func weight(w float64) float64 {
+	if w == 0 {
+		return 1
+	}
+	return w
+}
+
+// addLabels adds the labels without overriding the labels which identify fake objects.
+func addLabels(labels, extra map[string]string) {
+	for key, value := range extra {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
+		}
+	}
+}
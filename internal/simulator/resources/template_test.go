@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"testing"
+)
+
+func TestTemplate_ApplyToJob(t *testing.T) {
+	t.Parallel()
+
+	small := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	large := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}
+	template := &Template{
+		JobClass:    "team-a",
+		Parallelism: 2,
+		Completions: 4,
+		Requests:    []WeightedRequests{{Requests: small, Weight: 3}, {Requests: large}},
+		Labels:      map[string]string{"tenant": "team-a", LabelKeyApp: "overridden"},
+	}
+	assert.NoError(t, template.Validate())
+
+	rnd := util.NewRand(1)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		job := NewFakeJob("job", "team-a", false, rnd)
+		template.ApplyToJob(job, rnd)
+		assert.Equal(t, "team-a", job.Labels[LabelKeyJobClass])
+		assert.Equal(t, "team-a", job.Spec.Template.Labels[LabelKeyJobClass])
+		assert.Equal(t, "team-a", job.Spec.Template.Labels["tenant"])
+		assert.Equal(t, LabelValueFakeJob, job.Labels[LabelKeyApp], "labels identifying fake objects must not be overridden")
+		assert.Equal(t, int32(2), *job.Spec.Parallelism)
+		assert.Equal(t, int32(4), *job.Spec.Completions)
+		assert.Nil(t, job.Spec.Suspend)
+		cpu := job.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+		counts[cpu.String()]++
+	}
+	assert.InDelta(t, 750, counts["500m"], 60, "requests must be drawn proportionally to their weights")
+	assert.InDelta(t, 250, counts["4"], 60)
+
+	job := NewFakeJob("job", "default", false, rnd)
+	(&Template{Suspend: true}).ApplyToJob(job, rnd)
+	assert.True(t, *job.Spec.Suspend, "suspended jobs are admitted by a queue")
+
+	pod := NewFakePod("pod", "default", false, rnd)
+	(&Template{}).ApplyToPod(pod, rnd)
+	assert.Equal(t, FakePodCPURequest(), pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU], "the default requests must be kept")
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	t.Parallel()
+
+	for name, template := range map[string]Template{
+		"invalid job class":    {JobClass: "not a label"},
+		"negative parallelism": {Parallelism: -1},
+		"empty requests":       {Requests: []WeightedRequests{{Weight: 1}}},
+		"negative weight":      {Requests: []WeightedRequests{{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}, Weight: -1}}},
+		"invalid label key":    {Labels: map[string]string{"a b": "c"}},
+		"invalid label value":  {Labels: map[string]string{"a": "b c"}},
+	} {
+		assert.Error(t, template.Validate(), name)
+	}
+}
@@ -0,0 +1,70 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// LabelValueFakeObject marks the objects of arbitrary kinds which were created from a template.
+	LabelValueFakeObject = "fake-object"
+	// LabelSelectorFakeObject selects the objects created from a template.
+	LabelSelectorFakeObject = LabelKeyApp + "=" + LabelValueFakeObject
+)
+
+// LoadObjectTemplate reads the template of created objects of an arbitrary kind from a YAML or JSON file.
+// The template must set apiVersion and kind, its name, generateName, namespace and resourceVersion are ignored.
+func LoadObjectTemplate(path string) (*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object template %s: %w", path, err)
+	}
+	return ParseObjectTemplate(data)
+}
+
+// ParseObjectTemplate parses the template of created objects of an arbitrary kind from YAML or JSON.
+func ParseObjectTemplate(data []byte) (*unstructured.Unstructured, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse object template: %w", err)
+	}
+	template := &unstructured.Unstructured{}
+	if err := template.UnmarshalJSON(jsonData); err != nil {
+		return nil, fmt.Errorf("failed to parse object template: %w", err)
+	}
+	if template.GetAPIVersion() == "" || template.GetKind() == "" {
+		return nil, fmt.Errorf("object template must set apiVersion and kind")
+	}
+	return template, nil
+}
+
+// GuessResource returns the resource of the template's kind by lowercasing and pluralizing the kind,
+// e.g. batch.volcano.sh/v1alpha1 Job becomes jobs.v1alpha1.batch.volcano.sh, irregular plurals must be configured explicitly.
+func GuessResource(template *unstructured.Unstructured) schema.GroupVersionResource {
+	resource, _ := meta.UnsafeGuessKindToResource(template.GroupVersionKind())
+	return resource
+}
+
+// NewFakeObject creates an object from the template with the specified name and namespace, the namespace is not set if it is empty.
+// The labels of the template are kept and the object is labeled as a fake object of the run.
+func NewFakeObject(template *unstructured.Unstructured, name, namespace string) *unstructured.Unstructured {
+	object := template.DeepCopy()
+	object.SetName(name)
+	object.SetGenerateName("")
+	object.SetNamespace(namespace)
+	object.SetResourceVersion("")
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelKeyApp] = LabelValueFakeObject
+	labels["type"] = "kwok"
+	labels["created-by"] = getHostname()
+	object.SetLabels(withRunID(labels))
+	return object
+}
@@ -0,0 +1,51 @@
+package resources
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+const podGroupTemplate = `
+apiVersion: scheduling.x-k8s.io/v1alpha1
+kind: PodGroup
+metadata:
+  name: ignored
+  namespace: ignored
+  labels:
+    team: a
+spec:
+  minMember: 4
+`
+
+func TestParseObjectTemplate(t *testing.T) {
+	t.Parallel()
+
+	template, err := ParseObjectTemplate([]byte(podGroupTemplate))
+	assert.NoError(t, err)
+	assert.Equal(t, "PodGroup", template.GetKind())
+	assert.Equal(t, schema.GroupVersionResource{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Resource: "podgroups"}, GuessResource(template))
+
+	_, err = ParseObjectTemplate([]byte("metadata:\n  name: a\n"))
+	assert.Error(t, err, "apiVersion and kind are required")
+	_, err = ParseObjectTemplate([]byte("kind: [a"))
+	assert.Error(t, err)
+}
+
+func TestNewFakeObject(t *testing.T) {
+	t.Parallel()
+
+	template, err := ParseObjectTemplate([]byte(podGroupTemplate))
+	assert.NoError(t, err)
+	object := NewFakeObject(template, "fake-podgroup-1", "tenant-1")
+	assert.Equal(t, "fake-podgroup-1", object.GetName())
+	assert.Equal(t, "tenant-1", object.GetNamespace())
+	assert.Equal(t, "a", object.GetLabels()["team"], "labels of the template must be kept")
+	assert.Equal(t, LabelValueFakeObject, object.GetLabels()[LabelKeyApp])
+	assert.Equal(t, "ignored", template.GetName(), "the template must not be modified")
+	assert.NotContains(t, template.GetLabels(), LabelKeyApp)
+
+	spec, ok := object.Object["spec"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), spec["minMember"])
+}
@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary describes the distribution of a set of values.
+// Values summarized from durations are expressed in seconds.
+type Summary struct {
+	// Count is the number of values.
+	Count int `json:"count"`
+	// Min is the smallest value.
+	Min float64 `json:"min"`
+	// Max is the largest value.
+	Max float64 `json:"max"`
+	// Mean is the arithmetic mean of all values.
+	Mean float64 `json:"mean"`
+	// P50 is the 50th percentile (median).
+	P50 float64 `json:"p50"`
+	// P90 is the 90th percentile.
+	P90 float64 `json:"p90"`
+	// P95 is the 95th percentile.
+	P95 float64 `json:"p95"`
+	// P99 is the 99th percentile.
+	P99 float64 `json:"p99"`
+}
+
+// Summarize returns the Summary of the provided values.
+// The values are not modified. An empty Summary is returned if there are no values.
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Summary{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / float64(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+	}
+}
+
+// SummarizeDurations returns the Summary of the provided durations expressed in seconds.
+func SummarizeDurations(durations []time.Duration) Summary {
+	values := make([]float64, 0, len(durations))
+	for _, d := range durations {
+		values = append(values, d.Seconds())
+	}
+	return Summarize(values)
+}
+
+// Percentile returns the p-th percentile of the values using the nearest-rank method.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return percentile(sorted, p)
+}
+
+// percentile returns the p-th percentile of already sorted values using the nearest-rank method.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// DefaultLatencyBounds are the upper bounds of latency histogram buckets in seconds, from 10ms to ~80s.
+var DefaultLatencyBounds = []float64{0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92}
+
+// Histogram counts values in buckets.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets in ascending order.
+	Bounds []float64 `json:"bounds"`
+	// Counts holds the number of values in each bucket, the last count holds values greater than the last bound.
+	Counts []int `json:"counts"`
+}
+
+// NewHistogram counts the values in buckets with the provided upper bounds, which must be sorted in ascending order.
+func NewHistogram(values, bounds []float64) Histogram {
+	h := Histogram{Bounds: bounds, Counts: make([]int, len(bounds)+1)}
+	for _, v := range values {
+		h.Counts[sort.SearchFloat64s(bounds, v)]++
+	}
+	return h
+}
+
+// NewDurationHistogram counts the durations, expressed in seconds, in buckets with DefaultLatencyBounds.
+func NewDurationHistogram(durations []time.Duration) Histogram {
+	values := make([]float64, 0, len(durations))
+	for _, d := range durations {
+		values = append(values, d.Seconds())
+	}
+	return NewHistogram(values, DefaultLatencyBounds)
+}
@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty values", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, Summary{}, Summarize(nil))
+	})
+
+	t.Run("computes distribution", func(t *testing.T) {
+		t.Parallel()
+
+		values := make([]float64, 0, 100)
+		for i := 100; i > 0; i-- {
+			values = append(values, float64(i))
+		}
+		summary := Summarize(values)
+		assert.Equal(t, 100, summary.Count)
+		assert.Equal(t, 1.0, summary.Min)
+		assert.Equal(t, 100.0, summary.Max)
+		assert.Equal(t, 50.5, summary.Mean)
+		assert.Equal(t, 50.0, summary.P50)
+		assert.Equal(t, 90.0, summary.P90)
+		assert.Equal(t, 95.0, summary.P95)
+		assert.Equal(t, 99.0, summary.P99)
+		assert.Equal(t, 100.0, values[0], "input values must not be sorted in place")
+	})
+}
+
+func TestSummarizeDurations(t *testing.T) {
+	t.Parallel()
+
+	summary := SummarizeDurations([]time.Duration{500 * time.Millisecond, 1 * time.Second, 2 * time.Second})
+	assert.Equal(t, 3, summary.Count)
+	assert.Equal(t, 0.5, summary.Min)
+	assert.Equal(t, 1.0, summary.P50)
+	assert.Equal(t, 2.0, summary.P99)
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0.0, Percentile(nil, 99))
+	assert.Equal(t, 3.0, Percentile([]float64{3, 1, 2}, 100))
+	assert.Equal(t, 1.0, Percentile([]float64{3, 1, 2}, 0))
+}
+
+func TestNewHistogram(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistogram([]float64{0.5, 1, 1.5, 2, 10}, []float64{1, 2})
+	assert.Equal(t, []float64{1, 2}, h.Bounds)
+	assert.Equal(t, []int{2, 2, 1}, h.Counts)
+
+	h = NewDurationHistogram([]time.Duration{5 * time.Millisecond, 100 * time.Millisecond, 2 * time.Minute})
+	assert.Len(t, h.Counts, len(DefaultLatencyBounds)+1)
+	assert.Equal(t, 1, h.Counts[0])
+	assert.Equal(t, 1, h.Counts[4])
+	assert.Equal(t, 1, h.Counts[len(DefaultLatencyBounds)])
+}
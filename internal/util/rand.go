@@ -1,39 +1,116 @@
 package util
 
 import (
+	"hash/fnv"
 	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-func RandomRFC1123Name(chars int) string {
-	// Define the character set for the random text.
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+const (
+	// rfc1123Charset is the character set of names which are valid Kubernetes object names.
+	rfc1123Charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// textCharset is the character set of random text.
+	textCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var (
+	// defaultRand is the source used by the package level functions, it is randomly seeded unless Seed is called.
+	defaultRand atomic.Pointer[Rand]
+	// uniqueRand is the source of names which must differ between runs with the same seed, it is never seeded.
+	uniqueRand = NewRand(time.Now().UnixNano())
+)
 
-	return random(chars, charset)
+func init() {
+	defaultRand.Store(NewRand(time.Now().UnixNano()))
 }
 
-// RandomText generates random text of the specified length.
-func RandomText(chars int) string {
-	// Define the character set for the random text.
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// Seed replaces the default source with a source seeded with seed.
+// It must be called before any randomness is consumed, so every value generated afterward is reproducible.
+func Seed(seed int64) {
+	defaultRand.Store(NewRand(seed))
+}
+
+// Default returns the default source.
+func Default() *Rand {
+	return defaultRand.Load()
+}
+
+// Rand is a seeded source of randomness which is safe for concurrent use.
+type Rand struct {
+	seed  int64
+	rand  *rand.Rand
+	mutex sync.Mutex
+}
+
+// NewRand creates a source seeded with seed, sources with the same seed generate the same values.
+func NewRand(seed int64) *Rand {
+	return &Rand{seed: seed, rand: rand.New(rand.NewSource(seed))} //nolint:gosec // not used for security
+}
+
+// Fork returns a new source seeded with the seed of this source and the name, without consuming values from this source.
+// Consumers which run concurrently fork their own source, so each of them generates the same values on every run
+// regardless of how they are interleaved.
+func (r *Rand) Fork(name string) *Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return NewRand(r.seed ^ int64(h.Sum64()))
+}
 
-	return random(chars, charset)
+// Intn returns a random number in [0,n).
+func (r *Rand) Intn(n int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Intn(n)
 }
 
-func random(chars int, charset string) string {
-	// Seed the random number generator.
-	rand.New(rand.NewSource(time.Now().UnixNano()))
+// Float64 returns a random number in [0.0,1.0).
+func (r *Rand) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Float64()
+}
+
+// RFC1123Name generates a random name of the specified length which is a valid Kubernetes object name.
+func (r *Rand) RFC1123Name(chars int) string {
+	return r.random(chars, rfc1123Charset)
+}
+
+// Text generates random text of the specified length.
+func (r *Rand) Text(chars int) string {
+	return r.random(chars, textCharset)
+}
+
+func (r *Rand) random(chars int, charset string) string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
 	// Use a strings.Builder for efficient string concatenation.
 	var sb strings.Builder
-	length := chars
-	sb.Grow(length)
+	sb.Grow(chars)
 
 	// Generate a random string of the given length.
-	for i := 0; i < length; i++ {
-		sb.WriteByte(charset[rand.Intn(len(charset))])
+	for i := 0; i < chars; i++ {
+		sb.WriteByte(charset[r.rand.Intn(len(charset))])
 	}
 
 	return sb.String()
 }
+
+// RandomRFC1123Name generates a random name of the specified length from the default source.
+func RandomRFC1123Name(chars int) string {
+	return Default().RFC1123Name(chars)
+}
+
+// UniqueRFC1123Name generates a random name of the specified length which is not affected by Seed,
+// for names which must not collide between runs with the same seed (e.g. run IDs).
+func UniqueRFC1123Name(chars int) string {
+	return uniqueRand.RFC1123Name(chars)
+}
+
+// RandomText generates random text of the specified length from the default source.
+func RandomText(chars int) string {
+	return Default().Text(chars)
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestRand(t *testing.T) {
+	t.Parallel()
+
+	a, b := NewRand(42), NewRand(42)
+	assert.Equal(t, a.RFC1123Name(16), b.RFC1123Name(16))
+	assert.Equal(t, a.Text(64), b.Text(64))
+	assert.Equal(t, a.Intn(1000), b.Intn(1000))
+	assert.Equal(t, a.Float64(), b.Float64())
+
+	assert.NotEqual(t, NewRand(42).Text(64), NewRand(43).Text(64))
+	assert.Regexp(t, `^[a-z0-9]{16}$`, a.RFC1123Name(16))
+}
+
+func TestRand_Fork(t *testing.T) {
+	t.Parallel()
+
+	parent := NewRand(42)
+	pods := parent.Fork("pods")
+	assert.Equal(t, NewRand(42).Text(16), parent.Text(16), "forking must not consume values of the parent")
+	assert.Equal(t, NewRand(42).Fork("pods").Text(16), pods.Text(16))
+	assert.NotEqual(t, NewRand(42).Fork("pods").Text(16), NewRand(42).Fork("jobs").Text(16))
+}
+
+func TestRand_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	rnd := NewRand(42)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = rnd.RFC1123Name(8)
+				_ = rnd.Intn(10)
+			}
+		}()
+	}
+	wg.Wait()
+}
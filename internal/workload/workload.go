@@ -0,0 +1,261 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+)
+
+const (
+	// KindJob tenants create fake jobs.
+	KindJob = "job"
+	// KindPod tenants create fake pods.
+	KindPod = "pod"
+	// ArrivalConstant creates the configured number of requests in every interval.
+	ArrivalConstant = "constant"
+	// ArrivalPoisson draws the number of requests of every interval from a Poisson distribution
+	// whose mean is the configured number of requests.
+	ArrivalPoisson = "poisson"
+	// IdentifierPrefix is the prefix of the executor identifiers of tenants, the tenant name follows it.
+	IdentifierPrefix = "workload-"
+	// defaultFrequency is the interval of tenants which do not configure one.
+	defaultFrequency = 1 * time.Second
+	// defaultZipfExponent is the exponent of the zipf distribution of tenants which do not configure one.
+	defaultZipfExponent = 1.0
+)
+
+// Config holds the tenants of a simulation, it is loaded from a YAML or JSON file.
+type Config struct {
+	// Tenants run concurrently, each with its own namespaces, template, arrival process and rate.
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Tenant is a workload which creates fake jobs or pods in its own namespaces at its own rate.
+type Tenant struct {
+	// Name identifies the tenant in the metrics, it must be a valid DNS label.
+	Name string `json:"name"`
+	// Kind is the kind of created objects, job (default) or pod.
+	Kind string `json:"kind,omitempty"`
+	// Namespaces are the namespaces across which the objects are spread, a namespace named after the tenant if empty.
+	// Namespaces which do not exist are created on demand.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Distribution is the distribution of objects across the namespaces, round-robin if empty.
+	Distribution executor.Distribution `json:"distribution,omitempty"`
+	// Weights are the weights of the namespaces of the weighted distribution.
+	Weights []float64 `json:"weights,omitempty"`
+	// ZipfExponent is the exponent of the zipf distribution, 1 if 0.
+	ZipfExponent float64 `json:"zipfExponent,omitempty"`
+	// Frequency is the interval at which objects are created, 1s if empty.
+	Frequency metav1.Duration `json:"frequency,omitempty"`
+	// Requests is the number of objects created per interval, it is the mean of the Poisson arrival process.
+	Requests int `json:"requests"`
+	// Limit is the maximum number of objects the tenant creates, it is required.
+	Limit int `json:"limit"`
+	// Arrival is the arrival process of the objects, constant (default) or poisson.
+	Arrival string `json:"arrival,omitempty"`
+	// Template customizes the created objects, the job class defaults to the tenant name.
+	Template resources.Template `json:"template,omitempty"`
+}
+
+// Load reads the tenants from the YAML or JSON file, sets their defaults and validates them.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workloads file %s: %w", path, err)
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse workloads file %s: %w", path, err)
+	}
+	config.Default()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid workloads file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Default sets the defaults of the tenants.
+func (c *Config) Default() {
+	for i := range c.Tenants {
+		t := &c.Tenants[i]
+		if t.Kind == "" {
+			t.Kind = KindJob
+		}
+		if len(t.Namespaces) == 0 {
+			t.Namespaces = []string{t.Name}
+		}
+		if t.Distribution == "" {
+			t.Distribution = executor.DistributionRoundRobin
+		}
+		if t.ZipfExponent == 0 {
+			t.ZipfExponent = defaultZipfExponent
+		}
+		if t.Frequency.Duration == 0 {
+			t.Frequency.Duration = defaultFrequency
+		}
+		if t.Arrival == "" {
+			t.Arrival = ArrivalConstant
+		}
+		if t.Template.JobClass == "" {
+			t.Template.JobClass = t.Name
+		}
+	}
+}
+
+// Validate returns an error if a tenant is invalid or tenant names are not unique.
+func (c *Config) Validate() error {
+	if len(c.Tenants) == 0 {
+		return fmt.Errorf("at least one tenant is required")
+	}
+	names := make(map[string]bool, len(c.Tenants))
+	for _, t := range c.Tenants {
+		if errs := validation.IsDNS1123Label(t.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid tenant name %q: %v", t.Name, errs)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("tenant %s is defined more than once", t.Name)
+		}
+		names[t.Name] = true
+		if err := t.validate(); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Tenant) validate() error {
+	switch t.Kind {
+	case KindJob, KindPod:
+	default:
+		return fmt.Errorf("unknown kind %q, must be %s or %s", t.Kind, KindJob, KindPod)
+	}
+	switch t.Arrival {
+	case ArrivalConstant, ArrivalPoisson:
+	default:
+		return fmt.Errorf("unknown arrival process %q, must be %s or %s", t.Arrival, ArrivalConstant, ArrivalPoisson)
+	}
+	for _, namespace := range t.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %v", namespace, errs)
+		}
+	}
+	if t.Frequency.Duration < 0 {
+		return fmt.Errorf("frequency must be positive, got %s", t.Frequency.Duration)
+	}
+	if t.Requests <= 0 {
+		return fmt.Errorf("requests must be positive, got %d", t.Requests)
+	}
+	if t.Limit <= 0 {
+		return fmt.Errorf("limit must be positive, got %d", t.Limit)
+	}
+	if err := t.spread().Validate(); err != nil {
+		return err
+	}
+	return t.Template.Validate()
+}
+
+// spread returns the spread of the tenant's objects across its namespaces.
+func (t *Tenant) spread() executor.Spread {
+	return executor.Spread{
+		Namespaces:   t.Namespaces,
+		Distribution: t.Distribution,
+		Weights:      t.Weights,
+		ZipfExponent: t.ZipfExponent,
+	}
+}
+
+// Identifier returns the executor identifier of the tenant.
+func (t *Tenant) Identifier() string {
+	return IdentifierPrefix + t.Name
+}
+
+// Options configures the workloads created from the tenants.
+type Options struct {
+	// Rand is the source from which every tenant forks its own source of names, namespaces, arrivals and requests.
+	// If no source is provided, util.Default() will be used.
+	Rand *util.Rand
+	// RandomEnvVars is used to determine whether random environment variables should be added to created objects.
+	RandomEnvVars bool
+	// Naming is the naming scheme of created objects, random if empty.
+	Naming executor.Naming
+	// RunID is the run ID of the created objects, it is required by sequential naming.
+	RunID string
+	// Observer is notified about every processed work item of all rate limiters, it is optional.
+	Observer ratelimiter.Observer
+}
+
+// Workloads creates a workload for every tenant which can be run by the Manager.
+// Objects are named <fake-job|fake-pod>-<tenant>-..., so tenants never create objects with the same name.
+func (c *Config) Workloads(client kubernetes.Interface, opts Options) []k8s.Workload {
+	if opts.Rand == nil {
+		opts.Rand = util.Default()
+	}
+	workloads := make([]k8s.Workload, 0, len(c.Tenants))
+	for i := range c.Tenants {
+		workloads = append(workloads, c.Tenants[i].workload(client, opts))
+	}
+	return workloads
+}
+
+// workload creates the executor and rate limiter of the tenant.
+func (t *Tenant) workload(client kubernetes.Interface, opts Options) k8s.Workload {
+	rnd := opts.Rand.Fork(t.Identifier())
+	executorOpts := []executor.Option{
+		executor.WithIdentifier(t.Identifier()),
+		executor.WithNamespaces(t.spread().Picker(rnd.Fork("namespaces"))),
+		executor.WithNamespaceCreator(func(ctx context.Context, namespace string) error {
+			return k8s.CreateFakeNamespaceIfNeed(ctx, client, namespace, slog.With("process", "workload", "tenant", t.Name))
+		}),
+		executor.WithTemplate(&t.Template),
+	}
+	arrivals := ratelimiter.Arrivals(ratelimiter.ConstantArrivals)
+	if t.Arrival == ArrivalPoisson {
+		arrivals = ratelimiter.PoissonArrivals(rnd.Fork("arrivals"))
+	}
+	if t.Kind == KindPod {
+		creator := executor.NewPodCreator(client, t.Namespaces[0], opts.RandomEnvVars, rnd.Fork("pods"), t.namer(executor.PodNamePrefix, rnd, opts), executorOpts...)
+		return k8s.Workload{
+			Name: t.Name,
+			RateLimiter: ratelimiter.New[*corev1.Pod](
+				t.Frequency.Duration, t.Requests, t.Limit, creator,
+				ratelimiter.WithObserver[*corev1.Pod](opts.Observer),
+				ratelimiter.WithArrivals[*corev1.Pod](arrivals),
+			),
+			NamespaceMetrics: creator.NamespaceMetrics,
+		}
+	}
+	creator := executor.NewJobCreator(client, t.Namespaces[0], opts.RandomEnvVars, rnd.Fork("jobs"), t.namer(executor.JobNamePrefix, rnd, opts), executorOpts...)
+	return k8s.Workload{
+		Name: t.Name,
+		RateLimiter: ratelimiter.New[*batchv1.Job](
+			t.Frequency.Duration, t.Requests, t.Limit, creator,
+			ratelimiter.WithObserver[*batchv1.Job](opts.Observer),
+			ratelimiter.WithArrivals[*batchv1.Job](arrivals),
+		),
+		NamespaceMetrics: creator.NamespaceMetrics,
+	}
+}
+
+// namer creates the namer of the tenant's objects, names are prefixed with the prefix and the tenant name.
+func (t *Tenant) namer(prefix string, rnd *util.Rand, opts Options) executor.Namer {
+	prefix = prefix + "-" + t.Name
+	if opts.Naming == executor.NamingSequential {
+		return executor.NewSequentialNamer(prefix, opts.RunID)
+	}
+	return executor.NewRandomNamer(prefix, rnd.Fork("names"))
+}
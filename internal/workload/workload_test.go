@@ -0,0 +1,151 @@
+package workload
+
+import (
+	"context"
+	"github.com/dejanzele/batch-simulator/internal/k8s"
+	"github.com/dejanzele/batch-simulator/internal/ratelimiter/executor"
+	"github.com/dejanzele/batch-simulator/internal/simulator/resources"
+	"github.com/dejanzele/batch-simulator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tenantsYAML = `
+tenants:
+  - name: team-a
+    frequency: 10ms
+    requests: 2
+    limit: 4
+    template:
+      parallelism: 2
+      requests:
+        - requests:
+            cpu: 500m
+            memory: 1Gi
+          weight: 3
+        - requests:
+            cpu: "4"
+  - name: team-b
+    kind: pod
+    namespaces: [team-b-0, team-b-1]
+    distribution: weighted
+    weights: [1, 0]
+    frequency: 10ms
+    requests: 3
+    limit: 3
+    arrival: poisson
+`
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "workloads.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	config, err := Load(writeFile(t, tenantsYAML))
+	assert.NoError(t, err)
+	if !assert.Len(t, config.Tenants, 2) {
+		t.FailNow()
+	}
+
+	teamA := config.Tenants[0]
+	assert.Equal(t, KindJob, teamA.Kind)
+	assert.Equal(t, []string{"team-a"}, teamA.Namespaces, "namespace must default to the tenant name")
+	assert.Equal(t, executor.DistributionRoundRobin, teamA.Distribution)
+	assert.Equal(t, ArrivalConstant, teamA.Arrival)
+	assert.Equal(t, 10*time.Millisecond, teamA.Frequency.Duration)
+	assert.Equal(t, "team-a", teamA.Template.JobClass, "job class must default to the tenant name")
+	assert.Len(t, teamA.Template.Requests, 2)
+	assert.Equal(t, "workload-team-a", teamA.Identifier())
+
+	teamB := config.Tenants[1]
+	assert.Equal(t, KindPod, teamB.Kind)
+	assert.Equal(t, ArrivalPoisson, teamB.Arrival)
+	assert.Equal(t, []float64{1, 0}, teamB.Weights)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range map[string]string{
+		"no tenants":       `tenants: []`,
+		"unknown field":    "tenants:\n  - name: a\n    requests: 1\n    limit: 1\n    rate: 5\n",
+		"invalid name":     "tenants:\n  - name: Team_A\n    requests: 1\n    limit: 1\n",
+		"duplicate name":   "tenants:\n  - name: a\n    requests: 1\n    limit: 1\n  - name: a\n    requests: 1\n    limit: 1\n",
+		"unknown kind":     "tenants:\n  - name: a\n    kind: node\n    requests: 1\n    limit: 1\n",
+		"unknown arrival":  "tenants:\n  - name: a\n    arrival: bursty\n    requests: 1\n    limit: 1\n",
+		"no requests":      "tenants:\n  - name: a\n",
+		"missing limit":    "tenants:\n  - name: a\n    requests: 1\n",
+		"negative limit":   "tenants:\n  - name: a\n    requests: 1\n    limit: -1\n",
+		"missing weights":  "tenants:\n  - name: a\n    distribution: weighted\n    requests: 1\n    limit: 1\n",
+		"invalid template": "tenants:\n  - name: a\n    requests: 1\n    limit: 1\n    template:\n      parallelism: -1\n",
+	} {
+		_, err := Load(writeFile(t, content))
+		assert.Error(t, err, name)
+	}
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestConfig_Workloads(t *testing.T) {
+	t.Parallel()
+
+	config, err := Load(writeFile(t, tenantsYAML))
+	assert.NoError(t, err)
+	fakeClient := fake.NewSimpleClientset()
+	workloads := config.Workloads(fakeClient, Options{Rand: util.NewRand(1)})
+	if !assert.Len(t, workloads, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "team-a", workloads[0].Name)
+	assert.Equal(t, "workload-team-a", workloads[0].RateLimiter.Identifier())
+
+	// the tenants run alongside the node, pod and job creators, which create nothing
+	rateLimiterConfig := fastRateLimiterConfig(0)
+	manager := k8s.NewManager(fakeClient, &k8s.ManagerConfig{
+		NodeRateLimiterConfig: rateLimiterConfig,
+		PodRateLimiterConfig:  rateLimiterConfig,
+		JobRateLimiterConfig:  rateLimiterConfig,
+		Workloads:             workloads,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, manager.Start(ctx))
+
+	jobs, err := fakeClient.BatchV1().Jobs("team-a").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, jobs.Items, 4)
+	for _, job := range jobs.Items {
+		assert.True(t, strings.HasPrefix(job.Name, "fake-job-team-a-"), job.Name)
+		assert.Equal(t, "team-a", job.Labels[resources.LabelKeyJobClass])
+		assert.Equal(t, int32(2), *job.Spec.Parallelism)
+		cpu := job.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+		assert.Contains(t, []string{"500m", "4"}, cpu.String())
+	}
+	pods, err := fakeClient.CoreV1().Pods("team-b-0").List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, pods.Items, 3, "pods must only be created in the namespace with a positive weight")
+	namespaces, err := fakeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: resources.LabelSelectorFakeNamespace})
+	assert.NoError(t, err)
+	assert.Len(t, namespaces.Items, 2, "only the picked namespaces must be created")
+
+	summaries := manager.Summaries()
+	assert.Equal(t, 4, summaries["workload-team-a"].Succeeded)
+	assert.Equal(t, 3, summaries["workload-team-b"].Succeeded)
+	assert.Equal(t, 3, manager.NamespaceMetrics()["workload-team-b"]["team-b-0"].Succeeded)
+}
+
+// fastRateLimiterConfig returns a fast rate limiter configuration with the limit.
+func fastRateLimiterConfig(limit int) k8s.RateLimiterConfig {
+	return k8s.RateLimiterConfig{Frequency: 10 * time.Millisecond, Requests: 1, Limit: limit}
+}